@@ -0,0 +1,146 @@
+package sqld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// ErrQueryNotAllowed is returned for raw queries that are not registered while
+// raw queries are locked, and for unknown query names.
+var ErrQueryNotAllowed = errors.New("raw query is not allowed")
+
+// RegisterQuery adds a named raw query to the allow-list of the default registry
+// and returns its hash. Requests then refer to it by name or hash through
+// ExecuteRawRequest.QueryName, so that clients never send SQL text:
+//
+//	sqld.RegisterQuery("employees_by_department",
+//	    `SELECT id, first_name FROM employees WHERE department = {{department}}`, nil)
+//	sqld.LockRawQueries()
+//
+//	results, err := sqld.ExecuteRaw[QueryParams, EmployeeResult](ctx, db, sqld.ExecuteRawRequest{
+//	    QueryName: "employees_by_department",
+//	    Params:    map[string]interface{}{"department": "Engineering"},
+//	})
+//
+// See Registry.RegisterQuery for allowed, the SQL fragments of its template lists.
+func RegisterQuery(name, query string, allowed map[string]map[string]string) (string, error) {
+	return defaultRegistry.RegisterQuery(name, query, allowed)
+}
+
+// LockRawQueries restricts the default registry to registered raw queries.
+// See Registry.LockRawQueries.
+func LockRawQueries() {
+	defaultRegistry.LockRawQueries()
+}
+
+// QueryHash returns the hash under which query is registered: the hex SHA-256
+// of its exact text, before template and placeholder expansion.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterQuery adds a named raw query to the allow-list and returns its hash.
+// allowed holds the permitted items of each {% for %} list of query with their
+// SQL fragments, by list name (see TemplateList). Requests for the query then
+// only choose the Items of their lists, and their fragments come from allowed.
+// Registering the same name again with the same query and lists is a no-op.
+func (r *Registry) RegisterQuery(name, query string, allowed map[string]map[string]string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("query name cannot be empty")
+	}
+	if query == "" {
+		return "", fmt.Errorf("query %s cannot be empty", name)
+	}
+	registered := registeredQuery{text: query, allowed: make(map[string]map[string]string, len(allowed))}
+	for list, fragments := range allowed {
+		registered.allowed[list] = maps.Clone(fragments)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.queries[name]; ok && existing.text != query {
+		return "", fmt.Errorf("query %s is already registered with a different text", name)
+	}
+	hash := QueryHash(query)
+	if existing, ok := r.queryHashes[hash]; ok && !reflect.DeepEqual(existing.allowed, registered.allowed) {
+		return "", fmt.Errorf("query %s is already registered with different lists", name)
+	}
+	r.queries[name] = registered
+	r.queryHashes[hash] = registered
+	return hash, nil
+}
+
+// LockRawQueries restricts raw execution to registered queries. Afterwards a raw
+// request must either name a registered query in QueryName or carry the exact
+// text of one in Query; any other query is rejected with ErrQueryNotAllowed.
+// The fragments of template lists must then be registered with the query too,
+// and requests setting TemplateList.Allowed are rejected.
+// Locking cannot be undone.
+func (r *Registry) LockRawQueries() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rawLocked = true
+}
+
+// registeredQuery is a raw query of the allow-list.
+type registeredQuery struct {
+	text    string
+	allowed map[string]map[string]string // SQL fragments of its template lists by list name
+}
+
+// resolveQuery returns the query text of req and the lists its template is
+// expanded with, looking up QueryName if set and enforcing the allow-list when
+// raw queries are locked. The lists of a registered query take their fragments
+// from its registration, unless the request sets its own while unlocked.
+func (r *Registry) resolveQuery(req ExecuteRawRequest) (string, map[string]TemplateList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.rawLocked {
+		for name, list := range req.Lists {
+			if list.Allowed != nil {
+				return "", nil, fmt.Errorf("%w: list %s cannot set its allowed fragments", ErrQueryNotAllowed, name)
+			}
+		}
+	}
+
+	var registered registeredQuery
+	var ok bool
+	switch {
+	case req.QueryName != "":
+		if req.Query != "" {
+			return "", nil, fmt.Errorf("only one of Query and QueryName may be set")
+		}
+		if registered, ok = r.queries[req.QueryName]; !ok {
+			if registered, ok = r.queryHashes[req.QueryName]; !ok {
+				return "", nil, fmt.Errorf("%w: unknown query %s", ErrQueryNotAllowed, req.QueryName)
+			}
+		}
+	default:
+		registered, ok = r.queryHashes[QueryHash(req.Query)]
+		if !ok {
+			if r.rawLocked {
+				return "", nil, fmt.Errorf("%w: query is not registered", ErrQueryNotAllowed)
+			}
+			return req.Query, req.Lists, nil
+		}
+	}
+
+	if len(req.Lists) == 0 {
+		return registered.text, nil, nil
+	}
+	lists := make(map[string]TemplateList, len(req.Lists))
+	for name, list := range req.Lists {
+		if list.Allowed == nil {
+			list.Allowed = registered.allowed[name]
+		}
+		lists[name] = list
+	}
+	return registered.text, lists, nil
+}
@@ -0,0 +1,117 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockedRawQueries(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	const query = "SELECT id, name FROM test_results WHERE id = {{id}}"
+	hash, err := RegisterQuery("result_by_id", query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, QueryHash(query), hash)
+	LockRawQueries()
+
+	tests := []struct {
+		name    string
+		req     ExecuteRawRequest
+		wantErr string
+	}{
+		{name: "by name", req: ExecuteRawRequest{QueryName: "result_by_id"}},
+		{name: "by hash", req: ExecuteRawRequest{QueryName: hash}},
+		{name: "registered text", req: ExecuteRawRequest{Query: query}},
+		{
+			name:    "unregistered text",
+			req:     ExecuteRawRequest{Query: "SELECT id, name FROM test_results WHERE id = {{id}} OR TRUE"},
+			wantErr: "raw query is not allowed: query is not registered",
+		},
+		{
+			name:    "unknown name",
+			req:     ExecuteRawRequest{QueryName: "all_results"},
+			wantErr: "raw query is not allowed: unknown query all_results",
+		},
+		{
+			name:    "both set",
+			req:     ExecuteRawRequest{Query: query, QueryName: "result_by_id"},
+			wantErr: "only one of Query and QueryName may be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+			tt.req.Params = map[string]interface{}{"id": 1}
+
+			results, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, tt.req)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Empty(t, fake.statements)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []map[string]interface{}{{"id": 1, "name": "Jane"}}, results)
+			assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE id = $1"}, fake.statements)
+		})
+	}
+}
+
+func TestRegisterQuery(t *testing.T) {
+	registry := NewRegistry()
+
+	_, err := registry.RegisterQuery("q", "SELECT 1", nil)
+	require.NoError(t, err)
+	_, err = registry.RegisterQuery("q", "SELECT 1", nil)
+	assert.NoError(t, err)
+
+	_, err = registry.RegisterQuery("q", "SELECT 2", nil)
+	assert.EqualError(t, err, "query q is already registered with a different text")
+	_, err = registry.RegisterQuery("", "SELECT 1", nil)
+	assert.EqualError(t, err, "query name cannot be empty")
+	_, err = registry.RegisterQuery("empty", "", nil)
+	assert.EqualError(t, err, "query empty cannot be empty")
+
+	_, err = registry.RegisterQuery("q", "SELECT 1", map[string]map[string]string{"cols": {"id": "id"}})
+	assert.EqualError(t, err, "query q is already registered with different lists")
+}
+
+func TestLockedRawQueryLists(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id", "name"})
+	client := NewClient(db)
+	require.NoError(t, client.Register(TestParams{}))
+	require.NoError(t, client.Register(TestResult{}))
+
+	const query = `SELECT {% for cols join ", " %}{% item %} AS {% key %}{% end %} FROM test_results`
+	_, err := client.RegisterQuery("results", query, map[string]map[string]string{
+		"cols": {"id": "id", "name": "upper(name)"},
+	})
+	require.NoError(t, err)
+	client.LockRawQueries()
+
+	_, err = ExecuteRawOn[TestParams, TestResult](context.Background(), client, ExecuteRawRequest{
+		QueryName: "results",
+		Lists:     map[string]TemplateList{"cols": {Items: []string{"id", "name"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT id AS id, upper(name) AS name FROM test_results"}, fake.statements)
+
+	_, err = ExecuteRawOn[TestParams, TestResult](context.Background(), client, ExecuteRawRequest{
+		QueryName: "results",
+		Lists: map[string]TemplateList{"cols": {
+			Allowed: map[string]string{"id": "id", "secret": "(SELECT password FROM users LIMIT 1)"},
+			Items:   []string{"secret"},
+		}},
+	})
+	assert.EqualError(t, err, "raw query is not allowed: list cols cannot set its allowed fragments")
+	assert.Len(t, fake.statements, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -265,3 +266,102 @@ func TestValidatorAcceptsIsNullOnArrayField(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONDecodedArrayValues(t *testing.T) {
+	require.NoError(t, Register[ArrayTestModel]())
+
+	metadata, err := getModelMetadata(ArrayTestModel{})
+	require.NoError(t, err)
+
+	// JSON decodes [20, 30] into []interface{} of float64
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "reporting_to", Operator: OpContains, Value: []interface{}{float64(20), float64(30)}},
+			{Field: "id", Operator: OpIn, Value: []interface{}{float64(1), float64(2)}},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	got, err := buildQuery[ArrayTestModel](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM array_test_models WHERE reporting_to @> $1 AND id IN ($2,$3)", sql)
+	assert.Equal(t, []interface{}{[]int64{20, 30}, int64(1), int64(2)}, args)
+
+	insert, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"reporting_to": []interface{}{float64(20)}},
+	})
+	require.NoError(t, err)
+	_, args, err = insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]int64{20}}, args)
+}
+
+func TestJSONDecodedArrayValuesErrors(t *testing.T) {
+	require.NoError(t, Register[ArrayTestModel]())
+
+	metadata, err := getModelMetadata(ArrayTestModel{})
+	require.NoError(t, err)
+
+	// Mismatched element types are rejected by validation
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "reporting_to", Operator: OpOverlap, Value: []interface{}{float64(20), "thirty"}},
+		},
+	}
+	assert.ErrorContains(t, BasicValidator{}.ValidateQuery(req, metadata), "at index 1")
+
+	// Fractional numbers cannot bind to an integer array
+	req.Where[0].Value = []interface{}{20.5}
+	_, err = buildQuery[ArrayTestModel](req)
+	assert.ErrorContains(t, err, "element 0")
+}
+
+type PgArrayTestModel struct {
+	ID     int64                         `json:"id" db:"id"`
+	Tags   pgtype.Array[string]          `json:"tags" db:"tags"`
+	Labels pgtype.FlatArray[pgtype.Text] `json:"labels" db:"labels"`
+}
+
+func (PgArrayTestModel) TableName() string {
+	return "pg_array_test_models"
+}
+
+func TestPgtypeArrayFields(t *testing.T) {
+	require.NoError(t, Register[PgArrayTestModel]())
+
+	metadata, err := getModelMetadata(PgArrayTestModel{})
+	require.NoError(t, err)
+
+	tags := metadata.Fields["tags"]
+	require.NotNil(t, tags.Array)
+	assert.Equal(t, reflect.TypeOf(""), tags.Array.ElementType)
+	assert.Equal(t, reflect.TypeOf([]string{}), tags.NormalizedType)
+	assert.Equal(t, "text[]", sqlTypeName(tags.Type))
+
+	labels := metadata.Fields["labels"]
+	require.NotNil(t, labels.Array)
+	assert.Equal(t, reflect.TypeOf(""), labels.Array.ElementType)
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "tags", Operator: OpContains, Value: []interface{}{"go", "sql"}},
+			{Field: "labels", Operator: OpAny, Value: "urgent"},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	got, err := buildQuery[PgArrayTestModel](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM pg_array_test_models WHERE tags @> $1 AND $2 = ANY(labels)", sql)
+	assert.Equal(t, []interface{}{[]string{"go", "sql"}, pgtype.Text{String: "urgent", Valid: true}}, args)
+
+	req.Where = []Condition{{Field: "tags", Operator: OpEqual, Value: "go"}}
+	assert.ErrorContains(t, BasicValidator{}.ValidateQuery(req, metadata), "array field")
+}
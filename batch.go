@@ -0,0 +1,136 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ManyQuery is a structured query prepared for ExecuteMany by QueryFor.
+type ManyQuery struct {
+	model Model
+	req   QueryRequest
+}
+
+// QueryFor prepares req, a query on the model T, for ExecuteMany. The model
+// is looked up in the registry ExecuteMany or ExecuteManyOn runs the query
+// with.
+func QueryFor[T Model](req QueryRequest) ManyQuery {
+	var model T
+	return ManyQuery{model: model, req: req}
+}
+
+// ExecuteMany runs several structured queries, possibly on different models,
+// and returns their responses in order, as Execute would. With a pgx handle
+// all their statements, including counts, aggregates and facets, are sent in a
+// single pgx.Batch round trip; with a *sql.DB or *sql.Tx they run one after the
+// other. All queries are validated before any is sent, and the first failing
+// query fails the whole batch.
+//
+//	responses, err := sqld.ExecuteMany(ctx, pool,
+//	    sqld.QueryFor[Employee](employeesReq),
+//	    sqld.QueryFor[Department](departmentsReq),
+//	)
+//	employees, departments := responses[0], responses[1]
+func ExecuteMany(ctx context.Context, db interface{}, queries ...ManyQuery) ([]QueryResponse[Model], error) {
+	return executeManyWith(ctx, defaultRegistry, db, queries)
+}
+
+// executeManyWith implements ExecuteMany with the models and converters of r.
+func executeManyWith(ctx context.Context, r *Registry, db interface{}, queries []ManyQuery) (responses []QueryResponse[Model], err error) {
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, "sqld.execute_many", "", spanOperationKey.String(OperationBatch))
+	metadata := make([]ModelMetadata, len(queries))
+	executions := make([]*execution, len(queries))
+	defer func() {
+		endSpan(span, err)
+		// Queries that were not built, because an earlier one failed, are not reported
+		for i := range queries {
+			if executions[i] != nil {
+				r.observeExecution(OperationBatch, metadata[i], executions[i], start, err)
+			}
+		}
+	}()
+
+	for i, q := range queries {
+		if q.model == nil {
+			return nil, fmt.Errorf("query %d: not prepared with QueryFor", i)
+		}
+		metadata[i], err = r.getOrRegister(q.model)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: failed to get model metadata: %w", i, err)
+		}
+		exec, err := r.newExecution(ctx, metadata[i], q.req)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		executions[i] = exec
+	}
+
+	// The query timeout covers the whole batch
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+
+	err = r.guard(func() error {
+		if sender, ok := db.(batchSender); ok {
+			return r.sendBatch(ctx, sender, executions, timeout)
+		}
+		for i, exec := range executions {
+			for _, stmt := range exec.statements {
+				if err := stmt.run(ctx, r, db, exec.metadata.TableName); err != nil {
+					return fmt.Errorf("query %d: %w", i, timeoutError(ctx, timeout, err))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses = make([]QueryResponse[Model], len(executions))
+	for i, exec := range executions {
+		resp, err := exec.response(r)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		responses[i] = resp
+	}
+	return responses, nil
+}
+
+// sendBatch runs the statements of executions in a single pgx.Batch, traced
+// as the span sqld.batch.
+func (r *Registry) sendBatch(ctx context.Context, sender batchSender, executions []*execution, timeout time.Duration) (err error) {
+	batch := &pgx.Batch{}
+	models := make([]string, len(executions))
+	for i, exec := range executions {
+		models[i] = exec.metadata.TableName
+		for _, stmt := range exec.statements {
+			r.logSQL(ctx, exec.metadata.TableName, stmt.query, stmt.args)
+			batch.Queue(stmt.query, stmt.args...)
+		}
+	}
+	ctx, span := r.startSpan(ctx, "sqld.batch", "", spanStatementsKey.Int(batch.Len()))
+	defer func() { endSpan(span, err) }()
+
+	start := time.Now()
+	defer func() { r.logSlowBatch(ctx, models, batch.Len(), time.Since(start)) }()
+	br := sender.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for i, exec := range executions {
+		for _, stmt := range exec.statements {
+			rows, err := br.Query()
+			if err != nil {
+				return fmt.Errorf("query %d: %w", i, timeoutError(ctx, timeout, wrapDBError(stmt.errMsg, err)))
+			}
+			if err := stmt.scan(rows); err != nil {
+				return fmt.Errorf("query %d: %w", i, timeoutError(ctx, timeout, err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BatchTestBranch struct {
+	ID   int64  `json:"id" db:"id"`
+	City string `json:"city" db:"city"`
+}
+
+func (BatchTestBranch) TableName() string {
+	return "branches"
+}
+
+func TestExecuteMany(t *testing.T) {
+	t.Run("responses in order", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+		fake.queue([]string{"count"}, []driver.Value{int64(12)})
+		fake.queue([]string{"id", "owner_name"}, []driver.Value{int64(1), "Jane"})
+		fake.queue([]string{"city"}, []driver.Value{"Pune"}, []driver.Value{"Mumbai"})
+
+		responses, err := ExecuteMany(context.Background(), db,
+			QueryFor[CompileTestAccount](QueryRequest{
+				Select:     []string{"id", "owner"},
+				Pagination: &PaginationRequest{Page: 1, PageSize: 1},
+			}),
+			QueryFor[BatchTestBranch](QueryRequest{
+				Select: []string{"city"},
+				Where:  []Condition{{Field: "id", Operator: OpIn, Value: []interface{}{1, 2}}},
+			}),
+		)
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		assert.Equal(t, []QueryResult{{"id": int64(1), "owner": "Jane"}}, responses[0].Data)
+		assert.Equal(t, 12, responses[0].Pagination.TotalItems)
+		assert.Equal(t, []QueryResult{{"city": "Pune"}, {"city": "Mumbai"}}, responses[1].Data)
+		assert.Nil(t, responses[1].Pagination)
+		assert.Equal(t, []string{
+			"SELECT COUNT(*) FROM accounts",
+			"SELECT id, owner_name FROM accounts LIMIT $1 OFFSET $2",
+			"SELECT city FROM branches WHERE id IN ($1,$2)",
+		}, fake.statements)
+	})
+
+	t.Run("invalid query fails the batch before sending", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+
+		_, err := ExecuteMany(context.Background(), db,
+			QueryFor[CompileTestAccount](QueryRequest{Select: []string{"id"}}),
+			QueryFor[BatchTestBranch](QueryRequest{Select: []string{"country"}}),
+		)
+		assert.EqualError(t, err, "query 1: failed to validate query: invalid field in select: country")
+		assert.Empty(t, fake.statements)
+	})
+	t.Run("on a client", func(t *testing.T) {
+		saved := defaultRegistry
+		defaultRegistry = NewRegistry()
+		t.Cleanup(func() { defaultRegistry = saved })
+
+		db, fake := newFakeDB(t, []string{"city"}, []driver.Value{"Pune"})
+		client := NewClient(db)
+
+		responses, err := ExecuteManyOn(context.Background(), client,
+			QueryFor[BatchTestBranch](QueryRequest{Select: []string{"city"}}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []QueryResult{{"city": "Pune"}}, responses[0].Data)
+		assert.Equal(t, []string{"SELECT city FROM branches"}, fake.statements)
+
+		// The model was registered with the client only
+		_, err = client.modelByTable("branches")
+		assert.NoError(t, err)
+		_, err = defaultRegistry.modelByTable("branches")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,163 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrCircuitOpen is returned, without running the query, while the circuit
+// breaker of the registry is open because the database failed repeatedly.
+// HTTP handlers can check for it with errors.As to answer 503 Service
+// Unavailable with a Retry-After header.
+type ErrCircuitOpen struct {
+	RetryAfter time.Duration // Time left until a trial query is let through
+}
+
+func (e *ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("circuit breaker open: database unavailable, retry after %s", e.RetryAfter)
+}
+
+// CircuitBreaker stops queries from reaching an unhealthy database, so they
+// fail fast instead of piling up on a pool that cannot serve them. After a
+// number of consecutive failures the circuit opens and queries fail with
+// *ErrCircuitOpen. Once it has been open for a while, a single trial query is
+// let through: if it succeeds the circuit closes, otherwise it opens again.
+//
+// Failures are errors that tell the database is unavailable or overloaded:
+// connection errors, timeouts, including waiting for a pool connection, and
+// PostgreSQL errors of the classes 08 (connection exception), 53 (insufficient
+// resources) and 57 (operator intervention). Other errors, such as constraint
+// violations, and queries cancelled by their caller do not count. A cancelled
+// trial query neither closes nor reopens the circuit.
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // Zero while closed
+	trial    bool      // A trial query is running while open
+}
+
+// NewCircuitBreaker returns a closed circuit breaker that opens after
+// failureThreshold consecutive failures, for openTimeout.
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{failureThreshold: failureThreshold, openTimeout: openTimeout, now: time.Now}
+}
+
+// UseCircuitBreaker makes the default registry run queries through b. See
+// Registry.UseCircuitBreaker.
+func UseCircuitBreaker(b *CircuitBreaker) {
+	defaultRegistry.UseCircuitBreaker(b)
+}
+
+// UseCircuitBreaker makes Execute, ExecuteMany, the raw functions, such as
+// ExecuteRaw and ExecuteRawPaginated, and the writes, such as ExecuteInsert
+// and ExecuteImport, run their statements through b, so they fail with
+// *ErrCircuitOpen while the database is unhealthy. Nil removes the circuit
+// breaker. Registries may share one.
+func (r *Registry) UseCircuitBreaker(b *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breaker = b
+}
+
+// guard runs fn, which queries the database, through the circuit breaker of
+// r, if any.
+func (r *Registry) guard(fn func() error) error {
+	r.mu.RLock()
+	b := r.breaker
+	r.mu.RUnlock()
+	if b == nil {
+		return fn()
+	}
+
+	trial, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.record(trial, err)
+	return err
+}
+
+// allow reports whether a query may run, and whether it is the trial query of
+// an open circuit.
+func (b *CircuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	if wait := b.openTimeout - b.now().Sub(b.openedAt); wait > 0 || b.trial {
+		if wait < 0 {
+			wait = 0
+		}
+		return false, &ErrCircuitOpen{RetryAfter: wait}
+	}
+	b.trial = true
+	return true, nil
+}
+
+// record updates the state of b with err, the outcome of a query.
+func (b *CircuitBreaker) record(trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if trial {
+		b.trial = false
+		// A trial cancelled by its caller tells nothing about the database, so
+		// the circuit stays open and lets the next query through as a trial
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+	}
+	failed := isDatabaseFailure(err)
+	switch {
+	case !failed && (trial || b.openedAt.IsZero()):
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case failed && trial:
+		b.openedAt = b.now()
+	case failed && b.openedAt.IsZero():
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.failures = 0
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// isDatabaseFailure reports whether err tells that the database is unavailable
+// or overloaded, see CircuitBreaker.
+func isDatabaseFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		for _, class := range []string{"08", "53", "57"} {
+			if strings.HasPrefix(pgErr.Code, class) {
+				return true
+			}
+		}
+		return false
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) ||
+		errors.As(err, &connectErr) || errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,135 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	registry := NewRegistry()
+	registry.UseCircuitBreaker(breaker)
+
+	calls := 0
+	fail := func(err error) func() error {
+		return func() error {
+			calls++
+			return err
+		}
+	}
+	unavailable := &pgconn.PgError{Code: "57P03", Message: "the database system is starting up"}
+
+	// Errors of the query itself do not count
+	constraint := &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, registry.guard(fail(constraint)), constraint)
+	}
+
+	// A success resets the consecutive failures
+	assert.Error(t, registry.guard(fail(unavailable)))
+	assert.NoError(t, registry.guard(fail(nil)))
+	assert.Error(t, registry.guard(fail(fmt.Errorf("failed to execute query: %w", unavailable))))
+	calls = 0
+
+	// The second consecutive failure opens the circuit
+	assert.ErrorIs(t, registry.guard(fail(context.DeadlineExceeded)), context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+	now = now.Add(20 * time.Second)
+	err := registry.guard(fail(nil))
+	var open *ErrCircuitOpen
+	require.ErrorAs(t, err, &open)
+	assert.Equal(t, 40*time.Second, open.RetryAfter)
+	assert.Equal(t, 1, calls)
+
+	// A failed trial opens the circuit again
+	now = now.Add(40 * time.Second)
+	assert.Error(t, registry.guard(fail(unavailable)))
+	assert.Equal(t, 2, calls)
+	require.ErrorAs(t, registry.guard(fail(nil)), &open)
+	assert.Equal(t, time.Minute, open.RetryAfter)
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	assert.NoError(t, registry.guard(fail(nil)))
+	assert.NoError(t, registry.guard(fail(nil)))
+	assert.Equal(t, 4, calls)
+}
+
+func TestCircuitBreakerSingleTrial(t *testing.T) {
+	breaker := NewCircuitBreaker(1, 0)
+	trial, err := breaker.allow()
+	require.NoError(t, err)
+	breaker.record(trial, driver.ErrBadConn)
+
+	trial, err = breaker.allow()
+	require.NoError(t, err)
+	assert.True(t, trial)
+
+	// Other queries fail while the trial runs
+	_, err = breaker.allow()
+	var open *ErrCircuitOpen
+	assert.ErrorAs(t, err, &open)
+
+	breaker.record(trial, nil)
+	trial, err = breaker.allow()
+	require.NoError(t, err)
+	assert.False(t, trial)
+}
+
+func TestCircuitBreakerCancelledTrial(t *testing.T) {
+	breaker := NewCircuitBreaker(1, 0)
+	trial, err := breaker.allow()
+	require.NoError(t, err)
+	breaker.record(trial, driver.ErrBadConn)
+
+	trial, err = breaker.allow()
+	require.NoError(t, err)
+	require.True(t, trial)
+	breaker.record(trial, fmt.Errorf("failed to execute query: %w", context.Canceled))
+
+	// The circuit is still open, and the next query is a trial again
+	trial, err = breaker.allow()
+	require.NoError(t, err)
+	assert.True(t, trial)
+}
+
+func TestIsDatabaseFailure(t *testing.T) {
+	assert.False(t, isDatabaseFailure(nil))
+	assert.False(t, isDatabaseFailure(context.Canceled))
+	assert.False(t, isDatabaseFailure(errors.New("failed to validate query")))
+	assert.False(t, isDatabaseFailure(&pgconn.PgError{Code: "42P01"}))
+	assert.True(t, isDatabaseFailure(&pgconn.PgError{Code: "53300"}))
+	assert.True(t, isDatabaseFailure(&pgconn.PgError{Code: "08006"}))
+	assert.True(t, isDatabaseFailure(&ErrQueryTimeout{Timeout: time.Second, Err: context.DeadlineExceeded}))
+	assert.True(t, isDatabaseFailure(fmt.Errorf("failed to execute query: %w", driver.ErrBadConn)))
+}
+
+func TestExecuteCircuitOpen(t *testing.T) {
+	registry := NewRegistry()
+	registry.Configure(Config{
+		QueryTimeout:   10 * time.Millisecond,
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	})
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	fake.delay = time.Second
+
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, QueryRequest{Select: []string{"id"}})
+	var timeoutErr *ErrQueryTimeout
+	require.ErrorAs(t, err, &timeoutErr)
+
+	fake.delay = 0
+	_, err = executeWith[CompileTestAccount](context.Background(), registry, db, QueryRequest{Select: []string{"id"}})
+	var open *ErrCircuitOpen
+	require.ErrorAs(t, err, &open)
+	assert.Empty(t, fake.statements)
+}
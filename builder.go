@@ -36,30 +36,87 @@ func buildWhereClause(fieldName string, cond Condition) (squirrel.Sqlizer, error
 		return squirrel.Expr(fieldName+" @> ?", cond.Value), nil
 	case OpOverlap:
 		return squirrel.Expr(fieldName+" && ?", cond.Value), nil
+	case OpSubnetOf:
+		return squirrel.Expr(fieldName+" << ?", cond.Value), nil
+	case OpSupernetOf:
+		return squirrel.Expr(fieldName+" >> ?", cond.Value), nil
 	default:
 		return nil, fmt.Errorf("unsupported operator: %s", cond.Operator)
 	}
 }
 
+// applyWhere converts each condition's JSON field name to its column name
+// and adds the condition to query. For soft-delete models, rows whose
+// soft-delete field is set are excluded.
+func (r *Registry) applyWhere(query squirrel.SelectBuilder, metadata ModelMetadata, where []Condition) (squirrel.SelectBuilder, error) {
+	clauses, err := r.whereClauses(metadata, where)
+	if err != nil {
+		return query, err
+	}
+	for _, clause := range clauses {
+		query = query.Where(clause)
+	}
+	if metadata.SoftDeleteField != "" {
+		query = query.Where(squirrel.Eq{metadata.Fields[metadata.SoftDeleteField].Name: nil})
+	}
+	return query, nil
+}
+
+// whereClauses converts conditions to squirrel clauses on column names,
+// applying the value converters registered with r.
+func (r *Registry) whereClauses(metadata ModelMetadata, where []Condition) ([]squirrel.Sqlizer, error) {
+	clauses := make([]squirrel.Sqlizer, 0, len(where))
+	for _, cond := range where {
+		if computed, ok := metadata.Computed[cond.Field]; ok {
+			clause, err := computedClause(computed, cond)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for computed condition %s: %w", cond.Field, err)
+			}
+			clauses = append(clauses, clause)
+			continue
+		}
+
+		field, ok := metadata.Fields[cond.Field]
+		if !ok {
+			return nil, fmt.Errorf("invalid field in where clause: %s", cond.Field)
+		}
+
+		value, err := r.convertConditionValue(field, cond)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %s: %w", cond.Field, err)
+		}
+		cond.Value = value
+
+		whereClause, err := buildWhereClause(field.Name, cond)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, whereClause)
+	}
+	return clauses, nil
+}
+
 // TODO: Add input validation for maximum number of selected columns
-// TODO: Add SQL injection protection checks for WHERE values
-// TODO: Add validation for LIMIT/OFFSET values
-// TODO: Add query timeout configuration
-// TODO: Add metrics/logging for query performance monitoring
 
 // buildQuery creates a type-safe query for the given model.
 // To achieve safety, it does the following:
 // - Validates the select fields against the model metadata
 // - Converts JSON field names to actual field names for SELECT
 // - Converts JSON field names to actual field names for WHERE
-// - Other validations -- TODO
+// - Binds WHERE values as parameters instead of inlining them
+// - Rejects negative LIMIT and OFFSET values
 func buildQuery[T Model](req QueryRequest) (squirrel.SelectBuilder, error) {
 	var model T
 	metadata, err := getModelMetadata(model)
 	if err != nil {
 		return squirrel.SelectBuilder{}, fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	return defaultRegistry.buildSelectQuery(model.TableName(), metadata, req)
+}
 
+// buildSelectQuery builds the SELECT statement of req on tableName.
+func (r *Registry) buildSelectQuery(tableName string, metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
+	var err error
 	// Validate select fields
 	if len(req.Select) == 0 {
 		return squirrel.SelectBuilder{}, fmt.Errorf("select fields cannot be empty")
@@ -74,7 +131,7 @@ func buildQuery[T Model](req QueryRequest) (squirrel.SelectBuilder, error) {
 		// When "ALL" is specified, include all fields from the model
 		selectFields = make([]string, 0, len(metadata.Fields))
 		for _, field := range metadata.Fields {
-			selectFields = append(selectFields, field.Name)
+			selectFields = append(selectFields, selectColumn(field))
 		}
 	} else {
 		// Convert JSON field names to actual field names for SELECT
@@ -84,28 +141,18 @@ func buildQuery[T Model](req QueryRequest) (squirrel.SelectBuilder, error) {
 			if !ok {
 				return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in select: %s", jsonName)
 			}
-			selectFields[i] = field.Name
+			selectFields[i] = selectColumn(field)
 		}
 	}
 
 	// Build query with converted field names
 	query := builder.Select(selectFields...).
-		From(model.TableName())
+		From(tableName)
 
 	// Build WHERE conditions
-	if len(req.Where) > 0 {
-		for _, cond := range req.Where {
-			field, ok := metadata.Fields[cond.Field]
-			if !ok {
-				return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in where clause: %s", cond.Field)
-			}
-
-			whereClause, err := buildWhereClause(field.Name, cond)
-			if err != nil {
-				return squirrel.SelectBuilder{}, err
-			}
-			query = query.Where(whereClause)
-		}
+	query, err = r.applyWhere(query, metadata, req.Where)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
 	}
 
 	// Handle ORDER BY clauses
@@ -142,3 +189,49 @@ func buildQuery[T Model](req QueryRequest) (squirrel.SelectBuilder, error) {
 
 	return query, nil
 }
+
+// buildAggregateQuery creates a query computing req.Aggregates over all rows
+// matching req.Where, ignoring pagination. Each aggregate is aliased as agg_<index>
+// so the result can be mapped back to Aggregate.Key().
+func (r *Registry) buildAggregateQuery(tableName string, metadata ModelMetadata, req QueryRequest) (squirrel.SelectBuilder, error) {
+	columns := make([]string, len(req.Aggregates))
+	for i, agg := range req.Aggregates {
+		field, ok := metadata.Fields[agg.Field]
+		if !ok {
+			return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in aggregate: %s", agg.Field)
+		}
+		columns[i] = fmt.Sprintf("%s(%s) AS agg_%d", agg.Func, field.Name, i)
+	}
+
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(columns...).
+		From(tableName)
+
+	return r.applyWhere(query, metadata, req.Where)
+}
+
+// MaxFacetValues caps the number of distinct values returned per facet field.
+const MaxFacetValues = 100
+
+// buildFacetQuery creates a query counting the rows matching req.Where per
+// distinct value of the given field, most frequent values first.
+func (r *Registry) buildFacetQuery(tableName string, metadata ModelMetadata, req QueryRequest, facet string) (squirrel.SelectBuilder, error) {
+	field, ok := metadata.Fields[facet]
+	if !ok {
+		return squirrel.SelectBuilder{}, fmt.Errorf("invalid field in facets: %s", facet)
+	}
+
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(field.Name+" AS value", "COUNT(*) AS count").
+		From(tableName)
+
+	query, err := r.applyWhere(query, metadata, req.Where)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+
+	return query.
+		GroupBy(field.Name).
+		OrderBy("count DESC", field.Name+" ASC").
+		Limit(MaxFacetValues), nil
+}
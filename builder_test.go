@@ -179,3 +179,63 @@ func TestBuildQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildAggregateQuery(t *testing.T) {
+	if err := Register[BuilderTestModel](); err != nil {
+		t.Fatalf("Failed to register test model: %v", err)
+	}
+
+	var model BuilderTestModel
+	metadata, err := getModelMetadata(model)
+	assert.NoError(t, err)
+
+	req := QueryRequest{
+		Select: []string{"name"},
+		Where: []Condition{
+			{Field: "active", Operator: OpEqual, Value: true},
+		},
+		Aggregates: []Aggregate{
+			{Func: AggSum, Field: "salary"},
+			{Func: AggAvg, Field: "age"},
+		},
+		Limit: intPtr(10),
+	}
+
+	got, err := defaultRegistry.buildAggregateQuery(model.TableName(), metadata, req)
+	assert.NoError(t, err)
+
+	sql, args, err := got.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT SUM(salary) AS agg_0, AVG(age) AS agg_1 FROM test_models WHERE active = $1", sql)
+	assert.Equal(t, []interface{}{true}, args)
+	assert.Equal(t, "sum_salary", req.Aggregates[0].Key())
+}
+
+func TestBuildFacetQuery(t *testing.T) {
+	if err := Register[BuilderTestModel](); err != nil {
+		t.Fatalf("Failed to register test model: %v", err)
+	}
+
+	var model BuilderTestModel
+	metadata, err := getModelMetadata(model)
+	assert.NoError(t, err)
+
+	req := QueryRequest{
+		Select: []string{"name"},
+		Where: []Condition{
+			{Field: "age", Operator: OpGreaterThan, Value: 30},
+		},
+		Facets: []string{"active"},
+	}
+
+	got, err := defaultRegistry.buildFacetQuery(model.TableName(), metadata, req, "active")
+	assert.NoError(t, err)
+
+	sql, args, err := got.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT active AS value, COUNT(*) AS count FROM test_models WHERE age > $1 GROUP BY active ORDER BY count DESC, active ASC LIMIT 100", sql)
+	assert.Equal(t, []interface{}{30}, args)
+
+	_, err = defaultRegistry.buildFacetQuery(model.TableName(), metadata, req, "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,389 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// DefaultBulkBatchSize is the number of rows sent per COPY or INSERT
+	// when BulkInsertRequest.BatchSize is not set.
+	DefaultBulkBatchSize = 1000
+
+	// maxBindParams is the maximum number of bind parameters PostgreSQL
+	// accepts in a single statement.
+	maxBindParams = 65535
+)
+
+// BulkInsertRequest represents a multi-row insert.
+type BulkInsertRequest struct {
+	// Rows maps JSON field names to values, one map per row.
+	// Every row must contain the same set of fields.
+	Rows []map[string]interface{} `json:"rows"`
+
+	// BatchSize is the number of rows sent to the database at once.
+	// Optional - defaults to DefaultBulkBatchSize.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// ExecuteBulkInsert validates every row against the model metadata and inserts
+// all rows in a single transaction. With pgx connections the rows are sent using
+// COPY; with database/sql they are sent as multi-row INSERT statements.
+// If any row is invalid, nothing is inserted and the error names the row index.
+// Auto-managed timestamp fields that a row leaves unset get the same current time
+// in every row.
+func ExecuteBulkInsert[T Model](ctx context.Context, db interface{}, req BulkInsertRequest) (InsertResponse, error) {
+	return executeBulkInsertWith[T](ctx, defaultRegistry, db, req)
+}
+
+// executeBulkInsertWith implements ExecuteBulkInsert with the models and
+// converters of r.
+func executeBulkInsertWith[T Model](ctx context.Context, r *Registry, db interface{}, req BulkInsertRequest) (resp InsertResponse, err error) {
+	var model T
+	ctx, finish := r.startWrite(ctx, "sqld.execute_bulk_insert", model.TableName())
+	defer func() { finish(int(resp.RowsAffected), err) }()
+
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return InsertResponse{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return InsertResponse{}, err
+	}
+
+	if len(req.Rows) == 0 {
+		return InsertResponse{}, fmt.Errorf("bulk insert rows cannot be empty")
+	}
+	if req.BatchSize < 0 {
+		return InsertResponse{}, fmt.Errorf("batch size must be non-negative")
+	}
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+
+	validator := BasicValidator{}
+	for i, row := range req.Rows {
+		// All rows share the field set of the first row
+		if !sameFields(req.Rows[0], row) {
+			return InsertResponse{}, fmt.Errorf("row %d: fields differ from the first row", i)
+		}
+		if err := validator.ValidateInsert(InsertRequest{Values: row}, metadata); err != nil {
+			return InsertResponse{}, fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+
+	columns, values, err := r.bulkValues(metadata, req.Rows)
+	if err != nil {
+		return InsertResponse{}, err
+	}
+
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+	rowsAffected, err := r.writeRows(ctx, db, model.TableName(), columns, values, batchSize, timeout)
+	if err != nil {
+		return InsertResponse{}, wrapDBError("failed to execute bulk insert", err)
+	}
+	r.invalidate(model.TableName())
+
+	return InsertResponse{RowsAffected: rowsAffected}, nil
+}
+
+// bulkValues converts validated rows that share the field set of rows[0] into
+// column names and value tuples. Auto-managed timestamps the rows leave out are
+// added as columns and filled with the same current time, and values are passed
+// through the registered converters.
+func (r *Registry) bulkValues(metadata ModelMetadata, rows []map[string]interface{}) ([]string, [][]interface{}, error) {
+	fields := make([]string, 0, len(rows[0]))
+	for name := range rows[0] {
+		fields = append(fields, name)
+	}
+	for name, field := range metadata.Fields {
+		if _, ok := rows[0][name]; !ok && field.AutoTimestamp != AutoNone {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+
+	columns := make([]string, len(fields))
+	for i, name := range fields {
+		columns[i] = metadata.Fields[name].Name
+	}
+	now := time.Now()
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(fields))
+		for j, name := range fields {
+			value := row[name]
+			field := metadata.Fields[name]
+			if field.AutoTimestamp != AutoNone && isUnsetTimestamp(value) {
+				value = now
+			}
+			converted, err := r.convertSetValue(field, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("row %d: invalid value for field %s: %w", i, name, err)
+			}
+			values[i][j] = converted
+		}
+	}
+	return columns, values, nil
+}
+
+// writeRows inserts values into tableName in a single transaction, using COPY
+// for pgx handles and multi-row INSERT statements for database/sql handles.
+// Each statement runs with runRaw, reporting ErrQueryTimeout after timeout.
+func (r *Registry) writeRows(ctx context.Context, db interface{}, tableName string, columns []string, values [][]interface{}, batchSize int, timeout time.Duration) (int64, error) {
+	db, release, err := acquireConn(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	switch db := db.(type) {
+	case *pgx.Conn:
+		return r.copyRows(ctx, db, tableName, columns, values, batchSize, timeout)
+	case *pgxpool.Pool:
+		return r.copyRows(ctx, db, tableName, columns, values, batchSize, timeout)
+	case pgx.Tx:
+		// Begin on a pgx.Tx starts a savepoint inside the caller's transaction
+		return r.copyRows(ctx, db, tableName, columns, values, batchSize, timeout)
+	case *sql.DB:
+		return r.insertRows(ctx, db, tableName, columns, values, batchSize, timeout)
+	case *sql.Conn:
+		return r.insertRows(ctx, db, tableName, columns, values, batchSize, timeout)
+	case *sql.Tx:
+		return r.insertRowsTx(ctx, db, tableName, columns, values, batchSize, timeout)
+	default:
+		return 0, &ErrUnsupportedDB{DB: db}
+	}
+}
+
+// ExecuteBulkInsertModels inserts a slice of model values. Every field of the
+// model is inserted, so use ExecuteBulkInsert with row maps to leave columns
+// to their database defaults.
+func ExecuteBulkInsertModels[T Model](ctx context.Context, db interface{}, models []T, batchSize int) (InsertResponse, error) {
+	return executeBulkInsertModelsWith(ctx, defaultRegistry, db, models, batchSize)
+}
+
+// executeBulkInsertModelsWith implements ExecuteBulkInsertModels with the models of r.
+func executeBulkInsertModelsWith[T Model](ctx context.Context, r *Registry, db interface{}, models []T, batchSize int) (InsertResponse, error) {
+	rows, err := modelsToRowsWith(r, models)
+	if err != nil {
+		return InsertResponse{}, err
+	}
+	return executeBulkInsertWith[T](ctx, r, db, BulkInsertRequest{Rows: rows, BatchSize: batchSize})
+}
+
+// ModelsToRows converts model values into row maps keyed by JSON field name.
+func ModelsToRows[T Model](models []T) ([]map[string]interface{}, error) {
+	return modelsToRowsWith(defaultRegistry, models)
+}
+
+// modelsToRowsWith implements ModelsToRows with the models of r.
+func modelsToRowsWith[T Model](r *Registry, models []T) ([]map[string]interface{}, error) {
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	rows := make([]map[string]interface{}, len(models))
+	for i, m := range models {
+		val := reflect.ValueOf(m)
+		row := make(map[string]interface{}, len(metadata.Fields))
+		for jsonName, field := range metadata.Fields {
+			fieldVal := val.FieldByName(field.GoFieldName)
+			if fieldVal.IsValid() {
+				row[jsonName] = fieldVal.Interface()
+			}
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// pgxBeginner is implemented by *pgx.Conn, *pgxpool.Pool and pgx.Tx.
+type pgxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// copyRows sends values using COPY in batches inside a single transaction.
+func (r *Registry) copyRows(ctx context.Context, db pgxBeginner, tableName string, columns []string, values [][]interface{}, batchSize int, timeout time.Duration) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	table := pgx.Identifier(strings.Split(tableName, "."))
+	// COPY has no SQL of its own; spans and logs show the equivalent statement
+	query := "COPY " + table.Sanitize() + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+	var total int64
+	for start := 0; start < len(values); start += batchSize {
+		end := min(start+batchSize, len(values))
+		err := r.runRaw(ctx, "sqld.copy", tableName, timeout, query, nil, func(ctx context.Context) error {
+			n, err := tx.CopyFrom(ctx, table, columns, pgx.CopyFromRows(values[start:end]))
+			total += n
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// sqlBeginner is implemented by *sql.DB and *sql.Conn.
+type sqlBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// insertRows sends values as multi-row INSERT statements inside a single transaction.
+// Batches are shrunk if needed to stay under the bind parameter limit.
+func (r *Registry) insertRows(ctx context.Context, db sqlBeginner, tableName string, columns []string, values [][]interface{}, batchSize int, timeout time.Duration) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	total, err := r.insertRowsTx(ctx, tx, tableName, columns, values, batchSize, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// insertRowsTx sends values as multi-row INSERT statements inside tx,
+// leaving commit or rollback to the caller.
+func (r *Registry) insertRowsTx(ctx context.Context, tx *sql.Tx, tableName string, columns []string, values [][]interface{}, batchSize int, timeout time.Duration) (int64, error) {
+	batchSize = min(batchSize, maxBindParams/len(columns))
+
+	var total int64
+	for start := 0; start < len(values); start += batchSize {
+		end := min(start+batchSize, len(values))
+
+		builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Insert(tableName).
+			Columns(columns...)
+		for _, row := range values[start:end] {
+			builder = builder.Values(row...)
+		}
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return 0, err
+		}
+		err = r.runRaw(ctx, "sqld.exec", tableName, timeout, query, args, func(ctx context.Context) error {
+			n, err := execStatement(ctx, tx, query, args...)
+			total += n
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// RowError describes an invalid value in a row passed to ValidateRows.
+// Field is empty for errors that concern the row as a whole.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d: field %s: %s", e.Row, e.Field, e.Message)
+}
+
+// ValidateRows checks every row against the metadata of T without touching the
+// database, and reports all invalid fields instead of stopping at the first one.
+// It applies the same rules as ExecuteBulkInsert, so import screens can show a
+// complete report before the insert is attempted. Errors are ordered by row and
+// field name; an empty result means every row is valid.
+func ValidateRows[T Model](rows []map[string]interface{}) ([]RowError, error) {
+	return validateRowsWith[T](defaultRegistry, rows)
+}
+
+// validateRowsWith implements ValidateRows with the models of r.
+func validateRowsWith[T Model](r *Registry, rows []map[string]interface{}) ([]RowError, error) {
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	var rowErrors []RowError
+	for i, row := range rows {
+		if len(row) == 0 {
+			rowErrors = append(rowErrors, RowError{Row: i, Message: "row has no values"})
+			continue
+		}
+
+		if i > 0 && !sameFields(rows[0], row) {
+			rowErrors = append(rowErrors, RowError{Row: i, Message: "fields differ from the first row"})
+		}
+		rowErrors = append(rowErrors, validateRow(i, row, metadata)...)
+	}
+	return rowErrors, nil
+}
+
+// validateRow reports every unknown field and invalid value of row, then every
+// missing required field, in field name order.
+func validateRow(rowNum int, row map[string]interface{}, metadata ModelMetadata) []RowError {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var rowErrors []RowError
+	for _, name := range names {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			rowErrors = append(rowErrors, RowError{Row: rowNum, Field: name, Message: "unknown field"})
+			continue
+		}
+		if err := validateFieldValue(field, row[name]); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: rowNum, Field: name, Message: err.Error()})
+		}
+	}
+	for _, name := range missingRequiredFields(metadata, row) {
+		rowErrors = append(rowErrors, RowError{Row: rowNum, Field: name, Message: "missing required field"})
+	}
+	return rowErrors
+}
+
+// sameFields reports whether both rows contain the same set of field names.
+func sameFields(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteBulkInsertValidatesRows(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	var mockDB *MockDB
+
+	tests := []struct {
+		name    string
+		req     BulkInsertRequest
+		wantErr string
+	}{
+		{
+			name:    "no rows",
+			req:     BulkInsertRequest{},
+			wantErr: "bulk insert rows cannot be empty",
+		},
+		{
+			name: "invalid value type",
+			req: BulkInsertRequest{Rows: []map[string]interface{}{
+				{"name": "Jane", "age": 30},
+				{"name": "John", "age": "thirty"},
+			}},
+			wantErr: "row 1: invalid type for field age: expected int, got string",
+		},
+		{
+			name: "different field sets",
+			req: BulkInsertRequest{Rows: []map[string]interface{}{
+				{"name": "Jane", "age": 30},
+				{"name": "John", "email": "john@example.com"},
+			}},
+			wantErr: "row 1: fields differ from the first row",
+		},
+		{
+			name: "negative batch size",
+			req: BulkInsertRequest{
+				Rows:      []map[string]interface{}{{"name": "Jane"}},
+				BatchSize: -1,
+			},
+			wantErr: "batch size must be non-negative",
+		},
+		{
+			name: "valid rows reach the database",
+			req: BulkInsertRequest{Rows: []map[string]interface{}{
+				{"name": "Jane", "age": 30},
+				{"name": "John", "age": 40},
+			}},
+			wantErr: "unsupported database type: *sqld.MockDB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteBulkInsert[BuilderTestModel](context.Background(), mockDB, tt.req)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestModelsToRows(t *testing.T) {
+	require.NoError(t, Register[RegistryTestModel]())
+
+	rows, err := ModelsToRows([]RegistryTestModel{
+		{ID: 1, Name: "first"},
+		{ID: 2, Name: "second"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": 1, "name": "first"},
+		{"id": 2, "name": "second"},
+	}, rows)
+}
+
+func TestValidateRows(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	rowErrors, err := ValidateRows[BuilderTestModel]([]map[string]interface{}{
+		{"name": "Jane", "age": 30},
+		{"name": 42, "age": "thirty"},
+		{"name": "John", "title": "CTO"},
+		{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []RowError{
+		{Row: 1, Field: "age", Message: "invalid type for field age: expected int, got string"},
+		{Row: 1, Field: "name", Message: "invalid type for field name: expected string, got int"},
+		{Row: 2, Message: "fields differ from the first row"},
+		{Row: 2, Field: "title", Message: "unknown field"},
+		{Row: 3, Message: "row has no values"},
+	}, rowErrors)
+	assert.EqualError(t, rowErrors[3], "row 2: field title: unknown field")
+
+	rowErrors, err = ValidateRows[BuilderTestModel]([]map[string]interface{}{{"name": "Jane"}})
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+}
@@ -0,0 +1,38 @@
+package sqld
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+)
+
+// bytesType is the normalized type of bytea fields, such as digests and file
+// contents. Their values are compared as a whole rather than as arrays of bytes,
+// and are given as []byte or as base64 strings, the form encoding/json gives
+// them in results.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// isBytesType reports whether fields of type t hold a bytea column: []byte, or
+// a named byte slice other than json.RawMessage.
+func isBytesType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !isJSONType(t)
+}
+
+// convertBytes is the converter for bytea fields. It decodes base64 strings,
+// as sent by JSON clients, and keeps byte slices.
+func convertBytes(v interface{}) (interface{}, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case string:
+		decoded, err := base64.StdEncoding.DecodeString(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 value")
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to bytes", v)
+}
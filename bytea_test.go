@@ -0,0 +1,72 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ByteaTestFile struct {
+	ID     int64  `json:"id" db:"id"`
+	Digest []byte `json:"digest" db:"digest"`
+}
+
+func (ByteaTestFile) TableName() string {
+	return "files"
+}
+
+func TestByteaFields(t *testing.T) {
+	metadata, err := buildModelMetadata(ByteaTestFile{}, false)
+	require.NoError(t, err)
+	digest := metadata.Fields["digest"]
+	assert.Nil(t, digest.Array)
+	assert.Equal(t, bytesType, digest.NormalizedType)
+	assert.True(t, digest.Nullable)
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "digest", Operator: OpEqual, Value: []byte{0xde, 0xad}},
+			{Field: "digest", Operator: OpIn, Value: []interface{}{"3q0=", "vu8="}},
+			{Field: "digest", Operator: OpIsNotNull},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+	got, err := buildQuery[ByteaTestFile](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM files WHERE digest = $1 AND digest IN ($2,$3) AND digest IS NOT NULL", sql)
+	assert.Equal(t, []interface{}{[]byte{0xde, 0xad}, []byte{0xde, 0xad}, []byte{0xbe, 0xef}}, args)
+
+	req.Where = []Condition{{Field: "digest", Operator: OpAny, Value: 1}}
+	var verr *ValidationError
+	require.ErrorAs(t, BasicValidator{}.ValidateQuery(req, metadata), &verr)
+	assert.Equal(t, CodeNotArrayField, verr.Rejected[0].Code)
+
+	req.Where = []Condition{{Field: "digest", Operator: OpEqual, Value: "not base64!"}}
+	_, err = buildQuery[ByteaTestFile](req)
+	assert.EqualError(t, err, "invalid value for field digest: invalid base64 value")
+
+	insert, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"digest": "3q0="},
+	})
+	require.NoError(t, err)
+	_, args, err = insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]byte{0xde, 0xad}}, args)
+}
+
+func TestByteaResultsEncodeAsBase64(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id", "digest"}, []driver.Value{int64(1), []byte{0xde, 0xad}})
+	resp, err := Execute[ByteaTestFile](context.Background(), db, QueryRequest{Select: []string{"id", "digest"}})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id": 1, "digest": "3q0="}]`, string(encoded))
+}
@@ -0,0 +1,63 @@
+package sqld
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheableModel is implemented by models whose query results may be cached.
+// CacheTTL is recorded in ModelMetadata.CacheTTL when the model is registered,
+// so a cache layer in front of Execute can look it up per table:
+//
+//	func (Department) CacheTTL() time.Duration { return 30 * time.Second }
+type CacheableModel interface {
+	Model
+	CacheTTL() time.Duration
+}
+
+// InvalidationHandler is called with the name of a table after sqld wrote to it.
+type InvalidationHandler func(tableName string)
+
+// OnInvalidate registers a handler in the default registry that is called after
+// every successful write made through sqld: inserts, bulk inserts, imports, soft
+// deletes and restores, and raw DML statements. Cache layers use it to drop
+// cached results of the table. Raw statements invalidate each table they write
+// to, including those of data-modifying WITH queries, under the name of its
+// registered model when the statement adds or omits the public schema.
+//
+// Handlers run synchronously once the statement succeeds. When the write runs
+// inside a transaction, that is before the transaction commits.
+func OnInvalidate(handler InvalidationHandler) {
+	defaultRegistry.OnInvalidate(handler)
+}
+
+// OnInvalidate registers a handler called after every successful write to a table.
+func (r *Registry) OnInvalidate(handler InvalidationHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidationHandlers = append(r.invalidationHandlers, handler)
+}
+
+// invalidate calls the registered invalidation handlers for tableName.
+func (r *Registry) invalidate(tableName string) {
+	r.mu.RLock()
+	handlers := r.invalidationHandlers
+	r.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(tableName)
+	}
+}
+
+// modelCacheTTL returns the cache TTL declared by model, or 0 if the model is not cacheable.
+func modelCacheTTL(model Model) (time.Duration, error) {
+	cacheable, ok := model.(CacheableModel)
+	if !ok {
+		return 0, nil
+	}
+	ttl := cacheable.CacheTTL()
+	if ttl < 0 {
+		return 0, fmt.Errorf("model %s has negative cache TTL %v", model.TableName(), ttl)
+	}
+	return ttl, nil
+}
@@ -0,0 +1,111 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CacheTestModel struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
+
+func (CacheTestModel) TableName() string {
+	return "cache_test_models"
+}
+
+func (CacheTestModel) CacheTTL() time.Duration {
+	return 30 * time.Second
+}
+
+type NegativeCacheTestModel struct {
+	ID int64 `json:"id" db:"id"`
+}
+
+func (NegativeCacheTestModel) TableName() string {
+	return "negative_cache_test_models"
+}
+
+func (NegativeCacheTestModel) CacheTTL() time.Duration {
+	return -time.Second
+}
+
+func TestModelCacheTTL(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(CacheTestModel{}))
+	require.NoError(t, registry.Register(BuilderTestModel{}))
+
+	metadata, err := registry.GetModelMetadata(CacheTestModel{})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, metadata.CacheTTL)
+
+	metadata, err = registry.GetModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+	assert.Zero(t, metadata.CacheTTL)
+
+	err = registry.Register(NegativeCacheTestModel{})
+	assert.EqualError(t, err, "model negative_cache_test_models has negative cache TTL -1s")
+}
+
+func TestInvalidationOnWrites(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	var invalidated []string
+	OnInvalidate(func(tableName string) {
+		invalidated = append(invalidated, tableName)
+	})
+
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+
+	_, err := ExecuteInsert[CacheTestModel](context.Background(), db, InsertRequest{
+		Values: map[string]interface{}{"name": "Jane"},
+	})
+	require.NoError(t, err)
+
+	_, err = ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+		Query:  "DELETE FROM public.employees AS e WHERE e.id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+
+	// Failed writes do not invalidate
+	_, err = ExecuteInsert[CacheTestModel](context.Background(), db, InsertRequest{
+		Values: map[string]interface{}{"title": "CTO"},
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"cache_test_models", "public.employees"}, invalidated)
+}
+
+func TestRawInvalidationTargets(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+	require.NoError(t, Register[CacheTestModel]())
+
+	var invalidated []string
+	OnInvalidate(func(tableName string) {
+		invalidated = append(invalidated, tableName)
+	})
+
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+
+	// Schema-qualified names map to the registered table, and the tables of
+	// data-modifying CTEs are invalidated too
+	_, err := ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+		Query: `WITH moved AS (DELETE FROM public.cache_test_models WHERE id = {{id}} RETURNING id, name),
+			logged AS (INSERT INTO audit_log (entry) SELECT name FROM moved)
+			INSERT INTO archived_models (id) SELECT id FROM moved`,
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archived_models", "cache_test_models", "audit_log"}, invalidated)
+}
@@ -0,0 +1,186 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Client runs queries against one database with its own Registry, so the
+// models, converters, scanners, registered queries and query rules of one
+// database do not leak into another, and parallel tests do not share state
+// through the package-level registry.
+//
+// The Registry methods, such as Register, RegisterQuery and SetQueryRules, are
+// available on the Client. Each Execute function has an On variant running on
+// the database and Registry of a Client, such as ExecuteOn, ExecuteRawOn,
+// ExecuteInsertOn and ExecuteSoftDeleteOn, and so do the helpers that look up
+// models, such as ModelsToRowsOn, MountFromConfigOn and NewConsoleHandlerOn:
+//
+//	reporting := sqld.NewClient(reportingDB)
+//	if err := reporting.Register(Sale{}); err != nil {
+//	    return err
+//	}
+//	resp, err := sqld.ExecuteOn[Sale](ctx, reporting, req)
+type Client struct {
+	*Registry
+	db interface{}
+}
+
+// NewClient returns a Client for db with a new, empty Registry.
+// db may be any handle accepted by Execute.
+func NewClient(db interface{}) *Client {
+	return &Client{Registry: NewRegistry(), db: db}
+}
+
+// DB returns the database handle of the client.
+func (c *Client) DB() interface{} {
+	return c.db
+}
+
+// WithDB returns a Client sharing the Registry of c that runs queries on db,
+// typically a transaction started on the database of c.
+func (c *Client) WithDB(db interface{}) *Client {
+	return &Client{Registry: c.Registry, db: db}
+}
+
+// Close closes the statements prepared on the database of c, see
+// PrepareStatements. It does not close the database itself, which the caller
+// of NewClient owns.
+func (c *Client) Close() error {
+	if db, ok := c.db.(*sql.DB); ok {
+		return c.ClosePreparedStatements(db)
+	}
+	return nil
+}
+
+// ExecuteOn is Execute on the database and Registry of c.
+func ExecuteOn[T Model](ctx context.Context, c *Client, req QueryRequest) (QueryResponse[T], error) {
+	return executeWith[T](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteRawOn is ExecuteRaw on the database and Registry of c.
+func ExecuteRawOn[P Model, R Model](ctx context.Context, c *Client, req ExecuteRawRequest) ([]map[string]interface{}, error) {
+	return executeRawWith[P, R](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteRawExecOn is ExecuteRawExec on the database and Registry of c.
+func ExecuteRawExecOn[P Model](ctx context.Context, c *Client, req ExecuteRawRequest) (int64, error) {
+	return executeRawExecWith[P](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteManyOn is ExecuteMany on the database and Registry of c.
+func ExecuteManyOn(ctx context.Context, c *Client, queries ...ManyQuery) ([]QueryResponse[Model], error) {
+	return executeManyWith(ctx, c.Registry, c.db, queries)
+}
+
+// ExecuteRawExecReturningOn is ExecuteRawExecReturning on the database and
+// Registry of c.
+func ExecuteRawExecReturningOn[P Model, R Model](ctx context.Context, c *Client, req ExecuteRawRequest) ([]map[string]interface{}, error) {
+	return executeRawExecReturningWith[P, R](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteRawSingleOn is ExecuteRawSingle on the database and Registry of c.
+func ExecuteRawSingleOn[P Model, R Model](ctx context.Context, c *Client, req ExecuteRawRequest) (map[string]interface{}, error) {
+	return executeRawSingleWith[P, R](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteRawStreamOn is ExecuteRawStream on the database and Registry of c.
+func ExecuteRawStreamOn[P Model, R Model](ctx context.Context, c *Client, req ExecuteRawRequest, fn func(row map[string]interface{}) error) error {
+	return executeRawStreamWith[P, R](ctx, c.Registry, c.db, req, fn)
+}
+
+// ExecuteRawPaginatedOn is ExecuteRawPaginated on the database and Registry of c.
+func ExecuteRawPaginatedOn[P Model, R Model](ctx context.Context, c *Client, req ExecuteRawRequest, pagination *PaginationRequest) (RawPaginatedResponse, error) {
+	return executeRawPaginatedWith[P, R](ctx, c.Registry, c.db, req, pagination)
+}
+
+// RawBatchQueryOn is RawBatchQuery with the Registry of c. Run the queries
+// with ExecuteRawBatchOn.
+func RawBatchQueryOn[P Model, R Model](c *Client, req ExecuteRawRequest) BatchQuery {
+	return rawBatchQueryWith[P, R](c.Registry, req)
+}
+
+// ExecuteRawBatchOn is ExecuteRawBatch on the database and Registry of c.
+func ExecuteRawBatchOn(ctx context.Context, c *Client, queries ...BatchQuery) ([][]map[string]interface{}, error) {
+	return executeRawBatchWith(ctx, c.Registry, c.db, queries)
+}
+
+// ExplainRawOn is ExplainRaw on the database and Registry of c.
+func ExplainRawOn[P Model](ctx context.Context, c *Client, req ExecuteRawRequest) (json.RawMessage, error) {
+	return explainRawWith[P](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteInsertOn is ExecuteInsert on the database and Registry of c.
+func ExecuteInsertOn[T Model](ctx context.Context, c *Client, req InsertRequest) (InsertResponse, error) {
+	return executeInsertWith[T](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteBulkInsertOn is ExecuteBulkInsert on the database and Registry of c.
+func ExecuteBulkInsertOn[T Model](ctx context.Context, c *Client, req BulkInsertRequest) (InsertResponse, error) {
+	return executeBulkInsertWith[T](ctx, c.Registry, c.db, req)
+}
+
+// ExecuteImportOn is ExecuteImport on the database and Registry of c.
+func ExecuteImportOn[T Model](ctx context.Context, c *Client, r io.Reader, req ImportRequest) (ImportResult, error) {
+	return executeImportWith[T](ctx, c.Registry, c.db, r, req)
+}
+
+// ExecuteSoftDeleteOn is ExecuteSoftDelete on the database and Registry of c.
+func ExecuteSoftDeleteOn[T Model](ctx context.Context, c *Client, req SoftDeleteRequest) (SoftDeleteResponse, error) {
+	return executeSoftDelete[T](ctx, c.Registry, c.db, req, true)
+}
+
+// ExecuteRestoreOn is ExecuteRestore on the database and Registry of c.
+func ExecuteRestoreOn[T Model](ctx context.Context, c *Client, req SoftDeleteRequest) (SoftDeleteResponse, error) {
+	return executeSoftDelete[T](ctx, c.Registry, c.db, req, false)
+}
+
+// ExecuteBulkInsertModelsOn is ExecuteBulkInsertModels on the database and
+// Registry of c.
+func ExecuteBulkInsertModelsOn[T Model](ctx context.Context, c *Client, models []T, batchSize int) (InsertResponse, error) {
+	return executeBulkInsertModelsWith(ctx, c.Registry, c.db, models, batchSize)
+}
+
+// ModelsToRowsOn is ModelsToRows with the Registry of c.
+func ModelsToRowsOn[T Model](c *Client, models []T) ([]map[string]interface{}, error) {
+	return modelsToRowsWith(c.Registry, models)
+}
+
+// ValidateRowsOn is ValidateRows with the Registry of c.
+func ValidateRowsOn[T Model](c *Client, rows []map[string]interface{}) ([]RowError, error) {
+	return validateRowsWith[T](c.Registry, rows)
+}
+
+// ExecuteScopedOn is ExecuteScoped on the database and Registry of c.
+func ExecuteScopedOn[T Model](ctx context.Context, c *Client, keyring *Keyring, token string, req QueryRequest) (QueryResponse[T], error) {
+	return executeScopedWith[T](ctx, c.Registry, c.db, keyring, token, req)
+}
+
+// ExecuteWithPolicyOn is ExecuteWithPolicy on the database and Registry of c.
+func ExecuteWithPolicyOn[T Model](ctx context.Context, c *Client, policy Policy, claims Claims, req QueryRequest) (QueryResponse[T], error) {
+	return executeWithPolicyWith[T](ctx, c.Registry, c.db, policy, claims, req)
+}
+
+// BindFieldsOn is BindFields with the Registry of c.
+func BindFieldsOn[T Model](c *Client, fields interface{}) error {
+	return bindFieldsWith[T](c.Registry, fields)
+}
+
+// ValidateMapParamsAgainstStructNamedOn is ValidateMapParamsAgainstStructNamed
+// with the Registry of c.
+func ValidateMapParamsAgainstStructNamedOn[P any](c *Client, paramMap map[string]interface{}, queryParams []string) ([]interface{}, error) {
+	return validateMapParamsWith[P](c.Registry, paramMap, queryParams)
+}
+
+// MountFromConfigOn is MountFromConfig on the database and Registry of c.
+func MountFromConfigOn(mux *http.ServeMux, c *Client, cfg MountConfig) error {
+	return mountFromConfig(mux, c.Registry, c.db, cfg)
+}
+
+// NewConsoleHandlerOn is NewConsoleHandler on the database and Registry of c.
+func NewConsoleHandlerOn(c *Client, opts ConsoleOptions) (http.Handler, error) {
+	return newConsoleHandler(c.Registry, c.db, opts)
+}
@@ -0,0 +1,179 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientsHaveSeparateRegistries(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	locked := NewClient(db)
+	require.NoError(t, locked.Register(TestParams{}))
+	require.NoError(t, locked.Register(TestResult{}))
+	name, err := locked.RegisterQuery("by_id", "SELECT id, name FROM test_results WHERE id = {{id}}", nil)
+	require.NoError(t, err)
+	locked.LockRawQueries()
+	locked.SetQueryRules(QueryRules{AllowedTables: []string{"test_results"}})
+
+	open := NewClient(db)
+
+	// The locked client only runs its registered query
+	results, err := ExecuteRawOn[TestParams, TestResult](context.Background(), locked, ExecuteRawRequest{
+		QueryName: name,
+		Params:    map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": 1, "name": "Jane"}}, results)
+
+	_, err = ExecuteRawOn[TestParams, TestResult](context.Background(), locked, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE name = {{name}}",
+		Params: map[string]interface{}{"name": "Jane"},
+	})
+	assert.ErrorIs(t, err, ErrQueryNotAllowed)
+
+	_, err = ExecuteRawExecOn[TestParams](context.Background(), locked.WithDB(db), ExecuteRawRequest{
+		QueryName: name,
+		Params:    map[string]interface{}{"id": 1},
+	})
+	assert.EqualError(t, err, "only INSERT, UPDATE and DELETE statements are allowed")
+
+	// The other client has neither the query lock nor the rules
+	_, err = ExecuteRawExecOn[TestParams](context.Background(), open, ExecuteRawRequest{
+		Query:  "DELETE FROM audit_log WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"SELECT id, name FROM test_results WHERE id = $1",
+		"DELETE FROM audit_log WHERE id = $1",
+	}, fake.statements)
+
+	_, err = open.modelByTable("test_results")
+	assert.Error(t, err)
+}
+
+func TestExecuteOn(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	client := NewClient(db)
+
+	resp, err := ExecuteOn[TestResult](context.Background(), client, QueryRequest{
+		Select: []string{"id", "name"},
+		Where:  []Condition{{Field: "id", Operator: OpEqual, Value: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "name": "Jane"}}, resp.Data)
+	assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE id = $1"}, fake.statements)
+
+	// The model was registered with the client only
+	_, err = client.modelByTable("test_results")
+	assert.NoError(t, err)
+	_, err = defaultRegistry.modelByTable("test_results")
+	assert.Error(t, err)
+	assert.Same(t, db, client.DB())
+}
+
+func TestClientWritesAndRawVariants(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	var clientTables, defaultTables []string
+	OnInvalidate(func(table string) { defaultTables = append(defaultTables, table) })
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	client := NewClient(db)
+	client.OnInvalidate(func(table string) { clientTables = append(clientTables, table) })
+	client.SetQueryRules(QueryRules{AllowedTables: []string{"test_results"}})
+
+	// Writes notify the handlers of the client only
+	_, err := ExecuteInsertOn[TestResult](context.Background(), client, InsertRequest{
+		Values: map[string]interface{}{"id": 2, "name": "Joe"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test_results"}, clientTables)
+	assert.Empty(t, defaultTables)
+
+	// Raw variants follow the rules of the client
+	row, err := ExecuteRawSingleOn[TestParams, TestResult](context.Background(), client, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": 1, "name": "Jane"}, row)
+
+	req := ExecuteRawRequest{Query: "SELECT id, name FROM audit_log"}
+	_, err = ExecuteRawPaginatedOn[TestParams, TestResult](context.Background(), client, req, nil)
+	assert.Error(t, err)
+	_, err = ExecuteRawBatchOn(context.Background(), client, RawBatchQueryOn[TestParams, TestResult](client, req))
+	assert.Error(t, err)
+	assert.Len(t, fake.statements, 2)
+
+	_, err = client.modelByTable("test_results")
+	assert.NoError(t, err)
+	_, err = defaultRegistry.modelByTable("test_results")
+	assert.Error(t, err)
+}
+
+func TestClientHelperVariants(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	client := NewClient(db)
+	ctx := context.Background()
+
+	rows, err := ModelsToRowsOn(client, []TestResult{{ID: 1, Name: "Jane"}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": 1, "name": "Jane"}}, rows)
+	rowErrors, err := ValidateRowsOn[TestResult](client, rows)
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+
+	var fields struct{ ID FieldRef[int] }
+	require.NoError(t, BindFieldsOn[TestResult](client, &fields))
+	args, err := ValidateMapParamsAgainstStructNamedOn[TestParams](client, map[string]interface{}{"id": 1}, []string{"id"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1}, args)
+
+	require.NoError(t, For[TestResult]().Select("id", "name").ValidateOn(client))
+	_, err = For[TestResult]().Select("id", "name").ExecuteOn(ctx, client)
+	require.NoError(t, err)
+
+	allow := PolicyFunc(func(context.Context, PolicyInput) (PolicyDecision, error) {
+		return PolicyDecision{Allow: true}, nil
+	})
+	_, err = ExecuteWithPolicyOn[TestResult](ctx, client, allow, Claims{}, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+	token, err := keyring.MintScopeToken(QueryScope{Model: "test_results", AllowedFields: []string{"id"}})
+	require.NoError(t, err)
+	_, err = ExecuteScopedOn[TestResult](ctx, client, keyring, token, QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	// Handlers find the models registered with the client
+	cfg := MountConfig{Endpoints: []EndpointConfig{{Path: "/results", Model: "test_results"}}}
+	assert.Error(t, MountFromConfig(http.NewServeMux(), db, cfg))
+	require.NoError(t, MountFromConfigOn(http.NewServeMux(), client, cfg))
+	console, err := NewConsoleHandlerOn(client, ConsoleOptions{Authorize: func(*http.Request) bool { return true }})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	console.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/models", nil))
+	assert.Contains(t, rec.Body.String(), `"table_name":"test_results"`)
+
+	assert.Len(t, fake.statements, 3)
+	_, err = defaultRegistry.modelByTable("test_results")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package sqld
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+// validateResultColumns checks that every output column of a raw SELECT has a
+// field with a matching db tag in the result model, so that a misnamed column
+// fails before execution instead of as a scan error. Columns whose name cannot be
+// told from the query, such as those of * or of unaliased expressions, are not checked.
+func validateResultColumns(query string, metadata ModelMetadata) error {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return fmt.Errorf("SQL syntax error: %w", err)
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return fmt.Errorf("only SELECT statements are allowed")
+	}
+
+	columns := make(map[string]bool, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		columns[field.Name] = true
+	}
+
+	for _, name := range outputColumnNames(sel) {
+		if !columns[name] {
+			return fmt.Errorf("column %s has no destination field in result model %s", name, metadata.TableName)
+		}
+	}
+	return nil
+}
+
+// outputColumnNames returns the names of the output columns of sel that can be
+// told from the query. The columns of a UNION are named by its first SELECT.
+func outputColumnNames(sel *tree.Select) []string {
+	switch clause := sel.Select.(type) {
+	case *tree.ParenSelect:
+		return outputColumnNames(clause.Select)
+	case *tree.UnionClause:
+		return outputColumnNames(clause.Left)
+	case *tree.SelectClause:
+		var names []string
+		for _, expr := range clause.Exprs {
+			if name, ok := outputColumnName(expr); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// outputColumnName returns the name PostgreSQL gives to the column of expr:
+// its alias, the column it selects, or the function it calls.
+func outputColumnName(expr tree.SelectExpr) (string, bool) {
+	if expr.As != "" {
+		return string(expr.As), true
+	}
+	switch e := expr.Expr.(type) {
+	case *tree.UnresolvedName:
+		if e.Star {
+			return "", false
+		}
+		return e.Parts[0], true
+	case *tree.FuncExpr:
+		if name, ok := e.Func.FunctionReference.(*tree.UnresolvedName); ok {
+			return name.Parts[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,78 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResultColumns(t *testing.T) {
+	require.NoError(t, Register[TestResult]())
+	metadata, err := getModelMetadata(TestResult{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{name: "columns", query: "SELECT id, name FROM test_results"},
+		{name: "qualified columns", query: "SELECT t.id, t.name FROM test_results t"},
+		{name: "aliases", query: "SELECT e.emp_id AS id, upper(e.first_name) AS name FROM employees e"},
+		{name: "star", query: "SELECT * FROM test_results"},
+		{name: "unaliased expression", query: "SELECT id, 1 + 1 FROM test_results"},
+		{name: "union", query: "(SELECT id, name FROM a) UNION SELECT x, y FROM b"},
+		{
+			name:    "unknown column",
+			query:   "SELECT id, first_name FROM employees",
+			wantErr: "column first_name has no destination field in result model test_results",
+		},
+		{
+			name:    "unknown alias",
+			query:   "SELECT id, count(*) AS total FROM test_results GROUP BY id",
+			wantErr: "column total has no destination field in result model test_results",
+		},
+		{
+			name:    "unknown function name",
+			query:   "SELECT id, count(*) FROM test_results GROUP BY id",
+			wantErr: "column count has no destination field in result model test_results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateResultColumns(tt.query, metadata)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExecuteRawUnknownColumn(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, fake := newFakeDB(t, nil)
+
+	_, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name, salary FROM employees WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	assert.EqualError(t, err, "column salary has no destination field in result model test_results")
+	assert.Empty(t, fake.statements)
+}
+
+func TestValidateSelectFields(t *testing.T) {
+	require.NoError(t, Register[TestResult]())
+	metadata, err := getModelMetadata(TestResult{})
+	require.NoError(t, err)
+
+	assert.NoError(t, validateSelectFields(nil, metadata))
+	assert.NoError(t, validateSelectFields([]string{"id", "name"}, metadata))
+	assert.EqualError(t, validateSelectFields([]string{"id", "nmae", "salary"}, metadata),
+		"unknown select fields for result model test_results: nmae, salary")
+}
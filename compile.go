@@ -0,0 +1,229 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// maxCompiledQueries bounds the number of request shapes whose SQL is kept per
+// model. Once it is reached the model's cache starts over, so shapes that are
+// no longer requested do not pile up.
+const maxCompiledQueries = 256
+
+// compiledQuery is the SQL shared by the requests of one shape, see
+// queryShape. Requests of the same shape only differ in the values of their
+// conditions, which are the arguments of all its statements, and in their limit
+// and offset, which are appended as parameters with pageClause.
+type compiledQuery struct {
+	sql          string   // SELECT of the rows, without LIMIT and OFFSET
+	countSQL     string   // SELECT COUNT(*) of the rows
+	aggregateSQL string   // SELECT of the aggregates, if any
+	facetSQL     []string // SELECT of the value counts of each facet, in request order
+}
+
+// queryCache holds the compiled queries of a registered model by request shape.
+// Copies of the model's metadata share it. A nil cache keeps nothing.
+type queryCache struct {
+	mu      sync.RWMutex
+	queries map[string]*compiledQuery
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{queries: make(map[string]*compiledQuery)}
+}
+
+func (c *queryCache) get(shape string) *compiledQuery {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.queries[shape]
+}
+
+func (c *queryCache) put(shape string, compiled *compiledQuery) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queries) >= maxCompiledQueries {
+		c.queries = make(map[string]*compiledQuery)
+	}
+	c.queries[shape] = compiled
+}
+
+// compileQuery resolves the pagination of req and returns the resolved
+// request, its SQL and the arguments of its conditions, and whether the SQL
+// was cached. The SQL is built and req fully validated the first time a shape
+// of request is seen; later requests of the same shape only have their values
+// validated and converted. Validation and building are traced as the spans
+// sqld.validate and sqld.build.
+func (r *Registry) compileQuery(ctx context.Context, metadata ModelMetadata, req QueryRequest) (QueryRequest, *compiledQuery, []interface{}, bool, error) {
+	resolved := resolvePagination(req)
+
+	_, span := r.startSpan(ctx, "sqld.validate", metadata.TableName)
+
+	// Values are checked before the shape is taken, since it converts them.
+	// Invalid requests are left to the validator, which reports all violations.
+	var shape string
+	if len(valueViolations(req, metadata)) == 0 {
+		var args []interface{}
+		var err error
+		shape, args, err = r.queryShape(metadata, resolved)
+		if err == nil {
+			if compiled := metadata.queries.get(shape); compiled != nil {
+				span.SetAttributes(spanCacheHitKey.Bool(true))
+				span.End()
+				return resolved, compiled, args, true, nil
+			}
+		} else {
+			shape = ""
+		}
+	}
+
+	validator := BasicValidator{}
+	if err := validator.ValidateQuery(req, metadata); err != nil {
+		err = fmt.Errorf("failed to validate query: %w", err)
+		endSpan(span, err)
+		return QueryRequest{}, nil, nil, false, err
+	}
+	span.SetAttributes(spanCacheHitKey.Bool(false))
+	span.End()
+	req = resolved
+
+	_, span = r.startSpan(ctx, "sqld.build", metadata.TableName)
+	compiled, args, err := r.buildCompiledQuery(metadata, req)
+	if err != nil {
+		endSpan(span, err)
+		return QueryRequest{}, nil, nil, false, err
+	}
+	span.SetAttributes(spanSQL(compiled.sql))
+	span.End()
+
+	if shape != "" {
+		metadata.queries.put(shape, compiled)
+	}
+	return req, compiled, args, false, nil
+}
+
+// buildCompiledQuery builds the SQL of req, a validated request, and returns it
+// with the arguments of its conditions.
+func (r *Registry) buildCompiledQuery(metadata ModelMetadata, req QueryRequest) (*compiledQuery, []interface{}, error) {
+	// The limit and offset are left out of the cached SQL, see pageClause
+	unpaged := req
+	unpaged.Limit, unpaged.Offset = nil, nil
+	builder, err := r.buildSelectQuery(metadata.TableName, metadata, unpaged)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+
+	// The count query applies the same where conditions, so it takes the same arguments
+	countBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select("COUNT(*)").
+		From(metadata.TableName)
+	countBuilder, err = r.applyWhere(countBuilder, metadata, req.Where)
+	if err != nil {
+		return nil, nil, err
+	}
+	countQuery, _, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate count sql: %w", err)
+	}
+	compiled := &compiledQuery{sql: query, countSQL: countQuery}
+
+	// Aggregates and facets apply the where conditions too, and take the same arguments
+	if len(req.Aggregates) > 0 {
+		aggBuilder, err := r.buildAggregateQuery(metadata.TableName, metadata, req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build aggregate query: %w", err)
+		}
+		if compiled.aggregateSQL, _, err = aggBuilder.ToSql(); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate aggregate sql: %w", err)
+		}
+	}
+	for _, facet := range req.Facets {
+		facetBuilder, err := r.buildFacetQuery(metadata.TableName, metadata, req, facet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build facet query: %w", err)
+		}
+		facetQuery, _, err := facetBuilder.ToSql()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate facet sql: %w", err)
+		}
+		compiled.facetSQL = append(compiled.facetSQL, facetQuery)
+	}
+	return compiled, args, nil
+}
+
+// queryShape returns the key under which the SQL of req is cached, with the
+// arguments of its conditions. The shape of a request is made of its select
+// list, the SQL of its conditions, its order by, aggregates and facets, and
+// whether it has a limit and an offset. Condition values are part of the shape
+// only as far as they change the SQL, as the number of values of IN does.
+func (r *Registry) queryShape(metadata ModelMetadata, req QueryRequest) (string, []interface{}, error) {
+	clauses, err := r.whereClauses(metadata, req.Where)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "select %q where", req.Select)
+	var args []interface{}
+	for _, clause := range clauses {
+		sql, clauseArgs, err := clause.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&b, " %q", sql)
+		args = append(args, clauseArgs...)
+	}
+	b.WriteString(" order by")
+	for _, orderBy := range req.OrderBy {
+		fmt.Fprintf(&b, " %q %t", orderBy.Field, orderBy.Desc)
+	}
+	b.WriteString(" aggregates")
+	for _, agg := range req.Aggregates {
+		fmt.Fprintf(&b, " %q %q", agg.Func, agg.Field)
+	}
+	fmt.Fprintf(&b, " facets %q limit %t offset %t", req.Facets, req.Limit != nil, req.Offset != nil)
+	return b.String(), args, nil
+}
+
+// valueViolations checks the parts of req that may change between requests of
+// the same shape: condition values, limit, offset and time zone.
+func valueViolations(req QueryRequest, metadata ModelMetadata) []Violation {
+	return append(whereViolations(req.Where, metadata), optionViolations(req)...)
+}
+
+// pageClause returns the LIMIT and OFFSET of req as parameters numbered after
+// the argc arguments of the query they are appended to, with their values.
+// Binding them keeps the SQL of every page of a shape the same, so one
+// prepared statement serves them all.
+func pageClause(req QueryRequest, argc int) (string, []interface{}, error) {
+	var clause string
+	var args []interface{}
+	if req.Limit != nil {
+		if *req.Limit < 0 {
+			return "", nil, fmt.Errorf("limit must be non-negative")
+		}
+		args = append(args, *req.Limit)
+		clause += fmt.Sprintf(" LIMIT $%d", argc+len(args))
+	}
+	if req.Offset != nil {
+		if *req.Offset < 0 {
+			return "", nil, fmt.Errorf("offset must be non-negative")
+		}
+		args = append(args, *req.Offset)
+		clause += fmt.Sprintf(" OFFSET $%d", argc+len(args))
+	}
+	return clause, args, nil
+}
@@ -0,0 +1,150 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CompileTestAccount struct {
+	ID     int64  `json:"id" db:"id"`
+	Owner  string `json:"owner" db:"owner_name"`
+	Status string `json:"status" db:"status"`
+}
+
+func (CompileTestAccount) TableName() string {
+	return "accounts"
+}
+
+func TestCompiledQueriesAreReusedAcrossValues(t *testing.T) {
+	registry := NewRegistry()
+	db, fake := newFakeDB(t, []string{"id", "owner_name"}, []driver.Value{int64(1), "Jane"})
+
+	for i, owner := range []string{"Jane", "John"} {
+		fake.queue([]string{"count"}, []driver.Value{int64(1)})
+		req := QueryRequest{
+			Select:     []string{"id", "owner"},
+			Where:      []Condition{{Field: "owner", Operator: OpEqual, Value: owner}},
+			OrderBy:    []OrderByClause{{Field: "id"}},
+			Pagination: &PaginationRequest{Page: i + 1, PageSize: 10},
+		}
+		resp, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+		require.NoError(t, err)
+		assert.Len(t, resp.Data, 1)
+	}
+
+	require.Len(t, fake.statements, 4)
+	assert.Equal(t, "SELECT COUNT(*) FROM accounts WHERE owner_name = $1", fake.statements[0])
+	assert.Equal(t, "SELECT id, owner_name FROM accounts WHERE owner_name = $1 ORDER BY id ASC LIMIT $2 OFFSET $3", fake.statements[1])
+	assert.Equal(t, fake.statements[0], fake.statements[2])
+	assert.Equal(t, fake.statements[1], fake.statements[3])
+	assert.Equal(t, []driver.Value{"John", int64(10), int64(10)}, fake.args[3])
+
+	metadata, err := registry.GetModelMetadata(CompileTestAccount{})
+	require.NoError(t, err)
+	assert.Len(t, metadata.queries.queries, 1)
+}
+
+func TestQueryShape(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(CompileTestAccount{}))
+	metadata, err := registry.GetModelMetadata(CompileTestAccount{})
+	require.NoError(t, err)
+
+	shapeOf := func(where ...Condition) string {
+		shape, _, err := registry.queryShape(metadata, QueryRequest{Select: []string{"id"}, Where: where})
+		require.NoError(t, err)
+		return shape
+	}
+
+	assert.Equal(t,
+		shapeOf(Condition{Field: "status", Operator: OpIn, Value: []interface{}{"open", "closed"}}),
+		shapeOf(Condition{Field: "status", Operator: OpIn, Value: []interface{}{"frozen", "open"}}))
+	assert.NotEqual(t,
+		shapeOf(Condition{Field: "status", Operator: OpIn, Value: []interface{}{"open", "closed"}}),
+		shapeOf(Condition{Field: "status", Operator: OpIn, Value: []interface{}{"open", "closed", "frozen"}}))
+	assert.NotEqual(t,
+		shapeOf(Condition{Field: "status", Operator: OpEqual, Value: "open"}),
+		shapeOf(Condition{Field: "owner", Operator: OpEqual, Value: "open"}))
+
+	_, args, err := registry.queryShape(metadata, QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "status", Operator: OpIn, Value: []interface{}{"open", "closed"}},
+			{Field: "owner", Operator: OpEqual, Value: "Jane"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"open", "closed", "Jane"}, args)
+}
+
+func TestCompiledQueriesStillValidateValues(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(CompileTestAccount{}))
+	metadata, err := registry.GetModelMetadata(CompileTestAccount{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where:  []Condition{{Field: "id", Operator: OpEqual, Value: 1}},
+	}
+	_, first, _, cached, err := registry.compileQuery(context.Background(), metadata, req)
+	require.NoError(t, err)
+	assert.False(t, cached)
+
+	req.Where[0].Value = 2
+	_, second, args, cached, err := registry.compileQuery(context.Background(), metadata, req)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Same(t, first, second)
+	assert.Equal(t, []interface{}{2}, args)
+
+	req.Where[0].Value = "two"
+	_, _, _, _, err = registry.compileQuery(context.Background(), metadata, req)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "where[0].value", verr.Rejected[0].Path)
+
+	// Values of requests selecting all fields are validated too
+	req.Select = []string{SelectAll}
+	req.Where[0].Value = 3
+	_, _, _, _, err = registry.compileQuery(context.Background(), metadata, req)
+	require.NoError(t, err)
+	req.Where[0].Value = "three"
+	_, _, _, cached, err = registry.compileQuery(context.Background(), metadata, req)
+	require.ErrorAs(t, err, &verr)
+	assert.False(t, cached)
+	assert.Equal(t, "where[0].value", verr.Rejected[0].Path)
+}
+
+func TestCompiledQueriesIncludeAggregatesAndFacets(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(CompileTestAccount{}))
+	metadata, err := registry.GetModelMetadata(CompileTestAccount{})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		Select:     []string{"id"},
+		Where:      []Condition{{Field: "owner", Operator: OpEqual, Value: "Jane"}},
+		Aggregates: []Aggregate{{Func: AggCount, Field: "id"}},
+		Facets:     []string{"status", "owner"},
+	}
+	_, first, _, _, err := registry.compileQuery(context.Background(), metadata, req)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(id) AS agg_0 FROM accounts WHERE owner_name = $1", first.aggregateSQL)
+	assert.Equal(t, []string{
+		"SELECT status AS value, COUNT(*) AS count FROM accounts WHERE owner_name = $1 GROUP BY status ORDER BY count DESC, status ASC LIMIT 100",
+		"SELECT owner_name AS value, COUNT(*) AS count FROM accounts WHERE owner_name = $1 GROUP BY owner_name ORDER BY count DESC, owner_name ASC LIMIT 100",
+	}, first.facetSQL)
+
+	// Requests of the same shape run the cached aggregate and facet SQL
+	req.Where[0].Value = "John"
+	_, second, args, cached, err := registry.compileQuery(context.Background(), metadata, req)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Same(t, first, second)
+	assert.Equal(t, []interface{}{"John"}, args)
+}
@@ -0,0 +1,75 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AutoRegisterComposites queries the current schema for all user-defined
+// composite types, created with CREATE TYPE ... AS (...), then registers them
+// and their array types in pgx's TypeMap so rows holding composite columns
+// scan instead of failing with unknown OID errors. Row types of tables are
+// left out.
+//
+// The field types of the composites must be known to pgx: built-in types,
+// other composites of the schema, or enums registered first with
+// AutoRegisterEnums. ConfigurePool does both in that order.
+func AutoRegisterComposites(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `
+		SELECT t.typname
+		FROM pg_type t
+		JOIN pg_namespace n ON t.typnamespace = n.oid
+		JOIN pg_class c ON c.oid = t.typrelid
+		WHERE t.typtype = 'c'
+		  AND c.relkind = 'c'
+		  AND n.nspname = current_schema()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query pg_type for composite types: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var typname string
+		if scanErr := rows.Scan(&typname); scanErr != nil {
+			return fmt.Errorf("failed to scan row for composite type: %w", scanErr)
+		}
+		names = append(names, typname)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return registerDerivedTypes(ctx, conn, "composite", names)
+}
+
+// registerDerivedTypes loads the types named names, and arrays of them, and
+// registers them in the TypeMap of conn. pgx loads the types they depend on in
+// the same query. kind names the types in errors.
+func registerDerivedTypes(ctx context.Context, conn *pgx.Conn, kind string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	typeNames := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		typeNames = append(typeNames, name, arrayTypeName(name))
+	}
+	types, err := conn.LoadTypes(ctx, typeNames)
+	if err != nil {
+		return fmt.Errorf("failed to load %s types: %w", kind, err)
+	}
+	conn.TypeMap().RegisterTypes(types)
+	return nil
+}
+
+// arrayTypeName returns the name of the array type of the type name, which
+// may be qualified with its schema: "_address" for "address" and
+// "billing._address" for "billing.address".
+func arrayTypeName(name string) string {
+	i := strings.LastIndexByte(name, '.')
+	return name[:i+1] + "_" + name[i+1:]
+}
@@ -0,0 +1,12 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayTypeName(t *testing.T) {
+	assert.Equal(t, "_address", arrayTypeName("address"))
+	assert.Equal(t, "billing._address", arrayTypeName("billing.address"))
+}
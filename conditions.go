@@ -0,0 +1,208 @@
+package sqld
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+)
+
+// FieldRef is a typed reference to a model field, where V is the Go type of
+// the field's values. Field references are used with the condition constructors
+// (Eq, Gt, In, ...) so that server-side queries are checked by the compiler:
+// a misspelled field does not compile and a value of the wrong type is rejected.
+//
+// Field references are declared once per model as a struct and bound to the
+// model's metadata at startup with BindFields:
+//
+//	var EmployeeFields struct {
+//	    ID         sqld.FieldRef[int64]
+//	    Department sqld.FieldRef[string]
+//	    Salary     sqld.FieldRef[float64]
+//	}
+//
+//	if err := sqld.BindFields[Employee](&EmployeeFields); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	req := sqld.QueryRequest{
+//	    Select: []string{EmployeeFields.ID.Name()},
+//	    Where: []sqld.Condition{
+//	        sqld.Eq(EmployeeFields.Department, "Engineering"),
+//	        sqld.Gte(EmployeeFields.Salary, 50000),
+//	    },
+//	}
+type FieldRef[V any] struct {
+	name string
+}
+
+// NewFieldRef creates a field reference from a JSON field name without
+// checking it against any model. Prefer BindFields, which validates names and types.
+func NewFieldRef[V any](jsonName string) FieldRef[V] {
+	return FieldRef[V]{name: jsonName}
+}
+
+// Name returns the JSON field name the reference points to.
+func (f FieldRef[V]) Name() string {
+	return f.name
+}
+
+func (f *FieldRef[V]) bind(name string) {
+	f.name = name
+}
+
+func (f *FieldRef[V]) valueType() reflect.Type {
+	return reflect.TypeOf((*V)(nil)).Elem()
+}
+
+// fieldBinder is implemented by *FieldRef[V] for every V.
+type fieldBinder interface {
+	bind(name string)
+	valueType() reflect.Type
+}
+
+// BindFields fills the FieldRef members of the struct pointed to by fields.
+// Each member is matched to the model field with the same Go field name,
+// or to the field whose JSON name is given in a `sqld:"name"` tag.
+// An error is returned if a member matches no field of T or if its value
+// type is not compatible with the field's type.
+func BindFields[T Model](fields interface{}) error {
+	return bindFieldsWith[T](defaultRegistry, fields)
+}
+
+// bindFieldsWith implements BindFields with the models and normalizers of r.
+func bindFieldsWith[T Model](r *Registry, fields interface{}) error {
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	byGoName := make(map[string]Field, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		byGoName[field.GoFieldName] = field
+	}
+
+	v := reflect.ValueOf(fields)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("fields must be a pointer to a struct, got %T", fields)
+	}
+	v = v.Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		binder, ok := v.Field(i).Addr().Interface().(fieldBinder)
+		if !ok {
+			continue
+		}
+
+		var field Field
+		var found bool
+		if jsonName := structField.Tag.Get("sqld"); jsonName != "" {
+			field, found = metadata.Fields[jsonName]
+		} else {
+			field, found = byGoName[structField.Name]
+		}
+		if !found {
+			return fmt.Errorf("field reference %s does not match any field of %s", structField.Name, metadata.TableName)
+		}
+
+		valueType := binder.valueType()
+		if !AreTypesCompatible(field.NormalizedType, r.normalizeType(valueType)) {
+			return fmt.Errorf("field reference %s has type %v, but field %s is %v",
+				structField.Name, valueType, field.JSONName, field.Type)
+		}
+
+		binder.bind(field.JSONName)
+	}
+	return nil
+}
+
+// Eq builds a condition field = value.
+func Eq[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpEqual, Value: value}
+}
+
+// NotEq builds a condition field != value.
+func NotEq[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpNotEqual, Value: value}
+}
+
+// Gt builds a condition field > value.
+func Gt[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpGreaterThan, Value: value}
+}
+
+// Gte builds a condition field >= value.
+func Gte[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpGreaterThanOrEqual, Value: value}
+}
+
+// Lt builds a condition field < value.
+func Lt[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpLessThan, Value: value}
+}
+
+// Lte builds a condition field <= value.
+func Lte[V any](f FieldRef[V], value V) Condition {
+	return Condition{Field: f.name, Operator: OpLessThanOrEqual, Value: value}
+}
+
+// In builds a condition field IN (values...).
+func In[V any](f FieldRef[V], values ...V) Condition {
+	return Condition{Field: f.name, Operator: OpIn, Value: values}
+}
+
+// NotIn builds a condition field NOT IN (values...).
+func NotIn[V any](f FieldRef[V], values ...V) Condition {
+	return Condition{Field: f.name, Operator: OpNotIn, Value: values}
+}
+
+// Like builds a condition field LIKE pattern.
+func Like(f FieldRef[string], pattern string) Condition {
+	return Condition{Field: f.name, Operator: OpLike, Value: pattern}
+}
+
+// ILike builds a condition field ILIKE pattern.
+func ILike(f FieldRef[string], pattern string) Condition {
+	return Condition{Field: f.name, Operator: OpILike, Value: pattern}
+}
+
+// IsNull builds a condition field IS NULL.
+func IsNull[V any](f FieldRef[V]) Condition {
+	return Condition{Field: f.name, Operator: OpIsNull}
+}
+
+// IsNotNull builds a condition field IS NOT NULL.
+func IsNotNull[V any](f FieldRef[V]) Condition {
+	return Condition{Field: f.name, Operator: OpIsNotNull}
+}
+
+// Any builds a condition matching rows whose array field contains value.
+func Any[E any](f FieldRef[[]E], value E) Condition {
+	return Condition{Field: f.name, Operator: OpAny, Value: value}
+}
+
+// Contains builds a condition matching rows whose array field contains all values.
+func Contains[E any](f FieldRef[[]E], values ...E) Condition {
+	return Condition{Field: f.name, Operator: OpContains, Value: values}
+}
+
+// Overlap builds a condition matching rows whose array field shares any element with values.
+func Overlap[E any](f FieldRef[[]E], values ...E) Condition {
+	return Condition{Field: f.name, Operator: OpOverlap, Value: values}
+}
+
+// SubnetOf builds a condition matching rows whose network field is strictly
+// contained in network.
+func SubnetOf[V netip.Addr | netip.Prefix](f FieldRef[V], network netip.Prefix) Condition {
+	return Condition{Field: f.name, Operator: OpSubnetOf, Value: network}
+}
+
+// SupernetOf builds a condition matching rows whose network field strictly
+// contains network.
+func SupernetOf(f FieldRef[netip.Prefix], network netip.Prefix) Condition {
+	return Condition{Field: f.name, Operator: OpSupernetOf, Value: network}
+}
@@ -0,0 +1,60 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindFieldsAndConditions(t *testing.T) {
+	require.NoError(t, Register[ArrayTestModel]())
+
+	var fields struct {
+		ID          FieldRef[int64]
+		Label       FieldRef[string] `sqld:"name"`
+		ReportingTo FieldRef[[]int64]
+	}
+	require.NoError(t, BindFields[ArrayTestModel](&fields))
+	assert.Equal(t, "id", fields.ID.Name())
+	assert.Equal(t, "name", fields.Label.Name())
+
+	req := QueryRequest{
+		Select: []string{fields.ID.Name(), fields.Label.Name()},
+		Where: []Condition{
+			Gt(fields.ID, 10),
+			Like(fields.Label, "J%"),
+			In(fields.ID, 1, 2, 3),
+			Any(fields.ReportingTo, 20),
+			Contains(fields.ReportingTo, 20, 30),
+		},
+	}
+
+	metadata, err := getModelMetadata(ArrayTestModel{})
+	require.NoError(t, err)
+	assert.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	got, err := buildQuery[ArrayTestModel](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, name FROM array_test_models WHERE id > $1 AND name LIKE $2 AND id IN ($3,$4,$5) AND $6 = ANY(reporting_to) AND reporting_to @> $7", sql)
+	assert.Equal(t, []interface{}{int64(10), "J%", int64(1), int64(2), int64(3), int64(20), []int64{20, 30}}, args)
+}
+
+func TestBindFieldsErrors(t *testing.T) {
+	require.NoError(t, Register[ArrayTestModel]())
+
+	var unknown struct {
+		Missing FieldRef[string]
+	}
+	assert.Error(t, BindFields[ArrayTestModel](&unknown))
+
+	var wrongType struct {
+		Name FieldRef[int]
+	}
+	assert.Error(t, BindFields[ArrayTestModel](&wrongType))
+
+	var notPointer struct{}
+	assert.Error(t, BindFields[ArrayTestModel](notPointer))
+}
@@ -0,0 +1,187 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Config holds the connection and execution settings of a service using sqld.
+// NewPool and OpenDB build database handles from it, so services do not each
+// wire pools and timeouts their own way:
+//
+//	cfg := sqld.Config{
+//	    DSN:            os.Getenv("DATABASE_URL"),
+//	    MaxConns:       20,
+//	    AcquireTimeout: 2 * time.Second,
+//	    QueryTimeout:   10 * time.Second,
+//	}
+//	pool, err := sqld.NewPool(ctx, cfg)
+type Config struct {
+	// DSN is the connection string, as accepted by pgx.ParseConfig.
+	DSN string
+
+	// MaxConns is the maximum number of open connections. Zero keeps the
+	// default of the driver.
+	MaxConns int32
+
+	// AcquireTimeout bounds how long a query waits for a free connection of a
+	// *pgxpool.Pool or *sql.DB. Zero waits as long as the query may run.
+	AcquireTimeout time.Duration
+
+	// QueryTimeout cancels structured and raw queries, and the statements of
+	// writes, that run longer, unless a request sets its own Timeout. Zero
+	// leaves queries unbounded.
+	QueryTimeout time.Duration
+
+	// Logger receives the log entries of sqld, see UseLogger. Nil logs
+	// nothing.
+	Logger Logger
+
+	// SlowQueryThreshold makes sqld log, at LogWarn level, the statements of
+	// structured and raw queries and of writes that run at least this long,
+	// with their SQL, the types of their arguments, their duration and their
+	// model. A pgx batch is logged as a whole, with its models and number of
+	// statements. Zero logs no slow queries.
+	SlowQueryThreshold time.Duration
+
+	// CircuitBreaker, if not nil, makes queries fail fast while the database
+	// is unhealthy, see UseCircuitBreaker.
+	CircuitBreaker *CircuitBreaker
+}
+
+// Configure applies the execution settings of cfg to the default registry.
+// See Registry.Configure.
+func Configure(cfg Config) {
+	defaultRegistry.Configure(cfg)
+}
+
+// Configure applies the acquire timeout, query timeout, logger, slow query
+// threshold and circuit breaker of cfg to the queries run with r. NewPool and
+// OpenDB call it, so it is only needed for handles built another way.
+func (r *Registry) Configure(cfg Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acquireTimeout = cfg.AcquireTimeout
+	r.queryTimeout = cfg.QueryTimeout
+	r.logger = cfg.Logger
+	r.slowQueryThreshold = cfg.SlowQueryThreshold
+	r.breaker = cfg.CircuitBreaker
+}
+
+// NewPool builds a pgx pool from cfg with the default registry. See
+// Registry.NewPool.
+func NewPool(ctx context.Context, cfg Config, opts ...PoolOption) (*pgxpool.Pool, error) {
+	return defaultRegistry.NewPool(ctx, cfg, opts...)
+}
+
+// NewPool builds a pgx pool connecting to cfg.DSN with at most cfg.MaxConns
+// connections, prepared for sqld by ConfigurePool with opts, and applies cfg
+// to r.
+func (r *Registry) NewPool(ctx context.Context, cfg Config, opts ...PoolOption) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	ConfigurePool(poolConfig, append([]PoolOption{WithRegistry(r)}, opts...)...)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool: %w", err)
+	}
+	r.Configure(cfg)
+	return pool, nil
+}
+
+// OpenDB opens a database/sql handle from cfg with the default registry. See
+// Registry.OpenDB.
+func OpenDB(cfg Config) (*sql.DB, error) {
+	return defaultRegistry.OpenDB(cfg)
+}
+
+// OpenDB opens a database/sql handle connecting to cfg.DSN through the pgx
+// driver, with at most cfg.MaxConns open connections, and applies cfg to r.
+// Unlike NewPool, it does not register enums and composite types with the
+// connections.
+func (r *Registry) OpenDB(cfg Config) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	db := stdlib.OpenDB(*connConfig)
+	if cfg.MaxConns > 0 {
+		db.SetMaxOpenConns(int(cfg.MaxConns))
+	}
+	r.Configure(cfg)
+	return db, nil
+}
+
+// acquireTimeoutKey is the context key of the acquire timeout of a query, see
+// queryContext.
+type acquireTimeoutKey struct{}
+
+// queryContext returns ctx bounded for a query run with r: by timeout, or the
+// query timeout set with Configure if timeout is not positive, and carrying the
+// acquire timeout of r for acquireConn. It also returns the timeout applied.
+func (r *Registry) queryContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, time.Duration) {
+	r.mu.RLock()
+	if timeout <= 0 {
+		timeout = r.queryTimeout
+	}
+	acquireTimeout := r.acquireTimeout
+	r.mu.RUnlock()
+
+	if acquireTimeout > 0 {
+		ctx = context.WithValue(ctx, acquireTimeoutKey{}, acquireTimeout)
+	}
+	ctx, cancel := withTimeout(ctx, timeout)
+	return ctx, cancel, timeout
+}
+
+// acquireConn takes a connection out of db for a single statement if ctx
+// carries an acquire timeout and db is a pool: a *pgx.Conn of a *pgxpool.Pool
+// or a *sql.Conn of a *sql.DB. release returns it to the pool. Other handles
+// are returned as they are.
+func acquireConn(ctx context.Context, db interface{}) (conn interface{}, release func(), err error) {
+	timeout, ok := ctx.Value(acquireTimeoutKey{}).(time.Duration)
+	if !ok {
+		return db, func() {}, nil
+	}
+	acquireCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch db := db.(type) {
+	case *pgxpool.Pool:
+		poolConn, err := db.Acquire(acquireCtx)
+		if err != nil {
+			return nil, nil, acquireError(ctx, timeout, err)
+		}
+		return poolConn.Conn(), poolConn.Release, nil
+	case *sql.DB:
+		sqlConn, err := db.Conn(acquireCtx)
+		if err != nil {
+			return nil, nil, acquireError(ctx, timeout, err)
+		}
+		return sqlConn, func() { sqlConn.Close() }, nil
+	default:
+		return db, func() {}, nil
+	}
+}
+
+// acquireError reports that no connection was free within timeout if the
+// acquire timeout, and not ctx, ended the wait for err.
+func acquireError(ctx context.Context, timeout time.Duration, err error) error {
+	if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("no connection available within %s: %w", timeout, err)
+	}
+	return err
+}
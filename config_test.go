@@ -0,0 +1,107 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryContext(t *testing.T) {
+	registry := NewRegistry()
+	_, cancel, timeout := registry.queryContext(context.Background(), 0)
+	cancel()
+	assert.Zero(t, timeout)
+
+	registry.Configure(Config{QueryTimeout: time.Minute, AcquireTimeout: time.Second})
+	ctx, cancel, timeout := registry.queryContext(context.Background(), 0)
+	defer cancel()
+	assert.Equal(t, time.Minute, timeout)
+	_, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, ctx.Value(acquireTimeoutKey{}))
+
+	// A timeout of the request wins over the default
+	_, cancel, timeout = registry.queryContext(context.Background(), 5*time.Second)
+	cancel()
+	assert.Equal(t, 5*time.Second, timeout)
+}
+
+func TestConfigQueryTimeout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Configure(Config{QueryTimeout: 10 * time.Millisecond})
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	fake.delay = time.Second
+
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, QueryRequest{Select: []string{"id"}})
+	var timeoutErr *ErrQueryTimeout
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+}
+
+func TestConfigLogger(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewRegistry()
+	registry.Configure(Config{Logger: NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))})
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	req := QueryRequest{
+		Select:     []string{"id"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	}
+
+	// SQL is only logged on demand
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+	_, err = executeWith[CompileTestAccount](WithSQLLogging(context.Background()), registry, db, req)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `level=INFO msg="sqld query" table=accounts sql="SELECT COUNT(*) FROM accounts"`)
+}
+
+func TestAcquireConn(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+
+	conn, release, err := acquireConn(context.Background(), db)
+	require.NoError(t, err)
+	release()
+	assert.Same(t, db, conn)
+
+	ctx := context.WithValue(context.Background(), acquireTimeoutKey{}, time.Second)
+	conn, release, err = acquireConn(ctx, db)
+	require.NoError(t, err)
+	assert.IsType(t, &sql.Conn{}, conn)
+	var ids []int64
+	require.NoError(t, selectAll(ctx, conn, &ids, "SELECT id FROM accounts"))
+	assert.Equal(t, []int64{1}, ids)
+	release()
+
+	// With a single connection held, no other is free
+	db.SetMaxOpenConns(1)
+	held, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer held.Close()
+	ctx = context.WithValue(context.Background(), acquireTimeoutKey{}, 10*time.Millisecond)
+	_, _, err = acquireConn(ctx, db)
+	assert.ErrorContains(t, err, "no connection available within 10ms")
+}
+
+func TestOpenDB(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.OpenDB(Config{DSN: "postgres://%zz"})
+	assert.Error(t, err)
+
+	db, err := registry.OpenDB(Config{DSN: "postgres://localhost/test", MaxConns: 5, QueryTimeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, 5, db.Stats().MaxOpenConnections)
+	assert.Equal(t, time.Second, registry.queryTimeout)
+}
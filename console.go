@@ -0,0 +1,225 @@
+package sqld
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+)
+
+//go:embed console
+var consoleAssets embed.FS
+
+// ConsoleOptions configures the query console.
+type ConsoleOptions struct {
+	// Authorize reports whether r comes from an administrator. Required.
+	Authorize func(r *http.Request) bool
+
+	// Policy, if set, is applied to every request the console explains or runs,
+	// with the claims returned by Claims.
+	Policy Policy
+	Claims func(r *http.Request) Claims
+}
+
+// NewConsoleHandler returns an admin handler for composing and debugging
+// structured queries. It serves a small UI listing the registered models, and
+// shows the SQL generated for a QueryRequest, its EXPLAIN output and its results.
+// Requests go through the same validation as Execute, and through opts.Policy.
+//
+// Mount it under a prefix with http.StripPrefix:
+//
+//	console, err := sqld.NewConsoleHandler(db, sqld.ConsoleOptions{Authorize: isAdmin})
+//	mux.Handle("/admin/sql/", http.StripPrefix("/admin/sql", console))
+//
+// Besides the UI at "/", it serves:
+//
+//	GET  /models   registered models and their fields
+//	POST /sql      generated SQL and arguments of {"model": ..., "request": ...}
+//	POST /explain  the same, with the EXPLAIN (FORMAT JSON) plan of the query
+//	POST /execute  the QueryResponse of the request
+func NewConsoleHandler(db interface{}, opts ConsoleOptions) (http.Handler, error) {
+	return newConsoleHandler(defaultRegistry, db, opts)
+}
+
+// newConsoleHandler implements NewConsoleHandler with the models of r.
+func newConsoleHandler(r *Registry, db interface{}, opts ConsoleOptions) (http.Handler, error) {
+	if opts.Authorize == nil {
+		return nil, fmt.Errorf("console requires an Authorize function")
+	}
+	if opts.Policy != nil && opts.Claims == nil {
+		return nil, fmt.Errorf("console with a policy requires a Claims function")
+	}
+
+	c := &console{registry: r, db: db, opts: opts, mux: http.NewServeMux()}
+	assets, err := fs.Sub(consoleAssets, "console")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load console assets: %w", err)
+	}
+	c.mux.Handle("GET /{$}", http.FileServer(http.FS(assets)))
+	c.mux.HandleFunc("GET /models", c.serveModels)
+	c.mux.HandleFunc("POST /sql", c.serveSQL)
+	c.mux.HandleFunc("POST /explain", c.serveExplain)
+	c.mux.HandleFunc("POST /execute", c.serveExecute)
+	return c, nil
+}
+
+// console serves the query console.
+type console struct {
+	registry *Registry
+	db       interface{}
+	opts     ConsoleOptions
+	mux      *http.ServeMux
+}
+
+// consoleRequest is the body of the console's POST endpoints.
+type consoleRequest struct {
+	Model   string       `json:"model"`
+	Request QueryRequest `json:"request"`
+}
+
+// consoleModel describes a registered model to the console UI.
+type consoleModel struct {
+	TableName string         `json:"table_name"`
+	Fields    []consoleField `json:"fields"`
+}
+
+// consoleField describes a field of a registered model to the console UI.
+type consoleField struct {
+	Name   string `json:"name"`
+	Column string `json:"column"`
+	Type   string `json:"type"`
+}
+
+// consoleSQL is the response of /sql and /explain.
+type consoleSQL struct {
+	SQL  string          `json:"sql"`
+	Args []interface{}   `json:"args"`
+	Plan json.RawMessage `json:"plan,omitempty"` // EXPLAIN (FORMAT JSON) output
+}
+
+func (c *console) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !c.opts.Authorize(r) {
+		writeJSONError(w, http.StatusForbidden, errors.New("forbidden"))
+		return
+	}
+	c.mux.ServeHTTP(w, r)
+}
+
+func (c *console) serveModels(w http.ResponseWriter, r *http.Request) {
+	var models []consoleModel
+	for _, metadata := range c.registry.registeredModels() {
+		model := consoleModel{TableName: metadata.TableName}
+		for _, field := range metadata.Fields {
+			model.Fields = append(model.Fields, consoleField{
+				Name:   field.JSONName,
+				Column: field.Name,
+				Type:   field.Type.String(),
+			})
+		}
+		sort.Slice(model.Fields, func(i, j int) bool { return model.Fields[i].Name < model.Fields[j].Name })
+		models = append(models, model)
+	}
+	writeJSON(w, models)
+}
+
+func (c *console) serveSQL(w http.ResponseWriter, r *http.Request) {
+	_, _, query, args, err := c.prepare(r)
+	if err != nil {
+		writeJSONError(w, consoleErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, consoleSQL{SQL: query, Args: args})
+}
+
+func (c *console) serveExplain(w http.ResponseWriter, r *http.Request) {
+	_, metadata, query, args, err := c.prepare(r)
+	if err != nil {
+		writeJSONError(w, consoleErrorStatus(err), err)
+		return
+	}
+
+	// The plan is taken like the one of ExplainRaw, with the timeout, circuit
+	// breaker, tracing and logging of the registry
+	ctx, cancel, timeout := c.registry.queryContext(r.Context(), 0)
+	defer cancel()
+	var plan json.RawMessage
+	err = c.registry.runRaw(ctx, "sqld.explain", metadata.TableName, timeout, query, args, func(ctx context.Context) (err error) {
+		plan, err = explainQuery(ctx, c.db, query, args)
+		return err
+	})
+	if err != nil {
+		writeJSONError(w, consoleErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, consoleSQL{SQL: query, Args: args, Plan: plan})
+}
+
+func (c *console) serveExecute(w http.ResponseWriter, r *http.Request) {
+	req, metadata, _, _, err := c.prepare(r)
+	if err != nil {
+		writeJSONError(w, consoleErrorStatus(err), err)
+		return
+	}
+
+	resp, err := c.registry.execute(r.Context(), c.db, metadata, req)
+	if err != nil {
+		writeJSONError(w, consoleErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// prepare decodes the console request of r and applies the policy. It returns
+// the resulting query request and model, with the SQL selecting its rows.
+func (c *console) prepare(r *http.Request) (QueryRequest, ModelMetadata, string, []interface{}, error) {
+	body, err := DecodeRequest[consoleRequest](r.Body, DecodeOptions{})
+	if err != nil {
+		return QueryRequest{}, ModelMetadata{}, "", nil, err
+	}
+	metadata, err := c.registry.modelByTable(body.Model)
+	if err != nil {
+		return QueryRequest{}, ModelMetadata{}, "", nil, &DecodeError{Path: "model", Reason: err.Error()}
+	}
+
+	req := body.Request
+	if c.opts.Policy != nil {
+		req, err = ApplyPolicy(r.Context(), c.opts.Policy, c.opts.Claims(r), req, metadata)
+		if err != nil {
+			return QueryRequest{}, ModelMetadata{}, "", nil, err
+		}
+	}
+
+	_, builder, err := c.registry.prepareQuery(metadata, req)
+	if err != nil {
+		return QueryRequest{}, ModelMetadata{}, "", nil, err
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return QueryRequest{}, ModelMetadata{}, "", nil, fmt.Errorf("failed to generate sql: %w", err)
+	}
+	return req, metadata, query, args, nil
+}
+
+// consoleErrorStatus returns the HTTP status reported for err.
+func consoleErrorStatus(err error) int {
+	var decodeErr *DecodeError
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &decodeErr), errors.As(err, &verr):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrPolicyDenied):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,143 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConsole(t *testing.T, db interface{}, policy Policy) http.Handler {
+	t.Helper()
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+	require.NoError(t, Register[TestResult]())
+
+	console, err := NewConsoleHandler(db, ConsoleOptions{
+		Authorize: func(r *http.Request) bool { return r.Header.Get("X-Admin") == "yes" },
+		Policy:    policy,
+		Claims:    func(r *http.Request) Claims { return Claims{"role": r.Header.Get("X-Role")} },
+	})
+	require.NoError(t, err)
+	return console
+}
+
+func serveConsole(console http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Admin", "yes")
+	rec := httptest.NewRecorder()
+	console.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConsole(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	console := newTestConsole(t, db, nil)
+	body := `{"model": "test_results", "request": {"select": ["name"], "where": [{"field": "id", "operator": "=", "value": 1}]}}`
+
+	t.Run("ui", func(t *testing.T) {
+		rec := serveConsole(console, http.MethodGet, "/", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "sqld query console")
+	})
+
+	t.Run("models", func(t *testing.T) {
+		rec := serveConsole(console, http.MethodGet, "/models", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"table_name": "test_results", "fields": [
+			{"name": "id", "column": "id", "type": "int"},
+			{"name": "name", "column": "name", "type": "string"}
+		]}]`, rec.Body.String())
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		rec := serveConsole(console, http.MethodPost, "/sql", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"sql": "SELECT name FROM test_results WHERE id = $1", "args": [1]}`, rec.Body.String())
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		rec := serveConsole(console, http.MethodPost, "/execute", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp QueryResponse[TestResult]
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, []QueryResult{{"name": "Jane"}}, resp.Data)
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		fake.queue([]string{"QUERY PLAN"}, []driver.Value{`[{"Plan": {"Node Type": "Seq Scan"}}]`})
+		rec := serveConsole(console, http.MethodPost, "/explain", body)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{
+			"sql": "SELECT name FROM test_results WHERE id = $1",
+			"args": [1],
+			"plan": [{"Plan": {"Node Type": "Seq Scan"}}]
+		}`, rec.Body.String())
+		assert.Equal(t, "EXPLAIN (FORMAT JSON) SELECT name FROM test_results WHERE id = $1", fake.statements[len(fake.statements)-1])
+	})
+
+	t.Run("explain with open circuit", func(t *testing.T) {
+		breaker := NewCircuitBreaker(1, time.Minute)
+		defaultRegistry.UseCircuitBreaker(breaker)
+		t.Cleanup(func() { defaultRegistry.UseCircuitBreaker(nil) })
+		defaultRegistry.guard(func() error { return context.DeadlineExceeded })
+
+		statements := len(fake.statements)
+		rec := serveConsole(console, http.MethodPost, "/explain", body)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "circuit breaker open")
+		assert.Len(t, fake.statements, statements)
+	})
+}
+
+func TestConsoleRejections(t *testing.T) {
+	db, fake := newFakeDB(t, nil)
+	policy := PolicyFunc(func(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+		return PolicyDecision{Allow: input.Claims["role"] == "dba", Reason: "only DBAs may query"}, nil
+	})
+	console := newTestConsole(t, db, policy)
+
+	rec := httptest.NewRecorder()
+	console.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/models", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "unknown model",
+			body:       `{"model": "missing", "request": {"select": ["id"]}}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid request body at model: no registered model has table missing",
+		},
+		{
+			name:       "policy denied",
+			body:       `{"model": "test_results", "request": {"select": ["id"]}}`,
+			wantStatus: http.StatusForbidden,
+			wantError:  "request denied by policy: only DBAs may query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveConsole(console, http.MethodPost, "/execute", tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantError)
+		})
+	}
+	assert.Empty(t, fake.statements)
+
+	_, err := NewConsoleHandler(db, ConsoleOptions{})
+	assert.EqualError(t, err, "console requires an Authorize function")
+}
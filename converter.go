@@ -0,0 +1,333 @@
+package sqld
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValueConverter converts a plain Go value, as received in a Condition, into
+// the value bound for a field of the type the converter is registered for.
+type ValueConverter func(value interface{}) (interface{}, error)
+
+// RegisterConverter registers a converter for fields of type t in the default registry.
+// Condition values for such fields are passed through the converter before they
+// are bound, so clients can filter with plain values:
+//
+//	sqld.RegisterConverter(reflect.TypeOf(Status("")), func(v interface{}) (interface{}, error) {
+//	    s, ok := v.(string)
+//	    if !ok {
+//	        return nil, fmt.Errorf("expected string, got %T", v)
+//	    }
+//	    return Status(s), nil
+//	})
+//
+// Converters for pgtype.Bool, Text, Int4, Int8, Numeric, Timestamptz and Date are registered by default.
+func RegisterConverter(t reflect.Type, converter ValueConverter) {
+	defaultRegistry.RegisterConverter(t, converter)
+}
+
+// RegisterConverter registers a converter for fields of type t.
+func (r *Registry) RegisterConverter(t reflect.Type, converter ValueConverter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.converters[t] = converter
+}
+
+// GetConverter returns the converter for the given type, if registered.
+func (r *Registry) GetConverter(t reflect.Type) (ValueConverter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	converter, ok := r.converters[t]
+	return converter, ok
+}
+
+// convertConditionValue passes the value of cond through the converter
+// registered for field's type. Values of IN/NOT IN and of array operators are
+// converted element by element. Values without a converter are returned unchanged.
+func (r *Registry) convertConditionValue(field Field, cond Condition) (interface{}, error) {
+	if cond.Value == nil {
+		return nil, nil
+	}
+	if field.JSON {
+		return encodeJSONValue(cond.Value)
+	}
+
+	t := field.Type
+	if field.Array != nil {
+		t, _ = arrayElemType(field.Type)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	converter, ok := r.GetConverter(t)
+	if !ok {
+		switch cond.Operator {
+		case OpIn, OpNotIn, OpContains, OpOverlap:
+			return coerceSlice(cond.Value, t)
+		}
+		return cond.Value, nil
+	}
+
+	switch cond.Operator {
+	case OpIn, OpNotIn, OpContains, OpOverlap:
+		v := reflect.ValueOf(cond.Value)
+		if v.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("operator %s requires a slice value", cond.Operator)
+		}
+		converted := make([]interface{}, v.Len())
+		for i := range converted {
+			elem, err := converter(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = elem
+		}
+		return converted, nil
+	default:
+		return converter(cond.Value)
+	}
+}
+
+// convertSetValue passes a value written to field through the converter
+// registered for field's type, element by element for array fields. Values that
+// already have the field's type are kept if the converter rejects them, array
+// values without a converter are coerced to a typed slice, and values of JSON
+// fields are encoded as JSON.
+func (r *Registry) convertSetValue(field Field, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if field.JSON {
+		return encodeJSONValue(value)
+	}
+
+	t := field.Type
+	if field.Array != nil {
+		t, _ = arrayElemType(field.Type)
+	}
+	elemType := t
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	converter, ok := r.GetConverter(t)
+	if !ok {
+		if field.Array != nil {
+			return coerceSlice(value, elemType)
+		}
+		return value, nil
+	}
+	if field.Array == nil {
+		converted, err := converter(value)
+		if err != nil && reflect.TypeOf(value).AssignableTo(field.Type) {
+			// Custom converters may only take plain values
+			return value, nil
+		}
+		return converted, err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return value, nil
+	}
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		elem, err := converter(item)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		converted[i] = elem
+	}
+	return converted, nil
+}
+
+// convertParamValue converts a raw query parameter to the type of its field in
+// the parameter struct. Compatible values are kept, plain Go values are converted
+// for fields with a registered converter, such as pgtype.Text, and pgtype values
+// are unwrapped for plain fields. It reports false if value cannot be bound for fieldType.
+func (r *Registry) convertParamValue(value interface{}, fieldType reflect.Type) (interface{}, bool) {
+	if AreTypesCompatible(reflect.TypeOf(value), fieldType) {
+		return value, true
+	}
+	if value == nil {
+		return nil, false
+	}
+
+	t := fieldType
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if converter, ok := r.GetConverter(t); ok {
+		if converted, err := converter(value); err == nil {
+			return converted, true
+		}
+	}
+
+	// Unwrap pgtype and other driver.Valuer values for plain fields
+	if f, ok := value.(pgtype.Float64Valuer); ok && IsNumericType(t) {
+		if n, err := f.Float64Value(); err == nil {
+			if !n.Valid {
+				return nil, true
+			}
+			return n.Float64, true
+		}
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, false
+		}
+		if v == nil {
+			return nil, true
+		}
+		if AreTypesCompatible(reflect.TypeOf(v), t) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// defaultConverters returns the converters for the pgtype wrappers understood
+// by normalizeReflectType. A value that already has the wrapper type is kept.
+func defaultConverters() map[reflect.Type]ValueConverter {
+	return map[reflect.Type]ValueConverter{
+		decimalType:     convertDecimal,
+		nullDecimalType: convertDecimal,
+		durationType:    convertInterval,
+		intervalType:    convertInterval,
+		bytesType:       convertBytes,
+		addrType:        convertNetwork,
+		prefixType:      convertNetwork,
+		reflect.TypeOf(pgtype.Bool{}): func(v interface{}) (interface{}, error) {
+			switch b := v.(type) {
+			case pgtype.Bool:
+				return b, nil
+			case bool:
+				return pgtype.Bool{Bool: b, Valid: true}, nil
+			}
+			return nil, fmt.Errorf("cannot convert %T to pgtype.Bool", v)
+		},
+		reflect.TypeOf(pgtype.Text{}): func(v interface{}) (interface{}, error) {
+			switch s := v.(type) {
+			case pgtype.Text:
+				return s, nil
+			case string:
+				return pgtype.Text{String: s, Valid: true}, nil
+			}
+			return nil, fmt.Errorf("cannot convert %T to pgtype.Text", v)
+		},
+		reflect.TypeOf(pgtype.Int4{}): func(v interface{}) (interface{}, error) {
+			if i, ok := v.(pgtype.Int4); ok {
+				return i, nil
+			}
+			n, err := toInt64(v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %T to pgtype.Int4", v)
+			}
+			return pgtype.Int4{Int32: int32(n), Valid: true}, nil
+		},
+		reflect.TypeOf(pgtype.Int8{}): func(v interface{}) (interface{}, error) {
+			if i, ok := v.(pgtype.Int8); ok {
+				return i, nil
+			}
+			n, err := toInt64(v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %T to pgtype.Int8", v)
+			}
+			return pgtype.Int8{Int64: n, Valid: true}, nil
+		},
+		reflect.TypeOf(pgtype.Numeric{}): func(v interface{}) (interface{}, error) {
+			if n, ok := v.(pgtype.Numeric); ok {
+				return n, nil
+			}
+			rv := reflect.ValueOf(v)
+			if !IsNumericType(rv.Type()) {
+				return nil, fmt.Errorf("cannot convert %T to pgtype.Numeric", v)
+			}
+			s := fmt.Sprint(v)
+			if rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
+				s = strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+			}
+			var n pgtype.Numeric
+			if err := n.Scan(s); err != nil {
+				return nil, err
+			}
+			return n, nil
+		},
+		reflect.TypeOf(pgtype.Timestamptz{}): func(v interface{}) (interface{}, error) {
+			switch ts := v.(type) {
+			case pgtype.Timestamptz:
+				return ts, nil
+			case time.Time:
+				return pgtype.Timestamptz{Time: ts, Valid: true}, nil
+			}
+			return nil, fmt.Errorf("cannot convert %T to pgtype.Timestamptz", v)
+		},
+		reflect.TypeOf(pgtype.Date{}): func(v interface{}) (interface{}, error) {
+			switch d := v.(type) {
+			case pgtype.Date:
+				return d, nil
+			case time.Time:
+				return pgtype.Date{Time: d, Valid: true}, nil
+			}
+			return nil, fmt.Errorf("cannot convert %T to pgtype.Date", v)
+		},
+	}
+}
+
+// toInt64 converts integer values, and floats without a fractional part
+// (as decoded from JSON), to int64.
+func toInt64(v interface{}) (int64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != float64(int64(f)) {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		return int64(f), nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to int64", v)
+}
+
+// coerceSlice converts an []interface{}, as decoded from JSON, into a slice of
+// elemType so that it binds as a typed array: [20, 30] arrives as float64
+// elements and becomes []int64{20, 30} for a bigint[] column. Typed slices,
+// and element types that are not basic kinds, are returned unchanged.
+func coerceSlice(value interface{}, elemType reflect.Type) (interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value, nil
+	}
+	switch elemType.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return value, nil
+	}
+
+	coerced := reflect.MakeSlice(reflect.SliceOf(elemType), len(items), len(items))
+	for i, item := range items {
+		v := reflect.ValueOf(item)
+		if !v.IsValid() || !v.Type().ConvertibleTo(elemType) ||
+			(v.Kind() == reflect.String) != (elemType.Kind() == reflect.String) {
+			return nil, fmt.Errorf("element %d: cannot convert %T to %v", i, item, elemType)
+		}
+		if IsNumericType(elemType) && elemType.Kind() != reflect.Float32 && elemType.Kind() != reflect.Float64 {
+			if _, err := toInt64(item); err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		coerced.Index(i).Set(v.Convert(elemType))
+	}
+	return coerced.Interface(), nil
+}
@@ -0,0 +1,157 @@
+package sqld
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type converterTestStatus string
+
+type ConverterTestModel struct {
+	ID     pgtype.Int8         `json:"id" db:"id"`
+	Name   pgtype.Text         `json:"name" db:"name"`
+	Active pgtype.Bool         `json:"active" db:"active"`
+	Status converterTestStatus `json:"status" db:"status"`
+}
+
+func (ConverterTestModel) TableName() string {
+	return "converter_test_models"
+}
+
+func TestConvertWhereValues(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(converterTestStatus("")), func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return converterTestStatus(s), nil
+	})
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "active", Operator: OpEqual, Value: true},
+			{Field: "id", Operator: OpIn, Value: []interface{}{float64(1), float64(2)}},
+			{Field: "name", Operator: OpLike, Value: "J%"},
+			{Field: "status", Operator: OpEqual, Value: "open"},
+		},
+	}
+
+	got, err := buildQuery[ConverterTestModel](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM converter_test_models WHERE active = $1 AND id IN ($2,$3) AND name LIKE $4 AND status = $5", sql)
+	// squirrel.Eq binds driver.Valuer values by calling Value()
+	assert.Equal(t, []interface{}{
+		true,
+		pgtype.Int8{Int64: 1, Valid: true},
+		pgtype.Int8{Int64: 2, Valid: true},
+		pgtype.Text{String: "J%", Valid: true},
+		converterTestStatus("open"),
+	}, args)
+}
+
+func TestConvertWhereValuesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cond Condition
+	}{
+		{"fractional integer", Condition{Field: "id", Operator: OpEqual, Value: 1.5}},
+		{"wrong type", Condition{Field: "active", Operator: OpEqual, Value: "yes"}},
+		{"IN without slice", Condition{Field: "id", Operator: OpIn, Value: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildQuery[ConverterTestModel](QueryRequest{Select: []string{"id"}, Where: []Condition{tt.cond}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConvertInsertValues(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterConverter(reflect.TypeOf(converterTestStatus("")), func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return converterTestStatus(s), nil
+	})
+	metadata, err := buildModelMetadata(ConverterTestModel{}, false)
+	require.NoError(t, err)
+
+	insert, err := registry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"id": float64(7), "active": true, "status": "open"},
+	})
+	require.NoError(t, err)
+	_, args, err := insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		pgtype.Bool{Bool: true, Valid: true},
+		pgtype.Int8{Int64: 7, Valid: true},
+		converterTestStatus("open"),
+	}, args)
+
+	_, values, err := registry.bulkValues(metadata, []map[string]interface{}{
+		{"name": "Jane", "status": converterTestStatus("closed")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{pgtype.Text{String: "Jane", Valid: true}, converterTestStatus("closed")}}, values)
+
+	_, err = registry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"status": 1},
+	})
+	assert.EqualError(t, err, "invalid value for field status: expected string, got int")
+}
+
+type ConverterTestParams struct {
+	Department pgtype.Text    `json:"department" db:"department"`
+	MinSalary  pgtype.Numeric `json:"min_salary" db:"min_salary"`
+	Name       string         `json:"name" db:"name"`
+	MaxSalary  float64        `json:"max_salary" db:"max_salary"`
+}
+
+func (ConverterTestParams) TableName() string {
+	return "converter_test_params"
+}
+
+func TestConvertRawParams(t *testing.T) {
+	require.NoError(t, Register[ConverterTestParams]())
+
+	_, args, err := bindRawParams[ConverterTestParams](defaultRegistry, ExecuteRawRequest{
+		Query: "SELECT id FROM employees WHERE department = {{department}} AND salary >= {{min_salary}} " +
+			"AND salary <= {{max_salary}} AND name = {{name}}",
+		Params: map[string]interface{}{
+			"department": "Engineering",
+			"min_salary": 50000,
+			"name":       pgtype.Text{String: "Jane", Valid: true},
+			"max_salary": pgtype.Numeric{Int: big.NewInt(90000), Valid: true},
+		},
+	})
+	require.NoError(t, err)
+
+	var minSalary pgtype.Numeric
+	require.NoError(t, minSalary.Scan("50000"))
+	assert.Equal(t, []interface{}{pgtype.Text{String: "Engineering", Valid: true}, minSalary, 90000.0, "Jane"}, args)
+
+	_, _, err = bindRawParams[ConverterTestParams](defaultRegistry, ExecuteRawRequest{
+		Query:  "SELECT id FROM employees WHERE department = {{department}}",
+		Params: map[string]interface{}{"department": 42},
+	})
+	assert.EqualError(t, err, "parameter department has wrong type: got int, want pgtype.Text")
+
+	args, err = ValidateMapParamsAgainstStructNamed[ConverterTestParams](
+		map[string]interface{}{"department": "Sales", "name": pgtype.Text{String: "John", Valid: true}},
+		[]string{"department", "name"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{pgtype.Text{String: "Sales", Valid: true}, "John"}, args)
+}
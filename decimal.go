@@ -0,0 +1,155 @@
+package sqld
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// Decimal field types. Fields of these types hold numeric columns without the
+// rounding of float64, so they are validated as numbers but bound as
+// pgtype.Numeric and scanned back into decimals.
+var (
+	decimalType     = reflect.TypeOf(decimal.Decimal{})
+	nullDecimalType = reflect.TypeOf(decimal.NullDecimal{})
+)
+
+// isDecimalType reports whether t, or the type it points to, is a decimal type.
+func isDecimalType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == decimalType || t == nullDecimalType
+}
+
+// toDecimal converts a decimal, a Go number or a numeric string to a decimal.
+// Floats are converted from their shortest representation, so 0.1 stays 0.1.
+func toDecimal(v interface{}) (decimal.Decimal, error) {
+	switch d := v.(type) {
+	case decimal.Decimal:
+		return d, nil
+	case string:
+		return decimal.NewFromString(d)
+	case float32:
+		return decimal.NewFromFloat32(d), nil
+	case float64:
+		return decimal.NewFromFloat(d), nil
+	}
+	n, err := toInt64(v)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("cannot convert %T to decimal", v)
+	}
+	return decimal.NewFromInt(n), nil
+}
+
+// decimalToNumeric returns d as a pgtype.Numeric with the same digits and exponent.
+func decimalToNumeric(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
+
+// convertDecimal is the converter for decimal fields. It binds values as
+// pgtype.Numeric, and a NULL decimal as a NULL numeric.
+func convertDecimal(v interface{}) (interface{}, error) {
+	if n, ok := v.(decimal.NullDecimal); ok {
+		if !n.Valid {
+			return pgtype.Numeric{}, nil
+		}
+		v = n.Decimal
+	}
+	d, err := toDecimal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decimalToNumeric(d), nil
+}
+
+// decimalScanner scans numeric results, such as pgtype.Numeric or their text
+// form, into a decimal.Decimal, or nil for NULL.
+type decimalScanner struct {
+	d decimal.NullDecimal
+}
+
+func (s *decimalScanner) Scan(src interface{}) error {
+	return s.d.Scan(src)
+}
+
+func (s *decimalScanner) Value() interface{} {
+	if !s.d.Valid {
+		return nil
+	}
+	return s.d.Decimal
+}
+
+// defaultScanners returns the scanners registered in every new registry.
+func defaultScanners() map[reflect.Type]func() sql.Scanner {
+	newDecimalScanner := func() sql.Scanner { return &decimalScanner{} }
+	newJSONScanner := func() sql.Scanner { return &jsonScanner{} }
+	return map[reflect.Type]func() sql.Scanner{
+		decimalType:                              newDecimalScanner,
+		nullDecimalType:                          newDecimalScanner,
+		rawJSONType:                              newJSONScanner,
+		reflect.TypeOf(map[string]interface{}{}): newJSONScanner,
+		durationType:                             func() sql.Scanner { return &durationScanner{} },
+		geoJSONType:                              func() sql.Scanner { return &geoJSONScanner{} },
+		addrType:                                 func() sql.Scanner { return &addrScanner{} },
+		prefixType:                               func() sql.Scanner { return &prefixScanner{} },
+	}
+}
+
+// RenderNumericsAsStrings makes the default registry return numeric results as
+// strings. See Registry.RenderNumericsAsStrings.
+func RenderNumericsAsStrings() {
+	defaultRegistry.RenderNumericsAsStrings()
+}
+
+// RenderNumericsAsStrings returns the values of numeric columns in results,
+// whether scanned as pgtype.Numeric or into decimal fields, as their exact
+// text, such as "1234.50". Encoded as JSON numbers they would be parsed into
+// float64 by most clients and lose digits of balances and salaries. NULL stays nil.
+func (r *Registry) RenderNumericsAsStrings() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.numericStrings = true
+}
+
+// numericString returns the exact text of a numeric result value, or nil for
+// NULL. It reports false for values that are not numerics.
+func numericString(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case pgtype.Numeric:
+		if !n.Valid {
+			return nil, true
+		}
+		s, err := n.Value()
+		return s, err == nil
+	case *pgtype.Numeric:
+		if n == nil {
+			return nil, true
+		}
+		return numericString(*n)
+	case decimal.Decimal:
+		return decimalText(n), true
+	case *decimal.Decimal:
+		if n == nil {
+			return nil, true
+		}
+		return decimalText(*n), true
+	case decimal.NullDecimal:
+		if !n.Valid {
+			return nil, true
+		}
+		return decimalText(n.Decimal), true
+	}
+	return nil, false
+}
+
+// decimalText returns d with all the digits of its scale, so 0.10 stays "0.10".
+func decimalText(d decimal.Decimal) string {
+	if d.Exponent() < 0 {
+		return d.StringFixed(-d.Exponent())
+	}
+	return d.String()
+}
@@ -0,0 +1,106 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DecimalTestAccount struct {
+	ID      int64               `json:"id" db:"id"`
+	Balance decimal.Decimal     `json:"balance" db:"balance"`
+	Credit  decimal.NullDecimal `json:"credit" db:"credit"`
+}
+
+func (DecimalTestAccount) TableName() string {
+	return "accounts"
+}
+
+func TestDecimalFields(t *testing.T) {
+	metadata, err := buildModelMetadata(DecimalTestAccount{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "float64", metadata.Fields["balance"].NormalizedType.String())
+	assert.True(t, metadata.Fields["credit"].Nullable)
+
+	got, err := buildQuery[DecimalTestAccount](QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "balance", Operator: OpGreaterThan, Value: 10.1},
+			{Field: "credit", Operator: OpLessThan, Value: decimal.RequireFromString("99.999")},
+		},
+	})
+	require.NoError(t, err)
+	_, args, err := got.ToSql()
+	require.NoError(t, err)
+	// squirrel binds driver.Valuer values by calling Value(), which renders the
+	// numerics exactly
+	assert.Equal(t, []interface{}{"10.1", "99.999"}, args)
+
+	insert, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"balance": 12, "credit": 0.5},
+	})
+	require.NoError(t, err)
+	_, args, err = insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		pgtype.Numeric{Int: big.NewInt(12), Exp: 0, Valid: true},
+		pgtype.Numeric{Int: big.NewInt(5), Exp: -1, Valid: true},
+	}, args)
+
+	err = BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"balance": "12.00"}}, metadata)
+	assert.Error(t, err)
+}
+
+func TestExecuteScansDecimals(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id", "balance", "credit"},
+		[]driver.Value{int64(1), "1234.5678", nil},
+		[]driver.Value{int64(2), []byte("0.10"), "-3"},
+	)
+
+	resp, err := Execute[DecimalTestAccount](context.Background(), db, QueryRequest{Select: []string{"id", "balance", "credit"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+	assert.True(t, decimal.RequireFromString("1234.5678").Equal(resp.Data[0]["balance"].(decimal.Decimal)))
+	assert.Nil(t, resp.Data[0]["credit"])
+	assert.True(t, decimal.RequireFromString("0.1").Equal(resp.Data[1]["balance"].(decimal.Decimal)))
+	assert.True(t, decimal.NewFromInt(-3).Equal(resp.Data[1]["credit"].(decimal.Decimal)))
+}
+
+func TestRenderNumericsAsStrings(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id", "balance", "credit"},
+		[]driver.Value{int64(1), "1234.50", nil},
+		[]driver.Value{int64(2), "12345678901234567890.123456789", "-3"},
+	)
+	client := NewClient(db)
+	client.RenderNumericsAsStrings()
+
+	resp, err := ExecuteOn[DecimalTestAccount](context.Background(), client, QueryRequest{Select: []string{"id", "balance", "credit"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{
+		{"id": int64(1), "balance": "1234.50", "credit": nil},
+		{"id": int64(2), "balance": "12345678901234567890.123456789", "credit": "-3"},
+	}, resp.Data)
+
+	tests := []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{pgtype.Numeric{Int: big.NewInt(1050), Exp: -2, Valid: true}, "10.50"},
+		{pgtype.Numeric{NaN: true, Valid: true}, "NaN"},
+		{pgtype.Numeric{}, nil},
+		{decimal.NullDecimal{}, nil},
+	}
+	for _, tt := range tests {
+		got, ok := numericString(tt.value)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, got)
+	}
+	_, ok := numericString(10.5)
+	assert.False(t, ok)
+}
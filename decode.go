@@ -0,0 +1,254 @@
+package sqld
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	// DefaultMaxRequestBytes is the default size limit for decoded request bodies.
+	DefaultMaxRequestBytes = 1 << 20
+
+	// DefaultMaxRequestDepth is the default nesting limit for decoded request bodies.
+	DefaultMaxRequestDepth = 32
+)
+
+// DecodeOptions configures DecodeRequest.
+type DecodeOptions struct {
+	MaxBytes int64 // Maximum body size in bytes (default: DefaultMaxRequestBytes)
+	MaxDepth int   // Maximum nesting of objects and arrays (default: DefaultMaxRequestDepth)
+}
+
+// DecodeError describes why a request body could not be decoded.
+// Path is the dotted JSON path of the offending key, e.g. "pagination.page_size",
+// without array indexes, and is empty for errors that concern the body as a whole.
+type DecodeError struct {
+	Path     string
+	Expected string // Expected JSON type, set for type mismatches
+	Reason   string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return "invalid request body: " + e.Reason
+	}
+	return fmt.Sprintf("invalid request body at %s: %s", e.Path, e.Reason)
+}
+
+// DecodeQueryRequest strictly decodes a QueryRequest from r.
+// See DecodeRequest for the rules applied.
+func DecodeQueryRequest(r io.Reader, opts DecodeOptions) (QueryRequest, error) {
+	return DecodeRequest[QueryRequest](r, opts)
+}
+
+// DecodeRequest strictly decodes a JSON request body into R.
+// Unlike json.Decoder's defaults it:
+//   - rejects unknown keys, so a misspelled "pagesize" is an error instead of being ignored
+//   - rejects bodies larger than opts.MaxBytes or nested deeper than opts.MaxDepth
+//   - rejects trailing data after the JSON value
+//
+// All failures are returned as *DecodeError.
+func DecodeRequest[R any](r io.Reader, opts DecodeOptions) (R, error) {
+	var req R
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxRequestBytes
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxRequestDepth
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return req, &DecodeError{Reason: fmt.Sprintf("failed to read body: %v", err)}
+	}
+	if int64(len(body)) > maxBytes {
+		return req, &DecodeError{Reason: fmt.Sprintf("body exceeds %d bytes", maxBytes)}
+	}
+
+	if err := checkJSONDepth(body, maxDepth); err != nil {
+		return req, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		decodeErr := toDecodeError(err)
+		// encoding/json only names the unknown key, so find where it is
+		if decodeErr.Reason == unknownFieldReason {
+			if path := unknownFieldPath(body, reflect.TypeOf(&req).Elem()); path != "" {
+				decodeErr.Path = path
+			}
+		}
+		return req, decodeErr
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return req, &DecodeError{Reason: "unexpected data after JSON value"}
+	}
+	return req, nil
+}
+
+// checkJSONDepth walks the tokens of body and fails if objects and arrays
+// are nested deeper than maxDepth.
+func checkJSONDepth(body []byte, maxDepth int) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return toDecodeError(err)
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				return &DecodeError{Reason: fmt.Sprintf("body is nested deeper than %d levels", maxDepth)}
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// unknownFieldReason is the Reason of a DecodeError for an unknown key.
+const unknownFieldReason = "unknown field"
+
+// toDecodeError converts errors from encoding/json into a *DecodeError.
+func toDecodeError(err error) *DecodeError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &DecodeError{Reason: fmt.Sprintf("malformed JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)}
+	case errors.As(err, &typeErr):
+		return &DecodeError{
+			Path:     typeErr.Field,
+			Expected: typeErr.Type.String(),
+			Reason:   fmt.Sprintf("expected %s, got JSON %s", typeErr.Type, typeErr.Value),
+		}
+	case errors.Is(err, io.EOF):
+		return &DecodeError{Reason: "body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{Reason: "body ends unexpectedly"}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		name := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &DecodeError{Path: name, Reason: unknownFieldReason}
+	default:
+		return &DecodeError{Reason: err.Error()}
+	}
+}
+
+// unknownFieldPath returns the dotted path of the first key of body that has
+// no matching field in t, the type body is decoded into, or "" if there is none.
+// Like the paths of type mismatches, it has no array indexes.
+func unknownFieldPath(body []byte, t reflect.Type) string {
+	path, _ := walkUnknownField(json.NewDecoder(bytes.NewReader(body)), t, "")
+	return path
+}
+
+// jsonUnmarshalerType is the type of json.Unmarshaler.
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// walkUnknownField reads the next JSON value of dec, decoded into t at path,
+// and returns the path of its first unknown key. A nil t accepts any value.
+func walkUnknownField(dec *json.Decoder, t reflect.Type, path string) (string, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Values decoding themselves do not reject unknown keys
+	if t != nil && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		t = nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return "", err
+			}
+			key, _ := keyTok.(string)
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+
+			var valueType reflect.Type
+			switch {
+			case t == nil:
+			case t.Kind() == reflect.Map:
+				valueType = t.Elem()
+			case t.Kind() == reflect.Struct:
+				field, ok := jsonField(t, key)
+				if !ok {
+					return keyPath, nil
+				}
+				valueType = field.Type
+			}
+			if found, err := walkUnknownField(dec, valueType, keyPath); found != "" || err != nil {
+				return found, err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for dec.More() {
+			if found, err := walkUnknownField(dec, elemType, path); found != "" || err != nil {
+				return found, err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return "", err
+}
+
+// jsonField returns the field of the struct type t that encoding/json decodes
+// the key into, preferring an exact match of its name to a case-insensitive one.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	var folded bool
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			// The fields of untagged embedded structs are visible fields themselves
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if field.Anonymous && embedded.Kind() == reflect.Struct {
+				continue
+			}
+			name = field.Name
+		}
+		if name == key {
+			return field, true
+		}
+		if !folded && strings.EqualFold(name, key) {
+			fold, folded = field, true
+		}
+	}
+	return fold, folded
+}
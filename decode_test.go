@@ -0,0 +1,101 @@
+package sqld
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeQueryRequest(t *testing.T) {
+	body := `{
+		"select": ["id", "name"],
+		"where": [{"field": "age", "operator": ">", "value": 18}],
+		"pagination": {"page": 2, "page_size": 20}
+	}`
+
+	req, err := DecodeQueryRequest(strings.NewReader(body), DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, req.Select)
+	assert.Equal(t, OpGreaterThan, req.Where[0].Operator)
+	assert.Equal(t, 20, req.Pagination.PageSize)
+}
+
+func TestDecodeQueryRequestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		opts     DecodeOptions
+		wantPath string
+		wantErr  string
+	}{
+		{
+			name:     "misspelled key",
+			body:     `{"select": ["id"], "pagesize": 20}`,
+			wantPath: "pagesize",
+			wantErr:  "invalid request body at pagesize: unknown field",
+		},
+		{
+			name:     "misspelled nested key",
+			body:     `{"select": ["id"], "pagination": {"page": 1, "pagesize": 20}}`,
+			wantPath: "pagination.pagesize",
+			wantErr:  "invalid request body at pagination.pagesize: unknown field",
+		},
+		{
+			name:     "unknown key in array",
+			body:     `{"select": ["id"], "where": [{"field": "age", "operator": "=", "value": 1}, {"field": "age", "op": "="}]}`,
+			wantPath: "where.op",
+			wantErr:  "invalid request body at where.op: unknown field",
+		},
+		{
+			name:     "wrong type",
+			body:     `{"select": ["id"], "pagination": {"page": "one"}}`,
+			wantPath: "pagination.page",
+			wantErr:  "invalid request body at pagination.page: expected int, got JSON string",
+		},
+		{
+			name:    "empty body",
+			body:    ``,
+			wantErr: "invalid request body: body is empty",
+		},
+		{
+			name:    "trailing data",
+			body:    `{"select": ["id"]} {"select": ["name"]}`,
+			wantErr: "invalid request body: unexpected data after JSON value",
+		},
+		{
+			name:    "too large",
+			body:    `{"select": ["id", "name", "email"]}`,
+			opts:    DecodeOptions{MaxBytes: 10},
+			wantErr: "invalid request body: body exceeds 10 bytes",
+		},
+		{
+			name:    "too deep",
+			body:    `{"select": ["id"], "where": [{"field": "age", "operator": "IN", "value": [[[1]]]}]}`,
+			opts:    DecodeOptions{MaxDepth: 4},
+			wantErr: "invalid request body: body is nested deeper than 4 levels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeQueryRequest(strings.NewReader(tt.body), tt.opts)
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+
+			var decodeErr *DecodeError
+			require.ErrorAs(t, err, &decodeErr)
+			assert.Equal(t, tt.wantPath, decodeErr.Path)
+		})
+	}
+}
+
+func TestDecodeRequestInsert(t *testing.T) {
+	req, err := DecodeRequest[InsertRequest](strings.NewReader(`{"values": {"name": "Jane"}}`), DecodeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", req.Values["name"])
+
+	_, err = DecodeRequest[InsertRequest](strings.NewReader(`{"value": {"name": "Jane"}}`), DecodeOptions{})
+	assert.EqualError(t, err, "invalid request body at value: unknown field")
+}
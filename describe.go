@@ -0,0 +1,98 @@
+package sqld
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ModelDescription describes a registered model for clients that build
+// queries against it, such as query-builder UIs.
+type ModelDescription struct {
+	Table           string             `json:"table"`
+	Fields          []FieldDescription `json:"fields"`                      // In struct order
+	PrimaryKey      []string           `json:"primary_key,omitempty"`       // JSON names of the key fields
+	SoftDeleteField string             `json:"soft_delete_field,omitempty"` // JSON name of the soft-delete field
+	Relationships   []Relationship     `json:"relationships,omitempty"`
+}
+
+// FieldDescription describes a field of a registered model.
+type FieldDescription struct {
+	Name      string     `json:"name"`   // JSON name, used in requests
+	Column    string     `json:"column"` // Database column
+	GoType    string     `json:"go_type"`
+	SQLType   string     `json:"sql_type,omitempty"` // PostgreSQL type matching the Go type, if obvious
+	Array     bool       `json:"array,omitempty"`
+	Nullable  bool       `json:"nullable"`
+	Operators []Operator `json:"operators"`      // Operators accepted in conditions on the field
+	Enum      *Enum      `json:"enum,omitempty"` // Enum held by the field, see RegisterEnum
+}
+
+// DescribeModels describes the models registered in the default registry.
+// See Registry.DescribeModels.
+func DescribeModels() []ModelDescription {
+	return defaultRegistry.DescribeModels()
+}
+
+// DescribeModels describes every registered model, sorted by table name, with
+// its fields and the operators they accept. The result is meant to be served
+// as JSON, for instance from a /meta endpoint:
+//
+//	mux.HandleFunc("/meta", func(w http.ResponseWriter, r *http.Request) {
+//	    json.NewEncoder(w).Encode(sqld.DescribeModels())
+//	})
+func (r *Registry) DescribeModels() []ModelDescription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	descriptions := make([]ModelDescription, 0, len(r.models))
+	for t, metadata := range r.models {
+		description := ModelDescription{
+			Table:           metadata.TableName,
+			PrimaryKey:      metadata.PrimaryKey,
+			SoftDeleteField: metadata.SoftDeleteField,
+		}
+		for _, field := range resultSchema(t, metadata, nil) {
+			f := metadata.Fields[field.Name]
+			description.Fields = append(description.Fields, FieldDescription{
+				Name:      field.Name,
+				Column:    field.Column,
+				GoType:    field.GoType,
+				SQLType:   field.SQLType,
+				Array:     f.Array != nil,
+				Nullable:  f.Nullable,
+				Operators: fieldOperators(f),
+				Enum:      f.Enum,
+			})
+		}
+		for _, rel := range r.relations[t] {
+			description.Relationships = append(description.Relationships, rel)
+		}
+		sort.Slice(description.Relationships, func(i, j int) bool {
+			return description.Relationships[i].Name < description.Relationships[j].Name
+		})
+		descriptions = append(descriptions, description)
+	}
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].Table < descriptions[j].Table })
+	return descriptions
+}
+
+// fieldOperators returns the operators BasicValidator accepts in conditions on field.
+func fieldOperators(field Field) []Operator {
+	if field.Array != nil {
+		return []Operator{OpAny, OpContains, OpOverlap, OpIsNull, OpIsNotNull}
+	}
+	if field.JSON {
+		return []Operator{OpEqual, OpNotEqual, OpContains, OpIsNull, OpIsNotNull}
+	}
+	if field.Spatial {
+		return []Operator{OpIsNull, OpIsNotNull}
+	}
+	operators := []Operator{OpEqual, OpNotEqual, OpGreaterThan, OpLessThan, OpGreaterThanOrEqual, OpLessThanOrEqual}
+	if field.NormalizedType.Kind() == reflect.String && field.Enum == nil {
+		operators = append(operators, OpLike, OpILike)
+	}
+	if isNetworkType(field.NormalizedType) {
+		operators = append(operators, OpSubnetOf, OpSupernetOf)
+	}
+	return append(operators, OpIn, OpNotIn, OpIsNull, OpIsNotNull)
+}
@@ -0,0 +1,46 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeModels(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(ArrayTestModel{}))
+	require.NoError(t, registry.RegisterRelationship(RelEmployee{}, RelDepartment{}, Relationship{
+		Name:          "department",
+		LocalColumns:  []string{"department_id"},
+		RemoteColumns: []string{"id"},
+		Cardinality:   BelongsTo,
+	}))
+
+	descriptions := registry.DescribeModels()
+	tables := make([]string, len(descriptions))
+	for i, description := range descriptions {
+		tables[i] = description.Table
+	}
+	assert.Equal(t, []string{"array_test_models", "departments", "employees"}, tables)
+
+	employees := descriptions[2]
+	require.Len(t, employees.Relationships, 1)
+	assert.Equal(t, "departments", employees.Relationships[0].RelatedTable)
+	assert.Equal(t, FieldDescription{
+		Name:      "department_id",
+		Column:    "department_id",
+		GoType:    "int",
+		SQLType:   "bigint",
+		Operators: []Operator{OpEqual, OpNotEqual, OpGreaterThan, OpLessThan, OpGreaterThanOrEqual, OpLessThanOrEqual, OpIn, OpNotIn, OpIsNull, OpIsNotNull},
+	}, employees.Fields[1])
+
+	for _, field := range descriptions[0].Fields {
+		if field.Array {
+			assert.True(t, field.Nullable)
+			assert.Equal(t, []Operator{OpAny, OpContains, OpOverlap, OpIsNull, OpIsNotNull}, field.Operators)
+		} else if field.GoType == "string" {
+			assert.Contains(t, field.Operators, OpILike)
+		}
+	}
+}
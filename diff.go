@@ -0,0 +1,71 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ResultDiff is the row-level difference between two executions of a query.
+// It lets live views send only the rows that changed instead of the full result.
+type ResultDiff struct {
+	Added   []QueryResult `json:"added,omitempty"`   // Rows whose key is new
+	Removed []QueryResult `json:"removed,omitempty"` // Rows whose key disappeared, as they were before
+	Updated []QueryResult `json:"updated,omitempty"` // Rows whose key is unchanged but whose values changed
+}
+
+// Empty reports whether the diff contains no changes.
+func (d ResultDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Updated) == 0
+}
+
+// DiffResults compares the rows of two executions of the same query, matching
+// rows by the value of keyField (typically the primary key, which must be selected).
+// Added and Updated rows follow the order of next; Removed rows follow the order of prev.
+// An error is returned if a row lacks keyField, has a non-comparable key, or if
+// two rows of the same result share a key.
+func DiffResults(prev, next []QueryResult, keyField string) (ResultDiff, error) {
+	prevByKey, err := indexResults(prev, keyField)
+	if err != nil {
+		return ResultDiff{}, fmt.Errorf("previous result: %w", err)
+	}
+	nextByKey, err := indexResults(next, keyField)
+	if err != nil {
+		return ResultDiff{}, fmt.Errorf("next result: %w", err)
+	}
+
+	var diff ResultDiff
+	for _, row := range next {
+		old, ok := prevByKey[row[keyField]]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, row)
+		case !reflect.DeepEqual(old, row):
+			diff.Updated = append(diff.Updated, row)
+		}
+	}
+	for _, row := range prev {
+		if _, ok := nextByKey[row[keyField]]; !ok {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return diff, nil
+}
+
+// indexResults maps the value of keyField to its row.
+func indexResults(rows []QueryResult, keyField string) (map[interface{}]QueryResult, error) {
+	byKey := make(map[interface{}]QueryResult, len(rows))
+	for i, row := range rows {
+		key, ok := row[keyField]
+		if !ok {
+			return nil, fmt.Errorf("row %d has no key field %s", i, keyField)
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("row %d: key field %s has non-comparable type %T", i, keyField, key)
+		}
+		if _, dup := byKey[key]; dup {
+			return nil, fmt.Errorf("row %d: duplicate key %v", i, key)
+		}
+		byKey[key] = row
+	}
+	return byKey, nil
+}
@@ -0,0 +1,53 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffResults(t *testing.T) {
+	prev := []QueryResult{
+		{"id": 1, "name": "Jane", "salary": 100},
+		{"id": 2, "name": "John", "salary": 200},
+		{"id": 3, "name": "Joe", "salary": 300},
+	}
+	next := []QueryResult{
+		{"id": 4, "name": "Jill", "salary": 400},
+		{"id": 1, "name": "Jane", "salary": 100},
+		{"id": 3, "name": "Joe", "salary": 350},
+	}
+
+	diff, err := DiffResults(prev, next, "id")
+	require.NoError(t, err)
+	assert.Equal(t, ResultDiff{
+		Added:   []QueryResult{{"id": 4, "name": "Jill", "salary": 400}},
+		Removed: []QueryResult{{"id": 2, "name": "John", "salary": 200}},
+		Updated: []QueryResult{{"id": 3, "name": "Joe", "salary": 350}},
+	}, diff)
+	assert.False(t, diff.Empty())
+
+	diff, err = DiffResults(next, next, "id")
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+}
+
+func TestDiffResultsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []QueryResult
+		wantErr string
+	}{
+		{"missing key", []QueryResult{{"name": "Jane"}}, "next result: row 0 has no key field id"},
+		{"duplicate key", []QueryResult{{"id": 1}, {"id": 1}}, "next result: row 1: duplicate key 1"},
+		{"non-comparable key", []QueryResult{{"id": []int{1}}}, "next result: row 0: key field id has non-comparable type []int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffResults(nil, tt.rows, "id")
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AutoRegisterDomains queries the current schema for all domains, created
+// with CREATE DOMAIN, then registers them and their array types in pgx's
+// TypeMap with the codec of their base type, so columns typed with a domain
+// scan on fresh connections instead of failing with unknown OID errors.
+//
+// The base types must be known to pgx: built-in types, or enums and
+// composites registered first with AutoRegisterEnums and
+// AutoRegisterComposites. ConfigurePool registers all three.
+func AutoRegisterDomains(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, `
+		SELECT t.typname
+		FROM pg_type t
+		JOIN pg_namespace n ON t.typnamespace = n.oid
+		WHERE t.typtype = 'd'
+		  AND n.nspname = current_schema()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query pg_type for domains: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var typname string
+		if scanErr := rows.Scan(&typname); scanErr != nil {
+			return fmt.Errorf("failed to scan row for domain: %w", scanErr)
+		}
+		names = append(names, typname)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return registerDerivedTypes(ctx, conn, "domain", names)
+}
@@ -0,0 +1,257 @@
+package sqld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MountConfig declares dynamic query endpoints, so a registered model can be
+// exposed over HTTP without writing a handler for it.
+//
+//	{
+//	  "endpoints": [{
+//	    "path": "/api/employees",
+//	    "model": "employees",
+//	    "allowed_fields": ["id", "first_name", "department"],
+//	    "allowed_operators": ["=", "IN"],
+//	    "forced_filters": [{"field": "is_active", "operator": "=", "value": true}],
+//	    "max_page_size": 50,
+//	    "cache_ttl": "30s"
+//	  }]
+//	}
+type MountConfig struct {
+	Endpoints []EndpointConfig `json:"endpoints"`
+}
+
+// EndpointConfig declares a single endpoint accepting QueryRequest bodies by POST.
+type EndpointConfig struct {
+	// Path is the pattern under which the endpoint is mounted on the mux.
+	Path string `json:"path"`
+
+	// Model is the table name of a registered model.
+	Model string `json:"model"`
+
+	// AllowedFields lists the JSON field names that may be selected, filtered
+	// or sorted on. Empty means all fields of the model are allowed.
+	AllowedFields []string `json:"allowed_fields,omitempty"`
+
+	// AllowedOperators lists the operators conditions may use.
+	// Empty means all operators are allowed.
+	AllowedOperators []Operator `json:"allowed_operators,omitempty"`
+
+	// ForcedFilters are appended to every request's conditions.
+	ForcedFilters []Condition `json:"forced_filters,omitempty"`
+
+	// MaxPageSize caps the page size and limit of requests. Requests without
+	// pagination or limit get the first page of DefaultPageSize rows.
+	// Optional - zero leaves requests unpaginated unless they ask otherwise.
+	MaxPageSize int `json:"max_page_size,omitempty"`
+
+	// CacheTTL, such as "30s", is sent to clients as Cache-Control max-age.
+	// Optional - defaults to the CacheTTL of the model, if it is a CacheableModel.
+	CacheTTL string `json:"cache_ttl,omitempty"`
+}
+
+// LoadMountConfig decodes a JSON endpoint configuration, rejecting unknown keys.
+// YAML configurations can be converted to JSON before loading.
+func LoadMountConfig(r io.Reader) (MountConfig, error) {
+	var cfg MountConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return MountConfig{}, fmt.Errorf("failed to decode mount config: %w", err)
+	}
+	return cfg, nil
+}
+
+// MountFromConfig builds a handler for every endpoint of cfg and registers it
+// on mux. The models must be registered beforehand. The whole configuration is
+// checked before anything is mounted.
+//
+// Endpoints respond with the JSON of a QueryResponse. Invalid requests get 400
+// with the violations in "rejected", and requests outside the endpoint's
+// allowed fields or operators get 403.
+func MountFromConfig(mux *http.ServeMux, db interface{}, cfg MountConfig) error {
+	return mountFromConfig(mux, defaultRegistry, db, cfg)
+}
+
+// mountFromConfig implements MountFromConfig with the models of r.
+func mountFromConfig(mux *http.ServeMux, r *Registry, db interface{}, cfg MountConfig) error {
+	handlers := make(map[string]*endpointHandler, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
+		handler, err := newEndpointHandler(r, db, endpoint)
+		if err != nil {
+			return fmt.Errorf("endpoint %d (%s): %w", i, endpoint.Path, err)
+		}
+		if _, exists := handlers[endpoint.Path]; exists {
+			return fmt.Errorf("endpoint %d: duplicate path %s", i, endpoint.Path)
+		}
+		handlers[endpoint.Path] = handler
+	}
+
+	for _, endpoint := range cfg.Endpoints {
+		mux.Handle(endpoint.Path, handlers[endpoint.Path])
+	}
+	return nil
+}
+
+// endpointHandler serves a configured endpoint.
+type endpointHandler struct {
+	registry *Registry
+	db       interface{}
+	config   EndpointConfig
+	metadata ModelMetadata
+	scope    QueryScope
+	cacheTTL time.Duration
+}
+
+// newEndpointHandler checks cfg against the model registered in r and returns its handler.
+func newEndpointHandler(r *Registry, db interface{}, cfg EndpointConfig) (*endpointHandler, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	metadata, err := r.modelByTable(cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range cfg.AllowedFields {
+		if _, ok := metadata.Fields[field]; !ok {
+			return nil, fmt.Errorf("invalid field in allowed fields: %s", field)
+		}
+	}
+	for _, op := range cfg.AllowedOperators {
+		if !isValidOperator(op) {
+			return nil, fmt.Errorf("unsupported operator in allowed operators: %s", op)
+		}
+	}
+	if err := validateWhere(cfg.ForcedFilters, metadata); err != nil {
+		return nil, fmt.Errorf("invalid forced filter: %w", err)
+	}
+	if cfg.MaxPageSize < 0 {
+		return nil, fmt.Errorf("max page size must be non-negative")
+	}
+
+	cacheTTL := metadata.CacheTTL
+	if cfg.CacheTTL != "" {
+		if cacheTTL, err = time.ParseDuration(cfg.CacheTTL); err != nil || cacheTTL < 0 {
+			return nil, fmt.Errorf("invalid cache TTL %q", cfg.CacheTTL)
+		}
+	}
+
+	return &endpointHandler{
+		registry: r,
+		db:       db,
+		config:   cfg,
+		metadata: metadata,
+		scope: QueryScope{
+			Model:         metadata.TableName,
+			AllowedFields: cfg.AllowedFields,
+			ForcedFilters: cfg.ForcedFilters,
+		},
+		cacheTTL: cacheTTL,
+	}, nil
+}
+
+func (h *endpointHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	req, err := DecodeQueryRequest(r.Body, DecodeOptions{})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req, err = h.apply(req)
+	if err != nil {
+		writeJSONError(w, http.StatusForbidden, err)
+		return
+	}
+
+	resp, err := h.registry.execute(r.Context(), h.db, h.metadata, req)
+	if err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if h.cacheTTL > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(h.cacheTTL.Seconds())))
+	}
+	writeJSON(w, resp)
+}
+
+// apply restricts req to the endpoint's fields, operators, forced filters and page size.
+func (h *endpointHandler) apply(req QueryRequest) (QueryRequest, error) {
+	if len(h.config.AllowedOperators) > 0 {
+		for _, cond := range req.Where {
+			if !containsOperator(h.config.AllowedOperators, cond.Operator) {
+				return QueryRequest{}, fmt.Errorf("%w: operator %s is not allowed", ErrScopeViolation, cond.Operator)
+			}
+		}
+	}
+
+	req, err := h.scope.Apply(req, h.metadata)
+	if err != nil {
+		return QueryRequest{}, err
+	}
+
+	if maxSize := h.config.MaxPageSize; maxSize > 0 {
+		switch {
+		case req.Pagination != nil:
+			pagination := *req.Pagination
+			if pagination.PageSize <= 0 {
+				pagination.PageSize = min(DefaultPageSize, maxSize)
+			} else if pagination.PageSize > maxSize {
+				pagination.PageSize = maxSize
+			}
+			req.Pagination = &pagination
+		case req.Limit != nil:
+			limit := min(*req.Limit, maxSize)
+			req.Limit = &limit
+		default:
+			req.Pagination = &PaginationRequest{Page: 1, PageSize: min(DefaultPageSize, maxSize)}
+		}
+	}
+	return req, nil
+}
+
+// containsOperator checks if op is present in ops.
+func containsOperator(ops []Operator, op Operator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONError writes err as a JSON error response. Validation errors
+// include their violations under "rejected".
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	body := struct {
+		Error    string      `json:"error"`
+		Rejected []Violation `json:"rejected,omitempty"`
+	}{Error: err.Error()}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		body.Rejected = verr.Rejected
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
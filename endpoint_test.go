@@ -0,0 +1,210 @@
+package sqld
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EndpointTestModel struct {
+	ID         int    `json:"id" db:"id"`
+	Name       string `json:"name" db:"name"`
+	Department string `json:"department" db:"department"`
+	TenantID   int    `json:"tenant_id" db:"tenant_id"`
+}
+
+func (EndpointTestModel) TableName() string {
+	return "endpoint_test_models"
+}
+
+func mountTestEndpoint(t *testing.T, db interface{}) *http.ServeMux {
+	t.Helper()
+	require.NoError(t, Register[EndpointTestModel]())
+
+	cfg, err := LoadMountConfig(strings.NewReader(`{
+		"endpoints": [{
+			"path": "/employees",
+			"model": "endpoint_test_models",
+			"allowed_fields": ["id", "name", "department"],
+			"allowed_operators": ["=", "IN"],
+			"forced_filters": [{"field": "tenant_id", "operator": "=", "value": 7}],
+			"max_page_size": 5,
+			"cache_ttl": "30s"
+		}]
+	}`))
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	require.NoError(t, MountFromConfig(mux, db, cfg))
+	return mux
+}
+
+func TestMountFromConfig(t *testing.T) {
+	db, fake := newFakeDB(t, nil)
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+	fake.queue([]string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	mux := mountTestEndpoint(t, db)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/employees", strings.NewReader(`{
+		"select": ["id", "name"],
+		"where": [{"field": "department", "operator": "=", "value": "Sales"}],
+		"pagination": {"page": 1, "page_size": 50}
+	}`)))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "max-age=30", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{
+		"SELECT COUNT(*) FROM endpoint_test_models WHERE department = $1 AND tenant_id = $2",
+		"SELECT id, name FROM endpoint_test_models WHERE department = $1 AND tenant_id = $2 LIMIT $3 OFFSET $4",
+	}, fake.statements)
+
+	var resp struct {
+		Data       []map[string]interface{} `json:"data"`
+		Pagination *PaginationResponse      `json:"pagination"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, []map[string]interface{}{{"id": float64(1), "name": "Jane"}}, resp.Data)
+	assert.Equal(t, 5, resp.Pagination.PageSize)
+}
+
+func TestMountFromConfigRejections(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  "method not allowed",
+		},
+		{
+			name:       "unknown key",
+			body:       `{"select": ["id"], "pagesize": 5}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid request body",
+		},
+		{
+			name:       "field not allowed",
+			body:       `{"select": ["tenant_id"]}`,
+			wantStatus: http.StatusForbidden,
+			wantError:  "field tenant_id cannot be selected",
+		},
+		{
+			name:       "operator not allowed",
+			body:       `{"select": ["id"], "where": [{"field": "name", "operator": "LIKE", "value": "J%"}]}`,
+			wantStatus: http.StatusForbidden,
+			wantError:  "operator LIKE is not allowed",
+		},
+		{
+			name:       "invalid query",
+			body:       `{"select": ["id"], "where": [{"field": "id", "operator": "IN", "value": 1}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "failed to validate query",
+		},
+	}
+
+	db, _ := newFakeDB(t, nil)
+	mux := mountTestEndpoint(t, db)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(method, "/employees", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var body struct {
+				Error string `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Contains(t, body.Error, tt.wantError)
+		})
+	}
+}
+
+func TestMountFromConfigInvalid(t *testing.T) {
+	require.NoError(t, Register[EndpointTestModel]())
+
+	tests := []struct {
+		name     string
+		endpoint EndpointConfig
+		wantErr  string
+	}{
+		{
+			name:     "unknown model",
+			endpoint: EndpointConfig{Path: "/x", Model: "missing"},
+			wantErr:  "no registered model has table missing",
+		},
+		{
+			name:     "unknown allowed field",
+			endpoint: EndpointConfig{Path: "/x", Model: "endpoint_test_models", AllowedFields: []string{"salary"}},
+			wantErr:  "invalid field in allowed fields: salary",
+		},
+		{
+			name:     "unknown operator",
+			endpoint: EndpointConfig{Path: "/x", Model: "endpoint_test_models", AllowedOperators: []Operator{"~"}},
+			wantErr:  "unsupported operator in allowed operators: ~",
+		},
+		{
+			name:     "invalid cache TTL",
+			endpoint: EndpointConfig{Path: "/x", Model: "endpoint_test_models", CacheTTL: "soon"},
+			wantErr:  `invalid cache TTL "soon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MountFromConfig(http.NewServeMux(), nil, MountConfig{Endpoints: []EndpointConfig{tt.endpoint}})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	err := MountFromConfig(http.NewServeMux(), nil, MountConfig{Endpoints: []EndpointConfig{
+		{Path: "/x", Model: "endpoint_test_models"},
+		{Path: "/x", Model: "endpoint_test_models"},
+	}})
+	assert.EqualError(t, err, "endpoint 1: duplicate path /x")
+}
+
+func TestEndpointPageSize(t *testing.T) {
+	require.NoError(t, Register[EndpointTestModel]())
+
+	tests := []struct {
+		name       string
+		maxSize    int
+		pagination *PaginationRequest
+		want       int
+	}{
+		{name: "within max", maxSize: 50, pagination: &PaginationRequest{Page: 2, PageSize: 20}, want: 20},
+		{name: "above max", maxSize: 50, pagination: &PaginationRequest{Page: 2, PageSize: 60}, want: 50},
+		{name: "unset", maxSize: 50, pagination: &PaginationRequest{Page: 2}, want: 10},
+		{name: "unset with small max", maxSize: 5, pagination: &PaginationRequest{Page: 2}, want: 5},
+		{name: "no pagination", maxSize: 50, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := newEndpointHandler(defaultRegistry, nil, EndpointConfig{Path: "/x", Model: "endpoint_test_models", MaxPageSize: tt.maxSize})
+			require.NoError(t, err)
+
+			req, err := h.apply(QueryRequest{Select: []string{"id"}, Pagination: tt.pagination})
+			require.NoError(t, err)
+			require.NotNil(t, req.Pagination)
+			assert.Equal(t, tt.want, req.Pagination.PageSize)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Enum describes a PostgreSQL enum held by a Go string type.
+type Enum struct {
+	Type   string   `json:"type"`   // Name of the enum type in the current schema
+	Labels []string `json:"labels"` // Allowed labels
+}
+
+// hasLabel reports whether label is one of the labels of e.
+func (e *Enum) hasLabel(label string) bool {
+	for _, l := range e.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterEnum registers the Go string type t in the default registry as
+// holding the PostgreSQL enum pgType. See Registry.RegisterEnum.
+func RegisterEnum(t reflect.Type, pgType string, labels ...string) error {
+	return defaultRegistry.RegisterEnum(t, pgType, labels...)
+}
+
+// RegisterEnum declares that fields of the Go string type t, and arrays of it,
+// hold the PostgreSQL enum pgType with the given labels:
+//
+//	type Status string
+//
+//	const (
+//	    StatusActive   Status = "active"
+//	    StatusInactive Status = "inactive"
+//	)
+//
+//	err := sqld.RegisterEnum(reflect.TypeOf(Status("")), "employee_status",
+//	    string(StatusActive), string(StatusInactive))
+//
+// Conditions and inserts on such fields of models registered afterwards are
+// rejected unless their values are labels, results holding other labels fail
+// instead of reaching clients, and VerifyEnums compares the labels with the
+// database. Once the enum catalog holds pgType, its labels are used instead;
+// see LoadEnumLabels.
+func (r *Registry) RegisterEnum(t reflect.Type, pgType string, labels ...string) error {
+	if t.Kind() != reflect.String {
+		return fmt.Errorf("enum type %s is not a string type", t)
+	}
+	if pgType == "" {
+		return fmt.Errorf("enum type %s has no PostgreSQL type", t)
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("enum type %s has no labels", t)
+	}
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		if seen[label] {
+			return fmt.Errorf("enum type %s has duplicate label %q", t, label)
+		}
+		seen[label] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enums[t] = Enum{Type: pgType, Labels: append([]string(nil), labels...)}
+	return nil
+}
+
+// applyEnumsLocked sets Field.Enum for the fields of metadata whose type, or
+// element type, is a registered enum, with the labels of the enum catalog if
+// it has them. The caller must hold r.mu.
+func (r *Registry) applyEnumsLocked(metadata ModelMetadata) {
+	if len(r.enums) == 0 {
+		return
+	}
+	for name, field := range metadata.Fields {
+		t := field.Type
+		if field.Array != nil {
+			t, _ = arrayElemType(t)
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if enum, ok := r.enums[t]; ok {
+			if labels, ok := r.enumCatalog[enum.Type]; ok {
+				enum.Labels = labels
+			}
+			field.Enum = &enum
+			metadata.Fields[name] = field
+		}
+	}
+}
+
+// LoadEnumLabels reads the labels of the enums of the current schema into the
+// enum catalog of the default registry. See Registry.LoadEnumLabels.
+func LoadEnumLabels(ctx context.Context, db interface{}) error {
+	return defaultRegistry.LoadEnumLabels(ctx, db)
+}
+
+// LoadEnumLabels reads the labels of the enum types of the current schema into
+// the enum catalog of r, replacing what it held. Registered enums then take
+// their labels from the catalog: conditions, inserts and results are checked
+// against the labels the database accepts, and DescribeModels lists them.
+// AutoRegisterEnums fills the catalog too, so pools set up with ConfigurePool
+// keep it current without calling LoadEnumLabels.
+func (r *Registry) LoadEnumLabels(ctx context.Context, db interface{}) error {
+	var rows []enumLabel
+	if err := selectAll(ctx, db, &rows, enumLabelsQuery); err != nil {
+		return wrapDBError("failed to get enum labels", err)
+	}
+	catalog := make(map[string][]string)
+	for _, row := range rows {
+		catalog[row.Type] = append(catalog[row.Type], row.Label)
+	}
+	r.setEnumCatalog(catalog)
+	return nil
+}
+
+// EnumLabels returns the labels of the database enum pgType held by the enum
+// catalog of the default registry. See Registry.EnumLabels.
+func EnumLabels(pgType string) ([]string, bool) {
+	return defaultRegistry.EnumLabels(pgType)
+}
+
+// EnumLabels returns the labels of the database enum pgType, in declaration
+// order, as last loaded by LoadEnumLabels or AutoRegisterEnums. It reports
+// false if the catalog does not have the type.
+func (r *Registry) EnumLabels(pgType string) ([]string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	labels, ok := r.enumCatalog[pgType]
+	return append([]string(nil), labels...), ok
+}
+
+// setEnumCatalog replaces the enum catalog of r and updates the enum fields of
+// the registered models with its labels.
+func (r *Registry) setEnumCatalog(catalog map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enumCatalog = catalog
+	for t, metadata := range r.models {
+		changed := false
+		// Copy the fields, so metadata already handed out is left unchanged
+		fields := make(map[string]Field, len(metadata.Fields))
+		for name, field := range metadata.Fields {
+			if field.Enum != nil {
+				if labels, ok := catalog[field.Enum.Type]; ok {
+					field.Enum = &Enum{Type: field.Enum.Type, Labels: labels}
+					changed = true
+				}
+			}
+			fields[name] = field
+		}
+		if changed {
+			metadata.Fields = fields
+			r.models[t] = metadata
+		}
+	}
+}
+
+// enumLabels returns the strings held by v: v itself if it is a string, or the
+// string elements of a slice. Values of other types are type-checked elsewhere.
+func enumLabels(v interface{}) []string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return []string{rv.String()}
+	case reflect.Slice, reflect.Array:
+		var labels []string
+		for i := 0; i < rv.Len(); i++ {
+			labels = append(labels, enumLabels(rv.Index(i).Interface())...)
+		}
+		return labels
+	}
+	return nil
+}
+
+// unknownEnumLabel returns the first string in v that is not a label of enum.
+func unknownEnumLabel(enum *Enum, v interface{}) (string, bool) {
+	for _, label := range enumLabels(v) {
+		if !enum.hasLabel(label) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// enumConditionViolation checks a condition on the enum field field. Enums
+// cannot be matched with LIKE, and values must be labels of the enum.
+func enumConditionViolation(path string, cond Condition, field Field) *Violation {
+	switch cond.Operator {
+	case OpLike, OpILike:
+		v := newViolation(CodeEnumField, path+".operator", "operator %s cannot be used on enum field %s",
+			cond.Operator, cond.Field)
+		return &v
+	}
+	if label, ok := unknownEnumLabel(field.Enum, cond.Value); ok {
+		v := newViolation(CodeUnknownLabel, path+".value", "%q is not a label of enum %s of field %s",
+			label, field.Enum.Type, cond.Field)
+		return &v
+	}
+	return nil
+}
+
+// EnumMismatch is a difference between a registered enum and its type in the database.
+type EnumMismatch struct {
+	Type    string `json:"type"`            // Name of the PostgreSQL enum type
+	Label   string `json:"label,omitempty"` // Label missing on one side, empty if the type itself is wrong
+	Message string `json:"message"`
+}
+
+// EnumMismatchError is returned by VerifyEnums when registered enums do not
+// match the database. Mismatches lists every difference found.
+type EnumMismatchError struct {
+	Mismatches []EnumMismatch `json:"mismatches"`
+}
+
+func (e *EnumMismatchError) Error() string {
+	if len(e.Mismatches) == 1 {
+		return "registered enums do not match the database: " + e.Mismatches[0].Message
+	}
+	return fmt.Sprintf("registered enums do not match the database: %s (and %d more)",
+		e.Mismatches[0].Message, len(e.Mismatches)-1)
+}
+
+// VerifyEnums checks the enums registered in the default registry against the
+// database. See Registry.VerifyEnums.
+func VerifyEnums(ctx context.Context, db interface{}) error {
+	return defaultRegistry.VerifyEnums(ctx, db)
+}
+
+// VerifyEnums compares every enum registered with RegisterEnum with the enum
+// types of the current schema, the ones AutoRegisterEnums registers with pgx,
+// and returns an *EnumMismatchError listing types that do not exist and labels
+// declared on only one side. Like VerifyModels, it is meant to run at startup.
+func (r *Registry) VerifyEnums(ctx context.Context, db interface{}) error {
+	r.mu.RLock()
+	enums := make([]Enum, 0, len(r.enums))
+	for _, enum := range r.enums {
+		enums = append(enums, enum)
+	}
+	r.mu.RUnlock()
+	if len(enums) == 0 {
+		return nil
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Type < enums[j].Type })
+
+	var rows []enumLabel
+	if err := selectAll(ctx, db, &rows, enumLabelsQuery); err != nil {
+		return wrapDBError("failed to get enum labels", err)
+	}
+	dbLabels := make(map[string][]string)
+	for _, row := range rows {
+		dbLabels[row.Type] = append(dbLabels[row.Type], row.Label)
+	}
+
+	var mismatches []EnumMismatch
+	for _, enum := range enums {
+		labels, ok := dbLabels[enum.Type]
+		if !ok {
+			mismatches = append(mismatches, EnumMismatch{
+				Type:    enum.Type,
+				Message: fmt.Sprintf("enum type %s does not exist", enum.Type),
+			})
+			continue
+		}
+		for _, label := range enum.Labels {
+			if !contains(labels, label) {
+				mismatches = append(mismatches, EnumMismatch{
+					Type:    enum.Type,
+					Label:   label,
+					Message: fmt.Sprintf("enum type %s has no label %q", enum.Type, label),
+				})
+			}
+		}
+		for _, label := range labels {
+			if !enum.hasLabel(label) {
+				mismatches = append(mismatches, EnumMismatch{
+					Type:    enum.Type,
+					Label:   label,
+					Message: fmt.Sprintf("label %q of enum type %s is not registered", label, enum.Type),
+				})
+			}
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return &EnumMismatchError{Mismatches: mismatches}
+}
+
+// enumLabel is a label of an enum type, as listed by pg_enum.
+type enumLabel struct {
+	Type  string `db:"typname"`
+	Label string `db:"enumlabel"`
+}
+
+// enumLabelsQuery lists the labels of the enum types in the current schema, in
+// declaration order.
+const enumLabelsQuery = `SELECT t.typname, e.enumlabel
+FROM pg_type t
+JOIN pg_namespace n ON t.typnamespace = n.oid
+JOIN pg_enum e ON e.enumtypid = t.oid
+WHERE n.nspname = current_schema()
+ORDER BY t.typname, e.enumsortorder`
+
+// enumColumnMatches reports whether column holds enum, or an array of it.
+func enumColumnMatches(enum *Enum, column tableColumn) bool {
+	return strings.TrimPrefix(column.UDTName, "_") == enum.Type
+}
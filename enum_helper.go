@@ -10,6 +10,7 @@ import (
 
 // AutoRegisterEnums queries the current schema for all user-defined enums,
 // then registers them in pgx's TypeMap as text so we avoid unknown OID errors.
+// Their labels are stored in the enum catalog of the default registry.
 //
 // Note on Schema Behavior:
 // - current_schema() returns the first schema in the search_path that exists
@@ -26,22 +27,33 @@ import (
 //   ALTER DATABASE dbname SET search_path TO custom_schema, public;
 //   ALTER USER username SET search_path TO custom_schema, public;
 func AutoRegisterEnums(ctx context.Context, conn *pgx.Conn) error {
+	return defaultRegistry.AutoRegisterEnums(ctx, conn)
+}
+
+// AutoRegisterEnums registers the enums of the current schema in the TypeMap
+// of conn, like the package-level AutoRegisterEnums, and stores their labels in
+// the enum catalog of r; see Registry.LoadEnumLabels.
+func (r *Registry) AutoRegisterEnums(ctx context.Context, conn *pgx.Conn) error {
 	rows, err := conn.Query(ctx, `
-		SELECT t.oid, t.typname
+		SELECT t.oid, t.typname, array_agg(e.enumlabel ORDER BY e.enumsortorder)
 		FROM pg_type t
 		JOIN pg_namespace n ON t.typnamespace = n.oid
+		JOIN pg_enum e ON e.enumtypid = t.oid
 		WHERE t.typtype = 'e'
 		  AND n.nspname = current_schema()
+		GROUP BY t.oid, t.typname
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to query pg_type for enums: %w", err)
 	}
 	defer rows.Close()
 
+	catalog := make(map[string][]string)
 	for rows.Next() {
 		var oid uint32
 		var typname string
-		if scanErr := rows.Scan(&oid, &typname); scanErr != nil {
+		var labels []string
+		if scanErr := rows.Scan(&oid, &typname, &labels); scanErr != nil {
 			return fmt.Errorf("failed to scan row for enum: %w", scanErr)
 		}
 
@@ -51,10 +63,12 @@ func AutoRegisterEnums(ctx context.Context, conn *pgx.Conn) error {
 			OID:   oid,
 			Codec: pgtype.TextCodec{},
 		})
+		catalog[typname] = labels
 	}
 	if err := rows.Err(); err != nil {
 		return fmt.Errorf("rows iteration error: %w", err)
 	}
 
+	r.setEnumCatalog(catalog)
 	return nil
 }
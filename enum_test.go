@@ -0,0 +1,168 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type EnumTestStatus string
+
+type EnumTestEmployee struct {
+	ID     int64            `json:"id" db:"id"`
+	Status EnumTestStatus   `json:"status" db:"status"`
+	Past   []EnumTestStatus `json:"past" db:"past"`
+}
+
+func (EnumTestEmployee) TableName() string {
+	return "enum_test_employees"
+}
+
+func newEnumTestRegistry(t *testing.T) (*Registry, ModelMetadata) {
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterEnum(reflect.TypeOf(EnumTestStatus("")), "employee_status", "active", "inactive"))
+	require.NoError(t, registry.Register(EnumTestEmployee{}))
+	metadata, err := registry.GetModelMetadata(EnumTestEmployee{})
+	require.NoError(t, err)
+	return registry, metadata
+}
+
+func TestRegisterEnum(t *testing.T) {
+	registry := NewRegistry()
+	assert.EqualError(t, registry.RegisterEnum(reflect.TypeOf(0), "num", "1"), "enum type int is not a string type")
+	assert.EqualError(t, registry.RegisterEnum(reflect.TypeOf(EnumTestStatus("")), "employee_status"),
+		"enum type sqld.EnumTestStatus has no labels")
+	assert.EqualError(t, registry.RegisterEnum(reflect.TypeOf(EnumTestStatus("")), "employee_status", "a", "a"),
+		`enum type sqld.EnumTestStatus has duplicate label "a"`)
+
+	_, metadata := newEnumTestRegistry(t)
+	want := &Enum{Type: "employee_status", Labels: []string{"active", "inactive"}}
+	assert.Equal(t, want, metadata.Fields["status"].Enum)
+	assert.Equal(t, want, metadata.Fields["past"].Enum)
+	assert.Nil(t, metadata.Fields["id"].Enum)
+	assert.NotContains(t, fieldOperators(metadata.Fields["status"]), OpLike)
+}
+
+func TestEnumConditions(t *testing.T) {
+	_, metadata := newEnumTestRegistry(t)
+
+	valid := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "status", Operator: OpEqual, Value: "active"},
+			{Field: "status", Operator: OpIn, Value: []interface{}{"active", EnumTestStatus("inactive")}},
+			{Field: "past", Operator: OpAny, Value: "inactive"},
+			{Field: "status", Operator: OpIsNull},
+		},
+	}
+	assert.NoError(t, BasicValidator{}.ValidateQuery(valid, metadata))
+
+	tests := []struct {
+		name string
+		cond Condition
+		want Violation
+	}{
+		{
+			name: "unknown label",
+			cond: Condition{Field: "status", Operator: OpEqual, Value: "retired"},
+			want: Violation{Code: CodeUnknownLabel, Path: "where[0].value", Message: `"retired" is not a label of enum employee_status of field status`},
+		},
+		{
+			name: "unknown label in list",
+			cond: Condition{Field: "past", Operator: OpContains, Value: []string{"active", "fired"}},
+			want: Violation{Code: CodeUnknownLabel, Path: "where[0].value", Message: `"fired" is not a label of enum employee_status of field past`},
+		},
+		{
+			name: "like",
+			cond: Condition{Field: "status", Operator: OpLike, Value: "act%"},
+			want: Violation{Code: CodeEnumField, Path: "where[0].operator", Message: "operator LIKE cannot be used on enum field status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"id"}, Where: []Condition{tt.cond}}, metadata)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, []Violation{tt.want}, verr.Rejected)
+		})
+	}
+
+	err := BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"status": "retired"}}, metadata)
+	assert.EqualError(t, err, `invalid value for field status: "retired" is not a label of enum employee_status`)
+}
+
+func TestEnumResults(t *testing.T) {
+	registry, metadata := newEnumTestRegistry(t)
+
+	result := map[string]interface{}{"status": "active", "past": []interface{}{"inactive"}}
+	require.NoError(t, registry.scanResult(metadata, result))
+
+	result = map[string]interface{}{"status": "on_leave"}
+	assert.EqualError(t, registry.scanResult(metadata, result),
+		`field status has label "on_leave", which is not registered for enum employee_status`)
+}
+
+func TestVerifyEnums(t *testing.T) {
+	registry, _ := newEnumTestRegistry(t)
+	require.NoError(t, registry.RegisterEnum(reflect.TypeOf(""), "missing_type", "x"))
+
+	db, _ := newFakeDB(t, []string{"typname", "enumlabel"},
+		[]driver.Value{"employee_status", "active"},
+		[]driver.Value{"employee_status", "on_leave"},
+	)
+	err := registry.VerifyEnums(context.Background(), db)
+	var merr *EnumMismatchError
+	require.ErrorAs(t, err, &merr)
+	assert.Equal(t, []EnumMismatch{
+		{Type: "employee_status", Label: "inactive", Message: `enum type employee_status has no label "inactive"`},
+		{Type: "employee_status", Label: "on_leave", Message: `label "on_leave" of enum type employee_status is not registered`},
+		{Type: "missing_type", Message: "enum type missing_type does not exist"},
+	}, merr.Mismatches)
+
+	assert.NoError(t, NewRegistry().VerifyEnums(context.Background(), db))
+}
+
+func TestVerifyModelsChecksEnumColumns(t *testing.T) {
+	_, metadata := newEnumTestRegistry(t)
+	mismatches := modelMismatches(metadata, map[string]tableColumn{
+		"id":     {Name: "id", DataType: "bigint", UDTName: "int8"},
+		"status": {Name: "status", DataType: "USER-DEFINED", UDTName: "job_status"},
+		"past":   {Name: "past", DataType: "ARRAY", UDTName: "_employee_status"},
+	})
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "column enum_test_employees.status has type job_status, but field status holds enum employee_status",
+		mismatches[0].Message)
+}
+
+func TestLoadEnumLabels(t *testing.T) {
+	registry, before := newEnumTestRegistry(t)
+
+	db, _ := newFakeDB(t, []string{"typname", "enumlabel"},
+		[]driver.Value{"employee_status", "active"},
+		[]driver.Value{"employee_status", "inactive"},
+		[]driver.Value{"employee_status", "on_leave"},
+		[]driver.Value{"pay_grade", "junior"},
+	)
+	require.NoError(t, registry.LoadEnumLabels(context.Background(), db))
+
+	labels, ok := registry.EnumLabels("employee_status")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"active", "inactive", "on_leave"}, labels)
+	_, ok = registry.EnumLabels("missing_type")
+	assert.False(t, ok)
+
+	// Registered models use the labels of the database, metadata handed out
+	// earlier is left unchanged
+	after, err := registry.GetModelMetadata(EnumTestEmployee{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active", "inactive", "on_leave"}, after.Fields["status"].Enum.Labels)
+	assert.Equal(t, []string{"active", "inactive"}, before.Fields["status"].Enum.Labels)
+
+	cond := Condition{Field: "status", Operator: OpEqual, Value: "on_leave"}
+	assert.NoError(t, BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"id"}, Where: []Condition{cond}}, after))
+	assert.NoError(t, registry.scanResult(after, map[string]interface{}{"status": "on_leave"}))
+}
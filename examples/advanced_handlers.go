@@ -3,11 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"net/http"
 	"strings"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/remiges-tech/sqld"
 	"github.com/remiges-tech/sqld/examples/db/sqlc-gen"
 )
@@ -45,14 +43,8 @@ type PaginatedDynamicQueryParams struct {
 		MinSalary  *float64 `json:"min_salary,omitempty"`
 		MaxSalary  *float64 `json:"max_salary,omitempty"`
 	} `json:"filters"`
-	Pagination struct {
-		Limit  *int `json:"limit,omitempty"`  // Number of records to return
-		Offset *int `json:"offset,omitempty"` // Number of records to skip
-	} `json:"pagination"`
-	OrderBy []struct {
-		Field string `json:"field"` // Field to order by
-		Desc  bool   `json:"desc"`  // If true, order descending
-	} `json:"order_by,omitempty"`
+	Pagination *sqld.PaginationRequest `json:"pagination,omitempty"`
+	OrderBy    []sqld.OrderByClause     `json:"order_by,omitempty"` // Fields to order by, appended by sqld
 }
 
 func (PaginatedDynamicQueryParams) TableName() string {
@@ -126,13 +118,6 @@ func (s *Server) AdvancedSQLCHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert to SQLC types
-	params := sqlc.GetEmployeesAdvancedParams{
-		Department: pgtype.Text{String: requestParams.Department, Valid: true},
-		MinSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MinSalary)), Valid: true},
-		MaxSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MaxSalary)), Valid: true},
-	}
-
 	query := `
 		SELECT id, first_name, last_name, department, salary
 		FROM employees
@@ -142,10 +127,11 @@ func (s *Server) AdvancedSQLCHandler(w http.ResponseWriter, r *http.Request) {
 		ORDER BY salary DESC
 	`
 
+	// Plain values are converted to the pgtype fields of the SQLC params struct
 	paramMap := map[string]interface{}{
-		"department": params.Department,
-		"min_salary": params.MinSalary,
-		"max_salary": params.MaxSalary,
+		"department": requestParams.Department,
+		"min_salary": requestParams.MinSalary,
+		"max_salary": requestParams.MaxSalary,
 	}
 
 	req := sqld.ExecuteRawRequest{
@@ -188,13 +174,6 @@ func (s *Server) AdvancedSQLCHandlerSimple(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Convert to SQLC types
-	params := sqlc.GetEmployeesAdvancedParams{
-		Department: pgtype.Text{String: requestParams.Department, Valid: true},
-		MinSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MinSalary)), Valid: true},
-		MaxSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MaxSalary)), Valid: true},
-	}
-
 	query := `
 		SELECT first_name, department 
 		FROM employees
@@ -204,10 +183,11 @@ func (s *Server) AdvancedSQLCHandlerSimple(w http.ResponseWriter, r *http.Reques
 		ORDER BY salary DESC
 	`
 
+	// Plain values are converted to the pgtype fields of the SQLC params struct
 	paramMap := map[string]interface{}{
-		"department": params.Department,
-		"min_salary": params.MinSalary,
-		"max_salary": params.MaxSalary,
+		"department": requestParams.Department,
+		"min_salary": requestParams.MinSalary,
+		"max_salary": requestParams.MaxSalary,
 	}
 
 	req := sqld.ExecuteRawRequest{
@@ -250,13 +230,6 @@ func (s *Server) AdvancedSQLCHandlerJoins(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Convert to SQLC types
-	params := sqlc.GetEmployeesWithAccountsParams{
-		Department: pgtype.Text{String: requestParams.Department, Valid: true},
-		MinSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MinSalary)), Valid: true},
-		MaxSalary:  pgtype.Numeric{Int: big.NewInt(int64(requestParams.MaxSalary)), Valid: true},
-	}
-
 	query := `
 		SELECT 
 			e.first_name as employee_name,
@@ -272,10 +245,11 @@ func (s *Server) AdvancedSQLCHandlerJoins(w http.ResponseWriter, r *http.Request
 		ORDER BY total_balance DESC
 	`
 
+	// Plain values are converted to the pgtype fields of the SQLC params struct
 	paramMap := map[string]interface{}{
-		"department": params.Department,
-		"min_salary": params.MinSalary,
-		"max_salary": params.MaxSalary,
+		"department": requestParams.Department,
+		"min_salary": requestParams.MinSalary,
+		"max_salary": requestParams.MaxSalary,
 	}
 
 	req := sqld.ExecuteRawRequest{
@@ -346,27 +320,18 @@ func (s *Server) AdvancedSQLCHandlerDynamic(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	// Build WHERE clause and params
-	var whereConditions []string
+	// Build params; the optional [[ ... ]] filter blocks in the query are
+	// dropped by sqld when their parameter is absent
 	paramMap := make(map[string]interface{})
 
 	if requestParams.Filters.Department != nil {
-		whereConditions = append(whereConditions, "e.department = {{department}}")
-		paramMap["department"] = pgtype.Text{String: *requestParams.Filters.Department, Valid: true}
+		paramMap["department"] = *requestParams.Filters.Department
 	}
 	if requestParams.Filters.MinSalary != nil {
-		whereConditions = append(whereConditions, "e.salary >= {{min_salary}}")
-		paramMap["min_salary"] = pgtype.Numeric{Int: big.NewInt(int64(*requestParams.Filters.MinSalary)), Valid: true}
+		paramMap["min_salary"] = *requestParams.Filters.MinSalary
 	}
 	if requestParams.Filters.MaxSalary != nil {
-		whereConditions = append(whereConditions, "e.salary <= {{max_salary}}")
-		paramMap["max_salary"] = pgtype.Numeric{Int: big.NewInt(int64(*requestParams.Filters.MaxSalary)), Valid: true}
-	}
-
-	// Build the complete query
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+		paramMap["max_salary"] = *requestParams.Filters.MaxSalary
 	}
 
 	// Only include ORDER BY total_balance if it's in the selected fields
@@ -378,16 +343,19 @@ func (s *Server) AdvancedSQLCHandlerDynamic(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	// Build the complete query
 	query := fmt.Sprintf(`
 		SELECT 
 			%s
 		FROM employees e
 		LEFT JOIN accounts a ON a.owner_id = e.id
-		%s
+		WHERE TRUE
+		[[ AND e.department = {{department}} ]]
+		[[ AND e.salary >= {{min_salary}} ]]
+		[[ AND e.salary <= {{max_salary}} ]]
 		GROUP BY e.first_name, e.department
 		%s
 	`, strings.Join(selectFields, ", "),
-		whereClause,
 		orderByClause)
 
 	req := sqld.ExecuteRawRequest{
@@ -458,95 +426,60 @@ func (s *Server) AdvancedSQLCHandlerDynamicPaginated(w http.ResponseWriter, r *h
 		}
 	}
 
-	// Build WHERE clause and params
-	var whereConditions []string
+	// Build params; the optional [[ ... ]] filter blocks in the query are
+	// dropped by sqld when their parameter is absent
 	paramMap := make(map[string]interface{})
 
 	if requestParams.Filters.Department != nil {
-		whereConditions = append(whereConditions, "e.department = {{department}}")
-		paramMap["department"] = pgtype.Text{String: *requestParams.Filters.Department, Valid: true}
+		paramMap["department"] = *requestParams.Filters.Department
 	}
 	if requestParams.Filters.MinSalary != nil {
-		whereConditions = append(whereConditions, "e.salary >= {{min_salary}}")
-		paramMap["min_salary"] = pgtype.Numeric{Int: big.NewInt(int64(*requestParams.Filters.MinSalary)), Valid: true}
+		paramMap["min_salary"] = *requestParams.Filters.MinSalary
 	}
 	if requestParams.Filters.MaxSalary != nil {
-		whereConditions = append(whereConditions, "e.salary <= {{max_salary}}")
-		paramMap["max_salary"] = pgtype.Numeric{Int: big.NewInt(int64(*requestParams.Filters.MaxSalary)), Valid: true}
-	}
-
-	// Build WHERE clause
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
-	}
-
-	// Build ORDER BY clause
-	var orderClauses []string
-	if len(requestParams.OrderBy) > 0 {
-		for _, order := range requestParams.OrderBy {
-			if !validFields[order.Field] {
-				http.Error(w, fmt.Sprintf("Invalid order field: %s", order.Field), http.StatusBadRequest)
-				return
-			}
-			direction := "ASC"
-			if order.Desc {
-				direction = "DESC"
-			}
-			orderClauses = append(orderClauses, fmt.Sprintf("%s %s", order.Field, direction))
-		}
-	}
-	orderByClause := ""
-	if len(orderClauses) > 0 {
-		orderByClause = "ORDER BY " + strings.Join(orderClauses, ", ")
+		paramMap["max_salary"] = *requestParams.Filters.MaxSalary
 	}
 
-	// Build LIMIT and OFFSET
-	limitOffsetClause := ""
-	if requestParams.Pagination.Limit != nil {
-		limitOffsetClause = fmt.Sprintf("LIMIT %d", *requestParams.Pagination.Limit)
-		if requestParams.Pagination.Offset != nil {
-			limitOffsetClause += fmt.Sprintf(" OFFSET %d", *requestParams.Pagination.Offset)
+	// Only selected fields can be ordered by; sqld validates them against the
+	// result model and appends the ORDER BY clause itself
+	for _, order := range requestParams.OrderBy {
+		if !validFields[order.Field] {
+			http.Error(w, fmt.Sprintf("Invalid order field: %s", order.Field), http.StatusBadRequest)
+			return
 		}
 	}
 
-	// Build the complete query
+	// Build the complete query; ExecuteRawPaginated adds ORDER BY, LIMIT and OFFSET
 	query := fmt.Sprintf(`
 		SELECT 
 			%s
 		FROM employees e
 		LEFT JOIN accounts a ON a.owner_id = e.id
-		%s
+		WHERE TRUE
+		[[ AND e.department = {{department}} ]]
+		[[ AND e.salary >= {{min_salary}} ]]
+		[[ AND e.salary <= {{max_salary}} ]]
 		GROUP BY e.first_name, e.department
-		%s
-		%s
-	`, strings.Join(selectFields, ", "),
-		whereClause,
-		orderByClause,
-		limitOffsetClause)
+	`, strings.Join(selectFields, ", "))
 
 	req := sqld.ExecuteRawRequest{
 		Query:        query,
 		Params:       paramMap,
 		SelectFields: requestParams.Fields,
+		OrderBy:      requestParams.OrderBy,
 	}
 
-	results, err := sqld.ExecuteRaw[sqlc.GetEmployeesWithAccountsParams, sqlc.GetEmployeesWithAccountsRow](
+	resp, err := sqld.ExecuteRawPaginated[sqlc.GetEmployeesWithAccountsParams, sqlc.GetEmployeesWithAccountsRow](
 		r.Context(),
 		s.db,
 		req,
+		requestParams.Pagination,
 	)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	resp := struct {
-		Data []map[string]interface{} `json:"data"`
-	}{
-		Data: results,
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -14,7 +14,6 @@ import (
 	"github.com/remiges-tech/sqld"
 	"github.com/remiges-tech/sqld/examples/db/sqlc-gen"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -75,8 +74,8 @@ type Employee struct {
 	Department string     `json:"department" db:"department"`
 	Position   string     `json:"position" db:"position"`
 	IsActive   bool       `json:"is_active" db:"is_active"`
-	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at" sqld:"autocreate"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at" sqld:"autoupdate"`
 }
 
 func (Employee) TableName() string {
@@ -133,9 +132,9 @@ func (s *Server) DynamicQueryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req sqld.QueryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	req, err := sqld.DecodeQueryRequest(r.Body, sqld.DecodeOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -156,15 +155,17 @@ func (s *Server) PaginatedQueryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req sqld.QueryRequest
-	req.Pagination = &sqld.PaginationRequest{
-		Page:     1,
-		PageSize: 10,
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	req, err := sqld.DecodeQueryRequest(r.Body, sqld.DecodeOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if req.Pagination == nil {
+		req.Pagination = &sqld.PaginationRequest{
+			Page:     1,
+			PageSize: 10,
+		}
+	}
 
 	resp, err := sqld.Execute[Employee](r.Context(), s.db, req)
 	if err != nil {
@@ -183,9 +184,9 @@ func (s *Server) SQLCQueryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req sqld.QueryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	req, err := sqld.DecodeQueryRequest(r.Body, sqld.DecodeOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -329,10 +330,8 @@ func main() {
 		log.Fatal("Unable to parse pool config")
 	}
 
-	// Add AfterConnect hook to register enums
-	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		return sqld.AutoRegisterEnums(ctx, conn)
-	}
+	// Register enums on every new connection
+	sqld.ConfigurePool(poolConfig)
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
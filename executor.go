@@ -3,8 +3,9 @@ package sqld
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
@@ -26,156 +27,447 @@ type PgxQuerier interface {
 }
 
 // Execute runs the query and returns properly scanned results.
+// db may be a *sql.DB, *sql.Tx, *pgx.Conn, *pgxpool.Pool or pgx.Tx, so queries
+// can run inside a caller's transaction.
 func Execute[T Model](ctx context.Context, db interface{}, req QueryRequest) (QueryResponse[T], error) {
+	return executeWith[T](ctx, defaultRegistry, db, req)
+}
+
+// executeWith implements Execute with the models and converters of r.
+func executeWith[T Model](ctx context.Context, r *Registry, db interface{}, req QueryRequest) (QueryResponse[T], error) {
 	// Get model metadata using type parameter T
 	var model T
-	metadata, err := getModelMetadata(model)
+	metadata, err := r.getOrRegister(model)
 	if err != nil {
 		return QueryResponse[T]{}, fmt.Errorf("failed to get model metadata: %w", err)
 	}
 
-	// Call the validator before building and executing the query.
-	validator := BasicValidator{}
-	if err := validator.ValidateQuery(req, metadata); err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to validate query: %w", err)
+	resp, err := r.execute(ctx, db, metadata, req)
+	if err != nil {
+		return QueryResponse[T]{}, err
 	}
+	return QueryResponse[T](resp), nil
+}
 
-	// Handle pagination if requested
-	var paginationResp *PaginationResponse
-	if req.Pagination != nil || req.Limit != nil || req.Offset != nil {
-		if req.Pagination != nil {
-			// If req.Pagination is provided, it will override any previously set limit/offset values.
-			// This ensures that page-based pagination always takes precedence over direct limit/offset parameters.
-
-			// Validate and normalize pagination parameters
-			req.Pagination = ValidatePagination(req.Pagination)
-
-			// Set limit and offset based on pagination
-			limit := req.Pagination.PageSize
-			offset := CalculateOffset(req.Pagination.Page, req.Pagination.PageSize)
-			req.Limit = &limit
-			req.Offset = &offset
+// execute runs req against the model described by metadata.
+// It is the untyped implementation of Execute.
+func (r *Registry) execute(ctx context.Context, db interface{}, metadata ModelMetadata, req QueryRequest) (resp QueryResponse[Model], err error) {
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, "sqld.execute", metadata.TableName, spanOperationKey.String(OperationExecute))
+	var exec *execution
+	defer func() {
+		span.SetAttributes(spanRowsKey.Int(len(resp.Data)))
+		endSpan(span, err)
+		r.observeExecution(OperationExecute, metadata, exec, start, err)
+	}()
+
+	exec, err = r.newExecution(ctx, metadata, req)
+	if err != nil {
+		return QueryResponse[Model]{}, err
+	}
+
+	// The query timeout covers all the queries of the request
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+
+	err = r.guard(func() error {
+		for _, stmt := range exec.statements {
+			if err := stmt.run(ctx, r, db, metadata.TableName); err != nil {
+				return timeoutError(ctx, timeout, err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return QueryResponse[Model]{}, err
 	}
+	return exec.response(r)
+}
 
-	// Build query using the generic buildQuery
-	builder, err := buildQuery[T](req)
+// execution is a structured query broken into the statements it runs, which
+// scan their rows into it, so they can run one after the other or in a batch.
+type execution struct {
+	metadata   ModelMetadata
+	req        QueryRequest // The request, with its pagination resolved
+	loc        *time.Location
+	statements []statement
+	cached     bool // The SQL came from the query cache
+
+	pagination *PaginationResponse
+	aggregates map[string]interface{} // Row of the aggregate query
+	facets     map[string][]FacetCount
+	rows       []map[string]interface{}
+}
+
+// statement is a query of an execution.
+type statement struct {
+	name   string // Name of the span of the query
+	query  string
+	args   []interface{}
+	dest   interface{} // Where the rows are scanned
+	single bool        // The query returns exactly one row
+	errMsg string      // Context of errors of the query
+	done   func()      // Called once the rows are scanned, if not nil
+}
+
+// newExecution validates req and builds the statements that answer it: the
+// count of the rows if req is paginated, its aggregates and facets, and its
+// rows, in that order.
+func (r *Registry) newExecution(ctx context.Context, metadata ModelMetadata, req QueryRequest) (*execution, error) {
+	req, compiled, args, cached, err := r.compileQuery(ctx, metadata, req)
+	if err != nil {
+		return nil, err
+	}
+	page, pageArgs, err := pageClause(req, len(args))
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to build query: %w", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
+	loc, err := r.locationFor(req)
+	if err != nil {
+		return nil, err
+	}
+	exec := &execution{metadata: metadata, req: req, loc: loc, cached: cached}
 
-	// If pagination is requested or limit/offset is set, we need to get total count
+	// If pagination is requested or limit/offset is set, we need to get total count.
+	// The count also tells how many rows the page holds, to size the results.
 	if req.Pagination != nil || req.Limit != nil || req.Offset != nil {
-		// Create a new count query builder with the same conditions
-		// Use Postgres placeholder format ($1, $2, etc)
-		builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-		countBuilder := builder.Select("COUNT(*)").From(model.TableName())
-
-		// Apply the same where conditions if they exist
-		for _, cond := range req.Where {
-			field, ok := metadata.Fields[cond.Field]
-			if !ok {
-				return QueryResponse[T]{}, fmt.Errorf("invalid field in where clause: %s", cond.Field)
-			}
+		// The count query has the same conditions as the main query
+		countQuery, countArgs := compiled.countSQL, args
 
-			whereClause, err := buildWhereClause(field.Name, cond)
-			if err != nil {
-				return QueryResponse[T]{}, err
-			}
-			countBuilder = countBuilder.Where(whereClause)
-		}
+		var totalItems int
+		exec.statements = append(exec.statements, statement{
+			name:   "sqld.count",
+			query:  countQuery,
+			args:   countArgs,
+			dest:   &totalItems,
+			single: true,
+			errMsg: "failed to get total count",
+			done: func() {
+				exec.rows = make([]map[string]interface{}, 0, pageRowCount(totalItems, req.Limit, req.Offset))
 
-		countQuery, countArgs, err := countBuilder.ToSql()
-		if err != nil {
-			return QueryResponse[T]{}, fmt.Errorf("failed to generate count sql: %w", err)
+				if req.Pagination != nil {
+					exec.pagination = CalculatePagination(totalItems, req.Pagination.PageSize, req.Pagination.Page)
+				} else if req.Limit != nil {
+					pageSize := *req.Limit
+					currentPage := 1
+					if req.Offset != nil {
+						currentPage = (*req.Offset / pageSize) + 1
+					}
+					exec.pagination = CalculatePagination(totalItems, pageSize, currentPage)
+				}
+			},
+		})
+	}
+
+	// Compute summary aggregates over the full filtered set
+	if len(req.Aggregates) > 0 {
+		exec.statements = append(exec.statements, statement{
+			name:   "sqld.aggregates",
+			query:  compiled.aggregateSQL,
+			args:   args,
+			dest:   &exec.aggregates,
+			single: true,
+			errMsg: "failed to compute aggregates",
+		})
+	}
+
+	// Compute value counts for each requested facet field
+	if len(req.Facets) > 0 {
+		exec.facets = make(map[string][]FacetCount, len(req.Facets))
+		for i, facet := range req.Facets {
+			var counts []FacetCount
+			exec.statements = append(exec.statements, statement{
+				name:   "sqld.facet",
+				query:  compiled.facetSQL[i],
+				args:   args,
+				dest:   &counts,
+				errMsg: "failed to compute facets",
+				done:   func() { exec.facets[facet] = counts },
+			})
 		}
+	}
+
+	// The main query selects the rows of the page
+	exec.statements = append(exec.statements, statement{
+		name:   "sqld.query",
+		query:  compiled.sql + page,
+		args:   append(append([]interface{}(nil), args...), pageArgs...),
+		dest:   &exec.rows,
+		errMsg: "failed to execute query",
+	})
+	return exec, nil
+}
 
-		// Log the query for debugging
-		log.Printf("Count Query: %s with args: %v", countQuery, countArgs)
+// run runs s on db and scans its rows, using the scanner matching the type of
+// db, in a span of the name of s.
+func (s statement) run(ctx context.Context, r *Registry, db interface{}, table string) (err error) {
+	ctx, span := r.startSpan(ctx, s.name, table, spanSQL(s.query))
+	defer func() { endSpan(span, err) }()
+	r.logSQL(ctx, table, s.query, s.args)
 
-		var totalItems int
-		switch db := db.(type) {
-		case *sql.DB:
-			err = sqlscan.Get(ctx, db, &totalItems, countQuery, countArgs...)
-		case *pgx.Conn:
-			err = pgxscan.Get(ctx, db, &totalItems, countQuery, countArgs...)
-		case *pgxpool.Pool:
-			err = pgxscan.Get(ctx, db, &totalItems, countQuery, countArgs...)
-		default:
-			return QueryResponse[T]{}, fmt.Errorf("unsupported database type: %T", db)
+	start := time.Now()
+	if s.single {
+		err = r.getPrepared(ctx, db, s.dest, s.query, s.args...)
+	} else {
+		err = r.selectPrepared(ctx, db, s.dest, s.query, s.args...)
+	}
+	r.logSlowQuery(ctx, table, s.query, s.args, time.Since(start))
+	if err != nil {
+		return wrapDBError(s.errMsg, err)
+	}
+	span.SetAttributes(spanRowsKey.Int(destRows(s.dest)))
+	if s.done != nil {
+		s.done()
+	}
+	return nil
+}
+
+// scan scans the rows of s, as returned by a pgx batch.
+func (s statement) scan(rows pgx.Rows) error {
+	var err error
+	if s.single {
+		err = pgxscan.ScanOne(s.dest, rows)
+	} else {
+		err = pgxscan.ScanAll(s.dest, rows)
+	}
+	if err != nil {
+		return wrapDBError(s.errMsg, err)
+	}
+	if s.done != nil {
+		s.done()
+	}
+	return nil
+}
+
+// response converts the rows scanned by the statements of exec.
+func (exec *execution) response(r *Registry) (QueryResponse[Model], error) {
+	metadata, req := exec.metadata, exec.req
+
+	var aggregates map[string]interface{}
+	if len(req.Aggregates) > 0 {
+		aggregates = make(map[string]interface{}, len(req.Aggregates))
+		for i, agg := range req.Aggregates {
+			aggregates[agg.Key()] = exec.aggregates[fmt.Sprintf("agg_%d", i)]
 		}
+	}
 
+	// Convert the results to our QueryResult type, planning the conversion
+	// of each field once for all rows
+	keys := req.Select
+	if len(req.Select) == 1 && req.Select[0] == SelectAll {
+		// When "ALL" is specified, map all fields from the metadata
+		keys = make([]string, 0, len(metadata.Fields))
+		for jsonName := range metadata.Fields {
+			keys = append(keys, jsonName)
+		}
+	}
+	plan := r.newResultPlan(metadata, keys, nil, exec.loc)
+	queryResults := make([]QueryResult, len(exec.rows))
+	for i, result := range exec.rows {
+		queryResult, err := plan.mapRow(result)
 		if err != nil {
-			return QueryResponse[T]{}, fmt.Errorf("failed to get total count: %w", err)
+			return QueryResponse[Model]{}, err
 		}
+		queryResults[i] = queryResult
+	}
 
-		if req.Pagination != nil {
-			paginationResp = CalculatePagination(totalItems, req.Pagination.PageSize, req.Pagination.Page)
-		} else if req.Limit != nil {
-			pageSize := *req.Limit
-			currentPage := 1
-			if req.Offset != nil {
-				currentPage = (*req.Offset / pageSize) + 1
-			}
-			paginationResp = CalculatePagination(totalItems, pageSize, currentPage)
+	var asOf *time.Time
+	if metadata.MaterializedView {
+		asOf = r.refreshTime(metadata.TableName)
+	}
+
+	return QueryResponse[Model]{
+		Data:       queryResults,
+		Pagination: exec.pagination,
+		Aggregates: aggregates,
+		Facets:     exec.facets,
+		AsOf:       asOf,
+	}, nil
+}
+
+// scanResult runs the values of result through the scanners registered for
+// the types of their fields, so custom types come back as their Go values, and
+// fails if an enum field holds a label that was not registered. Numeric values are then rendered as strings if RenderNumericsAsStrings was called.
+// Rows of a query are converted with a resultPlan instead.
+func (r *Registry) scanResult(metadata ModelMetadata, result map[string]interface{}) error {
+	keys := make([]string, 0, len(result))
+	for jsonName := range result {
+		keys = append(keys, jsonName)
+	}
+	plan := r.newResultPlan(metadata, keys, nil, nil)
+	for _, f := range plan.fields {
+		converted, err := plan.value(f, result[f.key])
+		if err != nil {
+			return err
 		}
+		result[f.key] = converted
+	}
+	return nil
+}
+
+// prepareQuery validates req, resolves its pagination into a limit and offset and
+// builds the SELECT of the requested rows. It returns the resolved request.
+func (r *Registry) prepareQuery(metadata ModelMetadata, req QueryRequest) (QueryRequest, squirrel.SelectBuilder, error) {
+	// Call the validator before building and executing the query.
+	validator := BasicValidator{}
+	if err := validator.ValidateQuery(req, metadata); err != nil {
+		return QueryRequest{}, squirrel.SelectBuilder{}, fmt.Errorf("failed to validate query: %w", err)
 	}
 
-	// Get the query and args for the main query
-	query, args, err := builder.ToSql()
+	req = resolvePagination(req)
+
+	// Build query using the generic buildQuery
+	builder, err := r.buildSelectQuery(metadata.TableName, metadata, req)
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to generate sql: %w", err)
+		return QueryRequest{}, squirrel.SelectBuilder{}, fmt.Errorf("failed to build query: %w", err)
+	}
+	return req, builder, nil
+}
+
+// resolvePagination returns req with its page, if any, turned into a limit and offset.
+func resolvePagination(req QueryRequest) QueryRequest {
+	if req.Pagination != nil {
+		// If req.Pagination is provided, it will override any previously set limit/offset values.
+		// This ensures that page-based pagination always takes precedence over direct limit/offset parameters.
+
+		// Validate and normalize pagination parameters
+		req.Pagination = ValidatePagination(req.Pagination)
+
+		// Set limit and offset based on pagination
+		limit := req.Pagination.PageSize
+		offset := CalculateOffset(req.Pagination.Page, req.Pagination.PageSize)
+		req.Limit = &limit
+		req.Offset = &offset
 	}
+	return req
+}
+
+// ErrUnsupportedDB is returned when the db handle passed to an execution function
+// is not one of the supported database types.
+type ErrUnsupportedDB struct {
+	DB interface{}
+}
+
+func (e *ErrUnsupportedDB) Error() string {
+	return fmt.Sprintf("unsupported database type: %T", e.DB)
+}
+
+// wrapDBError adds context to an error returned by selectAll or getOne.
+// ErrUnsupportedDB is returned unchanged so every entry point reports it the same way.
+func wrapDBError(msg string, err error) error {
+	var unsupported *ErrUnsupportedDB
+	if errors.As(err, &unsupported) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
 
-	// Use appropriate scanner based on the database type
-	var results []map[string]interface{}
+// selectAll runs query and scans all rows into dest using the scanner
+// matching the type of db. Supported handles are *sql.DB, *sql.Conn, *sql.Tx,
+// *pgx.Conn, *pgxpool.Pool and pgx.Tx (which includes *pgxpool.Tx). If ctx
+// carries an acquire timeout, see queryContext, pools are bounded by it.
+func selectAll(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	db, release, err := acquireConn(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer release()
 	switch db := db.(type) {
 	case *sql.DB:
-		err = sqlscan.Select(ctx, db, &results, query, args...)
+		return sqlscan.Select(ctx, db, dest, query, args...)
+	case *sql.Conn:
+		return sqlscan.Select(ctx, db, dest, query, args...)
+	case *sql.Tx:
+		return sqlscan.Select(ctx, db, dest, query, args...)
 	case *pgx.Conn:
-		err = pgxscan.Select(ctx, db, &results, query, args...)
+		return pgxscan.Select(ctx, db, dest, query, args...)
 	case *pgxpool.Pool:
-		err = pgxscan.Select(ctx, db, &results, query, args...)
+		return pgxscan.Select(ctx, db, dest, query, args...)
+	case pgx.Tx:
+		return pgxscan.Select(ctx, db, dest, query, args...)
 	default:
-		return QueryResponse[T]{}, fmt.Errorf("unsupported database type: %T", db)
+		return &ErrUnsupportedDB{DB: db}
 	}
+}
 
+// getOne runs query and scans exactly one row into dest using the scanner
+// matching the type of db.
+func getOne(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	db, release, err := acquireConn(ctx, db)
 	if err != nil {
-		return QueryResponse[T]{}, fmt.Errorf("failed to execute query: %w", err)
-	}
-
-	// Convert the results to our QueryResult type
-	queryResults := make([]QueryResult, len(results))
-	for i, result := range results {
-		queryResult := make(QueryResult)
-		
-		// Handle "ALL" select case
-		if len(req.Select) == 1 && req.Select[0] == SelectAll {
-			// When "ALL" is specified, map all fields from the metadata
-			for jsonName, fieldMeta := range metadata.Fields {
-				if val, ok := result[fieldMeta.Name]; ok { // Use database column name
-					queryResult[jsonName] = val // Use JSON name from metadata
-				}
-			}
-		} else {
-			// Handle specific field selection
-			for _, field := range req.Select {
-				fieldMeta := metadata.Fields[field]
-				if val, ok := result[fieldMeta.Name]; ok { // Use database column name
-					queryResult[field] = val // Use JSON name from request
-				}
-			}
-		}
-		queryResults[i] = queryResult
+		return err
+	}
+	defer release()
+	switch db := db.(type) {
+	case *sql.DB:
+		return sqlscan.Get(ctx, db, dest, query, args...)
+	case *sql.Conn:
+		return sqlscan.Get(ctx, db, dest, query, args...)
+	case *sql.Tx:
+		return sqlscan.Get(ctx, db, dest, query, args...)
+	case *pgx.Conn:
+		return pgxscan.Get(ctx, db, dest, query, args...)
+	case *pgxpool.Pool:
+		return pgxscan.Get(ctx, db, dest, query, args...)
+	case pgx.Tx:
+		return pgxscan.Get(ctx, db, dest, query, args...)
+	default:
+		return &ErrUnsupportedDB{DB: db}
 	}
+}
 
-	return QueryResponse[T]{
-		Data:       queryResults,
-		Pagination: paginationResp,
-	}, nil
+// execStatement runs a statement that returns no rows and reports the number
+// of rows it affected.
+func execStatement(ctx context.Context, db interface{}, query string, args ...interface{}) (int64, error) {
+	db, release, err := acquireConn(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	switch db := db.(type) {
+	case *sql.DB:
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	case *sql.Tx:
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	case *sql.Conn:
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	case *pgx.Conn:
+		tag, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	case *pgxpool.Pool:
+		tag, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	case pgx.Tx:
+		tag, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	default:
+		return 0, &ErrUnsupportedDB{DB: db}
+	}
 }
 
-// TODO: Add connection pooling configuration
-// TODO: Add caching layer for frequently used queries
-// TODO: Add query execution timeout handling
-// TODO: Add detailed error context and error codes
+// isNoRows reports whether err means a single-row query found no rows,
+// for both database/sql and pgx.
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows)
+}
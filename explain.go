@@ -0,0 +1,52 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ExplainRaw returns the plan PostgreSQL chooses for a raw query, as the JSON
+// output of EXPLAIN (FORMAT JSON) for the final parameterized query. The query
+// is validated like in ExecuteRaw but not run.
+//
+// ExecuteRaw with ExecuteRawRequest.Explain set returns the same plan as the
+// "plan" entry of a single result row.
+func ExplainRaw[P Model](ctx context.Context, db interface{}, req ExecuteRawRequest) (json.RawMessage, error) {
+	return explainRawWith[P](ctx, defaultRegistry, db, req)
+}
+
+// explainRawWith implements ExplainRaw with the queries and rules of r.
+func explainRawWith[P Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest) (json.RawMessage, error) {
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.OrderBy) > 0 {
+		// Ordering is validated against the result model, which ExplainRaw does not have
+		return nil, fmt.Errorf("OrderBy is not supported by ExplainRaw, use ExecuteRaw with Explain set")
+	}
+	if err := r.validateSQLSyntax(finalQuery); err != nil {
+		return nil, err
+	}
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	var plan json.RawMessage
+	err = r.runRaw(ctx, "sqld.explain", "", timeout, finalQuery, args, func(ctx context.Context) (err error) {
+		plan, err = explainQuery(ctx, db, finalQuery, args)
+		return err
+	})
+	return plan, err
+}
+
+// explainQuery returns the EXPLAIN (FORMAT JSON) plan of a validated query.
+func explainQuery(ctx context.Context, db interface{}, query string, args []interface{}) (json.RawMessage, error) {
+	var plans []string
+	if err := selectAll(ctx, db, &plans, "EXPLAIN (FORMAT JSON) "+query, args...); err != nil {
+		return nil, wrapDBError("failed to explain query", err)
+	}
+	if len(plans) != 1 {
+		return nil, fmt.Errorf("failed to explain query: got %d plan rows, want 1", len(plans))
+	}
+	return json.RawMessage(plans[0]), nil
+}
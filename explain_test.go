@@ -0,0 +1,52 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainRaw(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	const plan = `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "test_results"}}]`
+	req := ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	}
+
+	t.Run("ExplainRaw", func(t *testing.T) {
+		db, fake := newFakeDB(t, []string{"QUERY PLAN"}, []driver.Value{plan})
+
+		got, err := ExplainRaw[TestParams](context.Background(), db, req)
+		require.NoError(t, err)
+		assert.JSONEq(t, plan, string(got))
+		assert.Equal(t, []string{"EXPLAIN (FORMAT JSON) SELECT id, name FROM test_results WHERE id = $1"}, fake.statements)
+		assert.Equal(t, [][]driver.Value{{int64(1)}}, fake.args)
+	})
+
+	t.Run("ExecuteRaw option", func(t *testing.T) {
+		db, fake := newFakeDB(t, []string{"QUERY PLAN"}, []driver.Value{plan})
+		explainReq := req
+		explainReq.Explain = true
+
+		results, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, explainReq)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		encoded, err := json.Marshal(results[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan": `+plan+`}`, string(encoded))
+		assert.Equal(t, []string{"EXPLAIN (FORMAT JSON) SELECT id, name FROM test_results WHERE id = $1"}, fake.statements)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+		_, err := ExplainRaw[TestParams](context.Background(), db, ExecuteRawRequest{Query: "DELETE FROM test_results"})
+		assert.EqualError(t, err, "only SELECT statements are allowed")
+		assert.Empty(t, fake.statements)
+	})
+}
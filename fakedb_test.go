@@ -0,0 +1,204 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is an in-memory database/sql driver for executor tests. Queries return
+// the queued results in order, then the configured rows; statements and
+// transaction outcomes are recorded.
+type fakeDB struct {
+	mu           sync.Mutex
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+	queued       []fakeResult
+	statements   []string
+	args         [][]driver.Value
+	commits      int
+	rollbacks    int
+	prepares     int
+	delay        time.Duration // How long statements take, unless their context is done first
+}
+
+// fakeResult is a result set returned by a single query.
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = make(map[string]*fakeDB)
+)
+
+func init() {
+	sql.Register("sqld_fake", fakeDriver{})
+}
+
+// newFakeDB opens a *sql.DB backed by a new fakeDB.
+func newFakeDB(t *testing.T, columns []string, rows ...[]driver.Value) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{columns: columns, rows: rows}
+
+	fakeDBsMu.Lock()
+	name := fmt.Sprintf("%s/%d", t.Name(), len(fakeDBs))
+	fakeDBs[name] = fake
+	fakeDBsMu.Unlock()
+
+	db, err := sql.Open("sqld_fake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+// queue adds a result set returned by the next query that has no earlier queued result.
+func (f *fakeDB) queue(columns []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queued = append(f.queued, fakeResult{columns: columns, rows: rows})
+}
+
+func (f *fakeDB) record(query string, args []driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, query)
+	f.args = append(f.args, args)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	fake, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	return &fakeConn{db: fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	c.db.prepares++
+	c.db.mu.Unlock()
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.record(s.query, args)
+	return driver.RowsAffected(s.db.rowsAffected), nil
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.db.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Exec(namedValues(args))
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.db.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Query(namedValues(args))
+}
+
+// wait blocks for the configured delay, or until ctx is done.
+func (f *fakeDB) wait(ctx context.Context) error {
+	if f.delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(f.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.record(s.query, args)
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if len(s.db.queued) > 0 {
+		result := s.db.queued[0]
+		s.db.queued = s.db.queued[1:]
+		return &fakeRows{columns: result.columns, rows: result.rows}, nil
+	}
+	return &fakeRows{columns: s.db.columns, rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,155 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+)
+
+// QueryBuilder incrementally constructs a QueryRequest for model T.
+// It is a convenience for Go callers; the request it produces goes through
+// the same validation and execution as one decoded from JSON.
+//
+// Example:
+//
+//	q := sqld.For[Employee]()
+//	q.Select("id", "first_name", "salary").
+//	    Where("department", sqld.OpEqual, "Engineering").
+//	    OrderByDesc("salary").
+//	    Page(2)
+//	resp, err := q.Execute(ctx, db)
+//
+// A QueryBuilder is not safe for concurrent use.
+type QueryBuilder[T Model] struct {
+	req QueryRequest
+}
+
+// For starts a new QueryBuilder for model T.
+func For[T Model]() *QueryBuilder[T] {
+	return &QueryBuilder[T]{}
+}
+
+// Select adds fields to the select list.
+func (q *QueryBuilder[T]) Select(fields ...string) *QueryBuilder[T] {
+	q.req.Select = append(q.req.Select, fields...)
+	return q
+}
+
+// SelectAll selects every field of the model.
+func (q *QueryBuilder[T]) SelectAll() *QueryBuilder[T] {
+	q.req.Select = []string{SelectAll}
+	return q
+}
+
+// Where adds a condition. Conditions are combined with AND.
+func (q *QueryBuilder[T]) Where(field string, op Operator, value interface{}) *QueryBuilder[T] {
+	q.req.Where = append(q.req.Where, Condition{Field: field, Operator: op, Value: value})
+	return q
+}
+
+// WhereConditions adds already constructed conditions.
+func (q *QueryBuilder[T]) WhereConditions(conds ...Condition) *QueryBuilder[T] {
+	q.req.Where = append(q.req.Where, conds...)
+	return q
+}
+
+// OrderBy adds an ascending sort on field.
+func (q *QueryBuilder[T]) OrderBy(field string) *QueryBuilder[T] {
+	q.req.OrderBy = append(q.req.OrderBy, OrderByClause{Field: field})
+	return q
+}
+
+// OrderByDesc adds a descending sort on field.
+func (q *QueryBuilder[T]) OrderByDesc(field string) *QueryBuilder[T] {
+	q.req.OrderBy = append(q.req.OrderBy, OrderByClause{Field: field, Desc: true})
+	return q
+}
+
+// Page selects the page to return, starting at 1.
+// The page size defaults to DefaultPageSize unless set with PageSize.
+func (q *QueryBuilder[T]) Page(page int) *QueryBuilder[T] {
+	q.pagination().Page = page
+	return q
+}
+
+// PageSize sets the number of results per page.
+func (q *QueryBuilder[T]) PageSize(size int) *QueryBuilder[T] {
+	q.pagination().PageSize = size
+	return q
+}
+
+// Limit sets the maximum number of results. It is ignored if Page or PageSize is used.
+func (q *QueryBuilder[T]) Limit(limit int) *QueryBuilder[T] {
+	q.req.Limit = &limit
+	return q
+}
+
+// Offset sets the number of results to skip. It is ignored if Page or PageSize is used.
+func (q *QueryBuilder[T]) Offset(offset int) *QueryBuilder[T] {
+	q.req.Offset = &offset
+	return q
+}
+
+// Aggregate requests a summary value over the full filtered set.
+func (q *QueryBuilder[T]) Aggregate(fn AggregateFunc, field string) *QueryBuilder[T] {
+	q.req.Aggregates = append(q.req.Aggregates, Aggregate{Func: fn, Field: field})
+	return q
+}
+
+// Facets requests value counts for the given fields.
+func (q *QueryBuilder[T]) Facets(fields ...string) *QueryBuilder[T] {
+	q.req.Facets = append(q.req.Facets, fields...)
+	return q
+}
+
+// Request returns a copy of the QueryRequest built so far.
+// Later changes to the builder do not affect the returned request.
+func (q *QueryBuilder[T]) Request() QueryRequest {
+	req := q.req
+	req.Select = append([]string(nil), q.req.Select...)
+	req.Where = append([]Condition(nil), q.req.Where...)
+	req.OrderBy = append([]OrderByClause(nil), q.req.OrderBy...)
+	req.Aggregates = append([]Aggregate(nil), q.req.Aggregates...)
+	req.Facets = append([]string(nil), q.req.Facets...)
+	if q.req.Pagination != nil {
+		pagination := *q.req.Pagination
+		req.Pagination = &pagination
+	}
+	return req
+}
+
+// Validate checks the request built so far against T's metadata
+// without executing it.
+func (q *QueryBuilder[T]) Validate() error {
+	return q.validate(defaultRegistry)
+}
+
+// ValidateOn is Validate with the Registry of c.
+func (q *QueryBuilder[T]) ValidateOn(c *Client) error {
+	return q.validate(c.Registry)
+}
+
+func (q *QueryBuilder[T]) validate(r *Registry) error {
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	return BasicValidator{}.ValidateQuery(q.Request(), metadata)
+}
+
+// Execute runs the request built so far with Execute.
+func (q *QueryBuilder[T]) Execute(ctx context.Context, db interface{}) (QueryResponse[T], error) {
+	return Execute[T](ctx, db, q.Request())
+}
+
+// ExecuteOn runs the request built so far with ExecuteOn.
+func (q *QueryBuilder[T]) ExecuteOn(ctx context.Context, c *Client) (QueryResponse[T], error) {
+	return ExecuteOn[T](ctx, c, q.Request())
+}
+
+func (q *QueryBuilder[T]) pagination() *PaginationRequest {
+	if q.req.Pagination == nil {
+		q.req.Pagination = &PaginationRequest{Page: 1, PageSize: DefaultPageSize}
+	}
+	return q.req.Pagination
+}
@@ -0,0 +1,67 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBuilderProducesRequest(t *testing.T) {
+	q := For[BuilderTestModel]()
+	q.Select("id", "name").
+		Where("age", OpGreaterThan, 18).
+		Where("active", OpEqual, true).
+		OrderByDesc("age").
+		OrderBy("name").
+		Page(2)
+
+	req := q.Request()
+	assert.Equal(t, []string{"id", "name"}, req.Select)
+	assert.Equal(t, []Condition{
+		{Field: "age", Operator: OpGreaterThan, Value: 18},
+		{Field: "active", Operator: OpEqual, Value: true},
+	}, req.Where)
+	assert.Equal(t, []OrderByClause{{Field: "age", Desc: true}, {Field: "name"}}, req.OrderBy)
+	require.NotNil(t, req.Pagination)
+	assert.Equal(t, 2, req.Pagination.Page)
+	assert.Equal(t, DefaultPageSize, req.Pagination.PageSize)
+
+	// The returned request is a snapshot
+	q.Select("email").PageSize(50)
+	assert.Equal(t, []string{"id", "name"}, req.Select)
+	assert.Equal(t, DefaultPageSize, req.Pagination.PageSize)
+	assert.Equal(t, 50, q.Request().Pagination.PageSize)
+}
+
+func TestQueryBuilderValidate(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	err := For[BuilderTestModel]().Select("id").Where("age", OpEqual, 30).Validate()
+	assert.NoError(t, err)
+
+	err = For[BuilderTestModel]().Select("id").Where("age", OpEqual, "thirty").Validate()
+	assert.Error(t, err)
+
+	err = For[BuilderTestModel]().Select("invalid_field").Validate()
+	assert.Error(t, err)
+}
+
+func TestQueryBuilderBuildsSameSQLAsRequest(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	req := For[BuilderTestModel]().
+		Select("name", "age").
+		Where("age", OpGreaterThan, 25).
+		OrderBy("name").
+		Limit(10).
+		Offset(20).
+		Request()
+
+	got, err := buildQuery[BuilderTestModel](req)
+	require.NoError(t, err)
+
+	sql, _, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT name, age FROM test_models WHERE age > $1 ORDER BY name ASC LIMIT 10 OFFSET 20", sql)
+}
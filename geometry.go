@@ -0,0 +1,274 @@
+package sqld
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+)
+
+// WKT holds a PostGIS geometry or geography column as well-known text, such as
+// "POINT(77.59 12.97)". Fields of this type are selected as ST_AsText(column),
+// so sqld passes spatial values through without understanding them. Values
+// written to them are sent as text, which PostGIS parses as WKT or EWKT
+// ("SRID=4326;POINT(77.59 12.97)").
+type WKT string
+
+// GeoJSON holds a PostGIS geometry or geography column as a GeoJSON document.
+// Fields of this type are selected as ST_AsGeoJSON(column) and the document is
+// embedded as is in JSON results.
+type GeoJSON string
+
+// MarshalJSON returns the document itself, or null if it is empty.
+func (g GeoJSON) MarshalJSON() ([]byte, error) {
+	if g == "" {
+		return []byte("null"), nil
+	}
+	if !json.Valid([]byte(g)) {
+		return nil, fmt.Errorf("invalid GeoJSON document")
+	}
+	return []byte(g), nil
+}
+
+// UnmarshalJSON stores the document as is.
+func (g *GeoJSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*g = ""
+		return nil
+	}
+	*g = GeoJSON(data)
+	return nil
+}
+
+var (
+	wktType     = reflect.TypeOf(WKT(""))
+	geoJSONType = reflect.TypeOf(GeoJSON(""))
+)
+
+// isSpatialType reports whether fields of type t hold a geometry or geography
+// column, that is whether t is WKT or GeoJSON.
+func isSpatialType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == wktType || t == geoJSONType
+}
+
+// selectColumn returns the expression selecting field, aliased to its column
+// name when it is not the column itself.
+func selectColumn(field Field) string {
+	if !field.Spatial {
+		return field.Name
+	}
+	t := field.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == geoJSONType {
+		return fmt.Sprintf("ST_AsGeoJSON(%s) AS %s", field.Name, field.Name)
+	}
+	return fmt.Sprintf("ST_AsText(%s) AS %s", field.Name, field.Name)
+}
+
+// geoJSONScanner scans the text returned by ST_AsGeoJSON into a GeoJSON, so
+// that results embed the document instead of a string holding it.
+type geoJSONScanner struct {
+	v interface{}
+}
+
+func (s *geoJSONScanner) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		s.v = nil
+	case string:
+		s.v = GeoJSON(v)
+	case []byte:
+		s.v = GeoJSON(v)
+	default:
+		return fmt.Errorf("cannot scan %T into GeoJSON", src)
+	}
+	return nil
+}
+
+func (s *geoJSONScanner) Value() interface{} {
+	return s.v
+}
+
+// spatialConditionViolation checks a condition on a spatial field.
+// Spatial values are opaque to sqld, so they can only be tested for NULL;
+// other spatial filters are registered with RegisterComputedCondition.
+func spatialConditionViolation(path string, cond Condition) *Violation {
+	if cond.Operator == OpIsNull || cond.Operator == OpIsNotNull {
+		return nil
+	}
+	v := newViolation(CodeSpatialField, path+".operator",
+		"operator %s cannot be used on spatial field %s, use a computed condition", cond.Operator, cond.Field)
+	return &v
+}
+
+// ComputedCondition is a filter written in SQL and registered for a model,
+// for conditions sqld cannot build itself, such as PostGIS distance queries.
+// Requests use it by name with OpMatches, giving the values of its
+// parameters:
+//
+//	{"field": "near", "operator": "MATCHES", "value": {"lng": 77.59, "lat": 12.97, "meters": 500}}
+//
+// Only registered SQL reaches the database; requests only supply parameters,
+// which are bound as query arguments.
+type ComputedCondition struct {
+	// Name is the name requests use in Condition.Field. It cannot be the
+	// name of a field of the model.
+	Name string `json:"name"`
+
+	// SQL is a boolean expression on the model's columns. Parameters are
+	// written as {{name}} placeholders, as in registered raw queries. Since
+	// arguments are bound with ? placeholders, the expression cannot use
+	// the ? operator.
+	SQL string `json:"-"`
+
+	// Params gives the type of each parameter. Every parameter is required.
+	Params map[string]reflect.Type `json:"-"`
+}
+
+// RegisterComputedCondition registers c for model T in the default registry.
+// See Registry.RegisterComputedCondition.
+//
+//	err := sqld.RegisterComputedCondition[Store](sqld.ComputedCondition{
+//	    Name: "near",
+//	    SQL:  "ST_DWithin(location, ST_SetSRID(ST_MakePoint({{lng}}, {{lat}}), 4326)::geography, {{meters}})",
+//	    Params: map[string]reflect.Type{
+//	        "lng":    reflect.TypeOf(float64(0)),
+//	        "lat":    reflect.TypeOf(float64(0)),
+//	        "meters": reflect.TypeOf(float64(0)),
+//	    },
+//	})
+func RegisterComputedCondition[T Model](c ComputedCondition) error {
+	var model T
+	return defaultRegistry.RegisterComputedCondition(model, c)
+}
+
+// RegisterComputedCondition registers c for model, which is registered if
+// needed. Its SQL must parse, and its placeholders must match its parameters.
+func (r *Registry) RegisterComputedCondition(model Model, c ComputedCondition) error {
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if c.Name == "" {
+		return fmt.Errorf("computed condition of model %s has no name", metadata.TableName)
+	}
+	if _, ok := metadata.Fields[c.Name]; ok {
+		return fmt.Errorf("computed condition %s has the name of a field of model %s", c.Name, metadata.TableName)
+	}
+
+	used := make(map[string]bool)
+	for _, m := range namedParamRegex.FindAllStringSubmatch(c.SQL, -1) {
+		if _, ok := c.Params[m[1]]; !ok {
+			return fmt.Errorf("computed condition %s uses undeclared parameter %s", c.Name, m[1])
+		}
+		used[m[1]] = true
+	}
+	params := make(map[string]reflect.Type, len(c.Params))
+	for name, t := range c.Params {
+		if !used[name] {
+			return fmt.Errorf("computed condition %s does not use parameter %s", c.Name, name)
+		}
+		params[name] = t
+	}
+	c.Params = params
+	sql, _, err := bindPlaceholders(c.SQL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid computed condition %s: %w", c.Name, err)
+	}
+	if _, err := parser.ParseOne("SELECT 1 WHERE " + sql); err != nil {
+		return fmt.Errorf("invalid SQL in computed condition %s: %w", c.Name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := reflect.TypeOf(model)
+	metadata = r.models[t]
+	if _, exists := metadata.Computed[c.Name]; exists {
+		return fmt.Errorf("model %s already has a computed condition named %s", metadata.TableName, c.Name)
+	}
+	// Copy the map, so metadata already handed out is left unchanged
+	computed := make(map[string]ComputedCondition, len(metadata.Computed)+1)
+	for name, existing := range metadata.Computed {
+		computed[name] = existing
+	}
+	computed[c.Name] = c
+	metadata.Computed = computed
+	r.models[t] = metadata
+	return nil
+}
+
+// paramNames returns the names of the parameters of c, sorted.
+func (c ComputedCondition) paramNames() []string {
+	names := make([]string, 0, len(c.Params))
+	for name := range c.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// computedConditionViolation checks a condition using the computed condition
+// c: it must use OpMatches and give a value of the right type for every
+// parameter, and no others.
+func computedConditionViolation(path string, cond Condition, c ComputedCondition) *Violation {
+	violation := func(code ViolationCode, path string, format string, args ...interface{}) *Violation {
+		v := newViolation(code, path, format, args...)
+		return &v
+	}
+
+	if cond.Operator != OpMatches {
+		return violation(CodeUnknownOperator, path+".operator", "computed condition %s only supports operator %s",
+			cond.Field, OpMatches)
+	}
+	params, ok := cond.Value.(map[string]interface{})
+	if !ok {
+		return violation(CodeTypeMismatch, path+".value", "value for computed condition %s must be an object of parameters",
+			cond.Field)
+	}
+	for _, name := range c.paramNames() {
+		value, ok := params[name]
+		if !ok || value == nil {
+			return violation(CodeRequired, path+".value."+name, "missing parameter %s of computed condition %s",
+				name, cond.Field)
+		}
+		want := normalizeReflectType(c.Params[name])
+		if !AreTypesCompatible(want, reflect.TypeOf(value)) {
+			return violation(CodeTypeMismatch, path+".value."+name, "invalid type for parameter %s: expected %v, got %T",
+				name, want, value)
+		}
+	}
+	for name := range params {
+		if _, ok := c.Params[name]; !ok {
+			return violation(CodeUnexpectedValue, path+".value."+name, "computed condition %s has no parameter %s",
+				cond.Field, name)
+		}
+	}
+	return nil
+}
+
+// computedClause builds the WHERE clause of a condition using c, binding its
+// parameters as arguments.
+func computedClause(c ComputedCondition, cond Condition) (squirrel.Sqlizer, error) {
+	params, ok := cond.Value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value for computed condition %s must be an object of parameters", c.Name)
+	}
+	sql, args, err := bindPlaceholders(c.SQL, params)
+	if err != nil {
+		return nil, err
+	}
+	// squirrel renumbers ? placeholders across the whole statement
+	sql, args, err = formatPlaceholders(sql, args, PlaceholderQuestion)
+	if err != nil {
+		return nil, err
+	}
+	return squirrel.Expr("("+sql+")", args...), nil
+}
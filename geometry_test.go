@@ -0,0 +1,182 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type GeometryTestStore struct {
+	ID       int64    `json:"id" db:"id"`
+	Location WKT      `json:"location" db:"location"`
+	Area     *GeoJSON `json:"area" db:"area"`
+}
+
+func (GeometryTestStore) TableName() string {
+	return "stores"
+}
+
+var floatParam = reflect.TypeOf(float64(0))
+
+func newGeometryTestRegistry(t *testing.T) (*Registry, ModelMetadata) {
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterComputedCondition(GeometryTestStore{}, ComputedCondition{
+		Name:   "near",
+		SQL:    "ST_DWithin(location, ST_SetSRID(ST_MakePoint({{lng}}, {{lat}}), 4326)::geography, {{meters}})",
+		Params: map[string]reflect.Type{"lng": floatParam, "lat": floatParam, "meters": floatParam},
+	}))
+	metadata, err := registry.GetModelMetadata(GeometryTestStore{})
+	require.NoError(t, err)
+	return registry, metadata
+}
+
+func TestSpatialFields(t *testing.T) {
+	registry, metadata := newGeometryTestRegistry(t)
+	assert.True(t, metadata.Fields["location"].Spatial)
+	assert.True(t, metadata.Fields["area"].Spatial)
+	assert.False(t, metadata.Fields["id"].Spatial)
+	assert.Equal(t, []Operator{OpIsNull, OpIsNotNull}, fieldOperators(metadata.Fields["location"]))
+
+	req := QueryRequest{
+		Select: []string{"id", "location", "area"},
+		Where:  []Condition{{Field: "area", Operator: OpIsNotNull}},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+	query, err := registry.buildSelectQuery(metadata.TableName, metadata, req)
+	require.NoError(t, err)
+	sql, _, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, ST_AsText(location) AS location, ST_AsGeoJSON(area) AS area FROM stores WHERE area IS NOT NULL", sql)
+
+	columns, err := returningColumns(metadata, []string{"id", "area"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "ST_AsGeoJSON(area) AS area"}, columns)
+
+	req.Where = []Condition{{Field: "location", Operator: OpEqual, Value: "POINT(0 0)"}}
+	var verr *ValidationError
+	require.ErrorAs(t, BasicValidator{}.ValidateQuery(req, metadata), &verr)
+	assert.Equal(t, []Violation{{
+		Code:    CodeSpatialField,
+		Path:    "where[0].operator",
+		Message: "operator = cannot be used on spatial field location, use a computed condition",
+	}}, verr.Rejected)
+}
+
+func TestGeoJSONResults(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id", "location", "area"},
+		[]driver.Value{int64(1), "POINT(77.59 12.97)", `{"type":"Point","coordinates":[77.59,12.97]}`},
+		[]driver.Value{int64(2), nil, nil},
+	)
+	resp, err := Execute[GeometryTestStore](context.Background(), db, QueryRequest{Select: []string{"id", "location", "area"}})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "location": "POINT(77.59 12.97)", "area": {"type": "Point", "coordinates": [77.59, 12.97]}},
+		{"id": 2, "location": null, "area": null}
+	]`, string(encoded))
+}
+
+func TestRegisterComputedCondition(t *testing.T) {
+	registry, _ := newGeometryTestRegistry(t)
+	tests := []struct {
+		name string
+		cond ComputedCondition
+		want string
+	}{
+		{
+			name: "field name",
+			cond: ComputedCondition{Name: "location", SQL: "location IS NULL"},
+			want: "computed condition location has the name of a field of model stores",
+		},
+		{
+			name: "duplicate",
+			cond: ComputedCondition{Name: "near", SQL: "location IS NULL"},
+			want: "model stores already has a computed condition named near",
+		},
+		{
+			name: "undeclared parameter",
+			cond: ComputedCondition{Name: "far", SQL: "NOT ST_DWithin(location, {{point}}, 10)"},
+			want: "computed condition far uses undeclared parameter point",
+		},
+		{
+			name: "unused parameter",
+			cond: ComputedCondition{Name: "empty", SQL: "ST_IsEmpty(location)", Params: map[string]reflect.Type{"x": floatParam}},
+			want: "computed condition empty does not use parameter x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, registry.RegisterComputedCondition(GeometryTestStore{}, tt.cond), tt.want)
+		})
+	}
+
+	err := registry.RegisterComputedCondition(GeometryTestStore{}, ComputedCondition{Name: "broken", SQL: "location IS"})
+	assert.ErrorContains(t, err, "invalid SQL in computed condition broken")
+}
+
+func TestComputedConditions(t *testing.T) {
+	registry, metadata := newGeometryTestRegistry(t)
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "id", Operator: OpGreaterThan, Value: int64(10)},
+			{Field: "near", Operator: OpMatches, Value: map[string]interface{}{"lng": 77.59, "lat": 12.97, "meters": 500.0}},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+	query, err := registry.buildSelectQuery(metadata.TableName, metadata, req)
+	require.NoError(t, err)
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM stores WHERE id > $1 AND "+
+		"(ST_DWithin(location, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography, $4))", sql)
+	assert.Equal(t, []interface{}{int64(10), 77.59, 12.97, 500.0}, args)
+
+	tests := []struct {
+		name string
+		cond Condition
+		want Violation
+	}{
+		{
+			name: "operator",
+			cond: Condition{Field: "near", Operator: OpEqual, Value: map[string]interface{}{}},
+			want: Violation{Code: CodeUnknownOperator, Path: "where[0].operator", Message: "computed condition near only supports operator MATCHES"},
+		},
+		{
+			name: "missing parameter",
+			cond: Condition{Field: "near", Operator: OpMatches, Value: map[string]interface{}{"lng": 77.59, "meters": 500.0}},
+			want: Violation{Code: CodeRequired, Path: "where[0].value.lat", Message: "missing parameter lat of computed condition near"},
+		},
+		{
+			name: "wrong type",
+			cond: Condition{Field: "near", Operator: OpMatches, Value: map[string]interface{}{"lng": 77.59, "lat": "north", "meters": 500.0}},
+			want: Violation{Code: CodeTypeMismatch, Path: "where[0].value.lat", Message: "invalid type for parameter lat: expected float64, got string"},
+		},
+		{
+			name: "unknown parameter",
+			cond: Condition{Field: "near", Operator: OpMatches, Value: map[string]interface{}{"lng": 77.59, "lat": 12.97, "meters": 500.0, "srid": 4326.0}},
+			want: Violation{Code: CodeUnexpectedValue, Path: "where[0].value.srid", Message: "computed condition near has no parameter srid"},
+		},
+		{
+			name: "matches on field",
+			cond: Condition{Field: "location", Operator: OpMatches, Value: map[string]interface{}{}},
+			want: Violation{Code: CodeUnknownOperator, Path: "where[0].operator", Message: "unsupported operator: MATCHES"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"id"}, Where: []Condition{tt.cond}}, metadata)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, []Violation{tt.want}, verr.Rejected)
+		})
+	}
+}
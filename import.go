@@ -0,0 +1,394 @@
+package sqld
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// ImportFormat is the format of an import source.
+type ImportFormat string
+
+const (
+	ImportCSV    ImportFormat = "csv"    // Comma-separated values with a header row
+	ImportNDJSON ImportFormat = "ndjson" // One JSON object per line
+)
+
+// ImportRequest describes how to read an import source into a model.
+type ImportRequest struct {
+	Format ImportFormat `json:"format"`
+
+	// Mapping maps source column names (CSV header names or NDJSON keys) to
+	// JSON field names of the model. Source columns missing from the mapping
+	// are ignored. Optional - if nil, source columns must be named like the fields.
+	Mapping map[string]string `json:"mapping,omitempty"`
+
+	// ConflictFields turns the import into an upsert: rows that conflict on
+	// these fields update the existing row instead of being inserted.
+	ConflictFields []string `json:"conflict_fields,omitempty"`
+
+	// BatchSize is the number of rows written at once.
+	// Optional - defaults to DefaultBulkBatchSize.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// ImportResult reports the outcome of an import. Rejected rows are skipped;
+// the Row of each RowError is the 1-based line number in the source.
+type ImportResult struct {
+	RowsAffected int64      `json:"rows_affected"`
+	Rejected     []RowError `json:"rejected,omitempty"`
+}
+
+// ExecuteImport streams rows from r into the table of T. Each row is mapped to
+// model fields using req.Mapping, converted to the field types and validated;
+// invalid rows are reported in ImportResult.Rejected and the valid rows are
+// written in batches of req.BatchSize.
+//
+// Each batch is committed on its own, so pass a transaction as db to make
+// the whole import atomic.
+//
+// CSV values are parsed according to the field type: empty values are NULL,
+// timestamps use RFC 3339 or YYYY-MM-DD, and array fields are written as JSON arrays.
+func ExecuteImport[T Model](ctx context.Context, db interface{}, r io.Reader, req ImportRequest) (ImportResult, error) {
+	return executeImportWith[T](ctx, defaultRegistry, db, r, req)
+}
+
+// executeImportWith implements ExecuteImport with the models and converters of
+// registry, reading the rows from src.
+func executeImportWith[T Model](ctx context.Context, registry *Registry, db interface{}, src io.Reader, req ImportRequest) (result ImportResult, err error) {
+	var model T
+	ctx, finish := registry.startWrite(ctx, "sqld.execute_import", model.TableName())
+	defer func() { finish(int(result.RowsAffected), err) }()
+
+	metadata, err := registry.getOrRegister(model)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return ImportResult{}, err
+	}
+
+	if req.BatchSize < 0 {
+		return ImportResult{}, fmt.Errorf("batch size must be non-negative")
+	}
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+
+	var conflictColumns []string
+	for _, name := range req.ConflictFields {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return ImportResult{}, fmt.Errorf("invalid conflict field: %s", name)
+		}
+		conflictColumns = append(conflictColumns, field.Name)
+	}
+	// Upserts keep the creation time of the rows they update
+	var createdColumns []string
+	for _, field := range metadata.Fields {
+		if field.AutoTimestamp == AutoCreate {
+			createdColumns = append(createdColumns, field.Name)
+		}
+	}
+
+	var next func() (int, map[string]interface{}, error)
+	switch req.Format {
+	case ImportCSV:
+		next, err = csvRows(src, req.Mapping, metadata)
+		if err != nil {
+			return ImportResult{}, err
+		}
+	case ImportNDJSON:
+		next = ndjsonRows(src, req.Mapping, metadata)
+	default:
+		return ImportResult{}, fmt.Errorf("unsupported import format: %q", req.Format)
+	}
+
+	var batch []map[string]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		columns, values, err := registry.bulkValues(metadata, batch)
+		if err != nil {
+			return err
+		}
+		// The query timeout covers the writing of a batch, not the reading of src
+		ctx, cancel, timeout := registry.queryContext(ctx, 0)
+		defer cancel()
+		var n int64
+		if len(conflictColumns) > 0 {
+			n, err = registry.upsertRows(ctx, db, model.TableName(), columns, values, conflictColumns, createdColumns, batchSize, timeout)
+		} else {
+			n, err = registry.writeRows(ctx, db, model.TableName(), columns, values, batchSize, timeout)
+		}
+		if err != nil {
+			return wrapDBError("failed to execute import", err)
+		}
+		result.RowsAffected += n
+		registry.invalidate(model.TableName())
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		line, row, err := next()
+		if err == io.EOF {
+			break
+		}
+		var rowErr RowError
+		if errors.As(err, &rowErr) {
+			result.Rejected = append(result.Rejected, rowErr)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if len(row) == 0 {
+			result.Rejected = append(result.Rejected, RowError{Row: line, Message: "row has no values"})
+			continue
+		}
+		if rowErrors := validateRow(line, row, metadata); len(rowErrors) > 0 {
+			result.Rejected = append(result.Rejected, rowErrors...)
+			continue
+		}
+
+		// A batch is written with the field set of its first row
+		if len(batch) > 0 && (len(batch) == batchSize || !sameFields(batch[0], row)) {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+		batch = append(batch, row)
+	}
+
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// csvRows reads the CSV header and returns a function yielding one mapped,
+// type-converted row per call, and io.EOF at the end.
+func csvRows(r io.Reader, mapping map[string]string, metadata ModelMetadata) (func() (int, map[string]interface{}, error), error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV input has no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	// fields[i] is the field of column i, or nil if the column is ignored
+	fields := make([]*Field, len(header))
+	for i, column := range header {
+		name := column
+		if mapping != nil {
+			var ok bool
+			if name, ok = mapping[column]; !ok {
+				continue
+			}
+		}
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return nil, fmt.Errorf("CSV column %q does not match any field", column)
+		}
+		fields[i] = &field
+	}
+
+	return func() (int, map[string]interface{}, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return 0, nil, RowError{Row: parseErr.Line, Message: parseErr.Err.Error()}
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := make(map[string]interface{}, len(record))
+		for i, text := range record {
+			if fields[i] == nil {
+				continue
+			}
+			value, err := parseCSVValue(*fields[i], text)
+			if err != nil {
+				return 0, nil, RowError{Row: line, Field: fields[i].JSONName, Message: err.Error()}
+			}
+			row[fields[i].JSONName] = value
+		}
+		return line, row, nil
+	}, nil
+}
+
+// ndjsonRows returns a function yielding one mapped row per non-empty line of r,
+// and io.EOF at the end. Strings are parsed for timestamp fields, since JSON has
+// no timestamp type; other values are kept as decoded.
+func ndjsonRows(r io.Reader, mapping map[string]string, metadata ModelMetadata) func() (int, map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), DefaultMaxRequestBytes)
+	line := 0
+
+	return func() (int, map[string]interface{}, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(text), &obj); err != nil {
+				return 0, nil, RowError{Row: line, Message: fmt.Sprintf("invalid JSON: %v", err)}
+			}
+			row := make(map[string]interface{}, len(obj))
+			for key, value := range obj {
+				name := key
+				if mapping != nil {
+					var ok bool
+					if name, ok = mapping[key]; !ok {
+						continue
+					}
+				}
+				if text, ok := value.(string); ok && IsTimeType(metadata.Fields[name].NormalizedType) {
+					ts, err := parseTimestamp(text)
+					if err != nil {
+						return 0, nil, RowError{Row: line, Field: name, Message: err.Error()}
+					}
+					value = ts
+				}
+				row[name] = value
+			}
+			return line, row, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read NDJSON: %w", err)
+		}
+		return 0, nil, io.EOF
+	}
+}
+
+// parseCSVValue converts the text of a CSV cell to a value of field's type.
+func parseCSVValue(field Field, text string) (interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	if field.Array != nil {
+		target := reflect.New(reflect.SliceOf(field.Array.ElementType))
+		if err := json.Unmarshal([]byte(text), target.Interface()); err != nil {
+			return nil, fmt.Errorf("expected a JSON array: %v", err)
+		}
+		return target.Elem().Interface(), nil
+	}
+
+	t := field.NormalizedType
+	if IsTimeType(t) {
+		return parseTimestamp(text)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return text, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", text)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsigned integer %q", text)
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return reflect.ValueOf(f).Convert(t).Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported field type %v for CSV import", field.Type)
+}
+
+// parseTimestamp parses an RFC 3339 timestamp or a YYYY-MM-DD date.
+func parseTimestamp(text string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if ts, err := time.Parse(layout, text); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", text)
+}
+
+// upsertRows sends values as multi-row INSERT ... ON CONFLICT statements.
+// Conflicting rows update every column but the conflict columns and
+// createdColumns, the autocreate timestamps; if there are none, they are skipped.
+// Each statement runs with runRaw, reporting ErrQueryTimeout after timeout.
+func (r *Registry) upsertRows(ctx context.Context, db interface{}, tableName string, columns []string, values [][]interface{}, conflictColumns, createdColumns []string, batchSize int, timeout time.Duration) (int64, error) {
+	batchSize = min(batchSize, maxBindParams/len(columns))
+
+	var updates []string
+	for _, column := range columns {
+		if !contains(conflictColumns, column) && !contains(createdColumns, column) {
+			updates = append(updates, column+" = EXCLUDED."+column)
+		}
+	}
+	suffix := "ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO NOTHING"
+	if len(updates) > 0 {
+		suffix = "ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	var total int64
+	for start := 0; start < len(values); start += batchSize {
+		end := min(start+batchSize, len(values))
+
+		builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Insert(tableName).
+			Columns(columns...).
+			Suffix(suffix)
+		for _, row := range values[start:end] {
+			builder = builder.Values(row...)
+		}
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return 0, err
+		}
+		err = r.runRaw(ctx, "sqld.exec", tableName, timeout, query, args, func(ctx context.Context) error {
+			n, err := execStatement(ctx, db, query, args...)
+			total += n
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
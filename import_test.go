@@ -0,0 +1,103 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteImportCSV(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+
+	input := "Full Name,Age,Ignored\n" +
+		"Jane,30,x\n" +
+		"John,thirty,x\n" +
+		"Joe,,x\n"
+
+	result, err := ExecuteImport[BuilderTestModel](context.Background(), db, strings.NewReader(input), ImportRequest{
+		Format:  ImportCSV,
+		Mapping: map[string]string{"Full Name": "name", "Age": "age"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.RowsAffected)
+	assert.Equal(t, []RowError{
+		{Row: 3, Field: "age", Message: `invalid integer "thirty"`},
+		{Row: 4, Field: "age", Message: "field age cannot be NULL"},
+	}, result.Rejected)
+
+	assert.Equal(t, []string{"INSERT INTO test_models (age,name) VALUES ($1,$2)"}, fake.statements)
+	assert.Equal(t, [][]driver.Value{{int64(30), "Jane"}}, fake.args)
+}
+
+func TestExecuteImportNDJSONUpsert(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+
+	input := `{"id": 1, "name": "Jane"}` + "\n" +
+		"\n" +
+		`{"id": 2, "name": 7}` + "\n" +
+		`not json` + "\n" +
+		`{"id": 3, "email": "joe@example.com"}` + "\n"
+
+	result, err := ExecuteImport[BuilderTestModel](context.Background(), db, strings.NewReader(input), ImportRequest{
+		Format:         ImportNDJSON,
+		ConflictFields: []string{"id"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.RowsAffected)
+	require.Len(t, result.Rejected, 2)
+	assert.Equal(t, RowError{Row: 3, Field: "name", Message: "invalid type for field name: expected string, got float64"}, result.Rejected[0])
+	assert.Equal(t, 4, result.Rejected[1].Row)
+
+	// Rows with different field sets are written in separate batches
+	assert.Equal(t, []string{
+		"INSERT INTO test_models (id,name) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+		"INSERT INTO test_models (email,id) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email",
+	}, fake.statements)
+}
+
+func TestExecuteImportUpsertKeepsCreationTime(t *testing.T) {
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+
+	_, err := ExecuteImport[AutoTimestampTestModel](context.Background(), db, strings.NewReader(`{"id": 1, "name": "Jane"}`), ImportRequest{
+		Format:         ImportNDJSON,
+		ConflictFields: []string{"id"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"INSERT INTO auto_timestamp_test_models (created_at,id,name,updated_at) VALUES ($1,$2,$3,$4) " +
+			"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at",
+	}, fake.statements)
+}
+
+func TestExecuteImportErrors(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	db, _ := newFakeDB(t, nil)
+
+	tests := []struct {
+		name    string
+		input   string
+		req     ImportRequest
+		wantErr string
+	}{
+		{"unknown format", "", ImportRequest{Format: "xml"}, `unsupported import format: "xml"`},
+		{"unknown CSV column", "name,title\n", ImportRequest{Format: ImportCSV}, `CSV column "title" does not match any field`},
+		{"empty CSV", "", ImportRequest{Format: ImportCSV}, "CSV input has no header row"},
+		{"unknown conflict field", "", ImportRequest{Format: ImportCSV, ConflictFields: []string{"title"}}, "invalid conflict field: title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteImport[BuilderTestModel](context.Background(), db, strings.NewReader(tt.input), tt.req)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
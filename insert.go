@@ -0,0 +1,196 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// buildInsertQuery creates an INSERT statement for the model, converting the
+// JSON field names in req.Values to column names and passing the values
+// through the registered converters.
+func (r *Registry) buildInsertQuery(tableName string, metadata ModelMetadata, req InsertRequest) (squirrel.InsertBuilder, error) {
+	values := make(map[string]interface{}, len(req.Values))
+	for name, value := range req.Values {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return squirrel.InsertBuilder{}, fmt.Errorf("invalid field in insert: %s", name)
+		}
+		converted, err := r.convertSetValue(field, value)
+		if err != nil {
+			return squirrel.InsertBuilder{}, fmt.Errorf("invalid value for field %s: %w", name, err)
+		}
+		values[field.Name] = converted
+	}
+
+	// Fill auto-managed timestamps the request left unset
+	for name, field := range metadata.Fields {
+		if field.AutoTimestamp != AutoNone && isUnsetTimestamp(req.Values[name]) {
+			values[field.Name] = squirrel.Expr("now()")
+		}
+	}
+
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Insert(tableName).
+		SetMap(values)
+
+	if len(req.Returning) > 0 {
+		columns, err := returningColumns(metadata, req.Returning)
+		if err != nil {
+			return squirrel.InsertBuilder{}, err
+		}
+		builder = builder.Suffix("RETURNING " + strings.Join(columns, ", "))
+	}
+	return builder, nil
+}
+
+// isUnsetTimestamp reports whether value leaves an auto-managed timestamp unset:
+// it is missing, nil, or a zero time such as an uninitialized model field.
+func isUnsetTimestamp(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return v.IsZero()
+}
+
+// returningColumns converts JSON field names to column names for a RETURNING clause.
+// SelectAll expands to every column of the model.
+func returningColumns(metadata ModelMetadata, returning []string) ([]string, error) {
+	if len(returning) == 1 && returning[0] == SelectAll {
+		columns := make([]string, 0, len(metadata.Fields))
+		for _, field := range metadata.Fields {
+			columns = append(columns, selectColumn(field))
+		}
+		sort.Strings(columns)
+		return columns, nil
+	}
+
+	columns := make([]string, len(returning))
+	for i, jsonName := range returning {
+		field, ok := metadata.Fields[jsonName]
+		if !ok {
+			return nil, fmt.Errorf("invalid field in returning: %s", jsonName)
+		}
+		columns[i] = selectColumn(field)
+	}
+	return columns, nil
+}
+
+// returningResults maps rows produced by a RETURNING clause from column names to
+// JSON field names, applying registered scanners as Execute does.
+func (r *Registry) returningResults(metadata ModelMetadata, rows []map[string]interface{}) ([]QueryResult, error) {
+	keys := make([]string, 0, len(metadata.Fields))
+	for jsonName := range metadata.Fields {
+		keys = append(keys, jsonName)
+	}
+	plan := r.newResultPlan(metadata, keys, nil, r.getResultLocation())
+	results := make([]QueryResult, len(rows))
+	for i, row := range rows {
+		result, err := plan.mapRow(row)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// ExecuteInsert validates req against the model metadata and inserts a single row.
+// If req.Returning is set, the requested fields of the inserted row are returned
+// in the same round trip. Auto-managed timestamp fields (see AutoTimestamp)
+// that req leaves unset are filled with now().
+//
+// Example:
+//
+//	resp, err := sqld.ExecuteInsert[Employee](ctx, db, sqld.InsertRequest{
+//	    Values: map[string]interface{}{
+//	        "first_name": "Jane",
+//	        "department": "Engineering",
+//	        "salary":     85000.0,
+//	    },
+//	    Returning: []string{"id", "created_at"},
+//	})
+func ExecuteInsert[T Model](ctx context.Context, db interface{}, req InsertRequest) (InsertResponse, error) {
+	return executeInsertWith[T](ctx, defaultRegistry, db, req)
+}
+
+// executeInsertWith implements ExecuteInsert with the models and converters of r.
+func executeInsertWith[T Model](ctx context.Context, r *Registry, db interface{}, req InsertRequest) (resp InsertResponse, err error) {
+	var model T
+	ctx, finish := r.startWrite(ctx, "sqld.execute_insert", model.TableName())
+	defer func() { finish(int(resp.RowsAffected), err) }()
+
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return InsertResponse{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return InsertResponse{}, err
+	}
+
+	validator := BasicValidator{}
+	if err := validator.ValidateInsert(req, metadata); err != nil {
+		return InsertResponse{}, fmt.Errorf("failed to validate insert: %w", err)
+	}
+
+	builder, err := r.buildInsertQuery(model.TableName(), metadata, req)
+	if err != nil {
+		return InsertResponse{}, fmt.Errorf("failed to build insert: %w", err)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return InsertResponse{}, fmt.Errorf("failed to generate sql: %w", err)
+	}
+
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+
+	if len(req.Returning) > 0 {
+		var rows []map[string]interface{}
+		err = r.runRaw(ctx, "sqld.exec", model.TableName(), timeout, query, args, func(ctx context.Context) error {
+			if err := selectAll(ctx, db, &rows, query, args...); err != nil {
+				return wrapDBError("failed to execute insert", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return InsertResponse{}, err
+		}
+		r.invalidate(model.TableName())
+		returning, err := r.returningResults(metadata, rows)
+		if err != nil {
+			return InsertResponse{}, err
+		}
+		return InsertResponse{
+			RowsAffected: int64(len(rows)),
+			Returning:    returning,
+		}, nil
+	}
+
+	var rowsAffected int64
+	err = r.runRaw(ctx, "sqld.exec", model.TableName(), timeout, query, args, func(ctx context.Context) (err error) {
+		rowsAffected, err = execStatement(ctx, db, query, args...)
+		if err != nil {
+			return wrapDBError("failed to execute insert", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return InsertResponse{}, err
+	}
+	r.invalidate(model.TableName())
+
+	return InsertResponse{RowsAffected: rowsAffected}, nil
+}
@@ -0,0 +1,196 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInsertQuery(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	var model BuilderTestModel
+	metadata, err := getModelMetadata(model)
+	require.NoError(t, err)
+
+	got, err := defaultRegistry.buildInsertQuery(model.TableName(), metadata, InsertRequest{
+		Values: map[string]interface{}{
+			"name":   "Jane",
+			"age":    30,
+			"active": true,
+		},
+	})
+	require.NoError(t, err)
+
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test_models (active,age,name) VALUES ($1,$2,$3)", sql)
+	assert.Equal(t, []interface{}{true, 30, "Jane"}, args)
+}
+
+func TestValidateInsert(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	require.NoError(t, Register[ArrayTestModel]())
+
+	builderMetadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+	arrayMetadata, err := getModelMetadata(ArrayTestModel{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		metadata ModelMetadata
+		values   map[string]interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "valid values",
+			metadata: builderMetadata,
+			values:   map[string]interface{}{"name": "Jane", "age": 30, "salary": 1000.5},
+		},
+		{
+			name:     "nil inserts null",
+			metadata: builderMetadata,
+			values:   map[string]interface{}{"nullable": nil},
+		},
+		{
+			name:     "valid array value",
+			metadata: arrayMetadata,
+			values:   map[string]interface{}{"reporting_to": []int64{1, 2}},
+		},
+		{
+			name:     "empty values",
+			metadata: builderMetadata,
+			values:   map[string]interface{}{},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown field",
+			metadata: builderMetadata,
+			values:   map[string]interface{}{"invalid_field": 1},
+			wantErr:  true,
+		},
+		{
+			name:     "wrong type",
+			metadata: builderMetadata,
+			values:   map[string]interface{}{"age": "thirty"},
+			wantErr:  true,
+		},
+		{
+			name:     "scalar for array field",
+			metadata: arrayMetadata,
+			values:   map[string]interface{}{"reporting_to": int64(1)},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateInsert(InsertRequest{Values: tt.values}, tt.metadata)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExecuteInsertUnsupportedDB(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	var mockDB *MockDB
+	_, err := ExecuteInsert[BuilderTestModel](context.Background(), mockDB, InsertRequest{
+		Values: map[string]interface{}{"name": "Jane"},
+	})
+	assert.EqualError(t, err, "unsupported database type: *sqld.MockDB")
+}
+
+func TestBuildInsertQueryWithReturning(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	var model BuilderTestModel
+	metadata, err := getModelMetadata(model)
+	require.NoError(t, err)
+
+	got, err := defaultRegistry.buildInsertQuery(model.TableName(), metadata, InsertRequest{
+		Values:    map[string]interface{}{"name": "Jane"},
+		Returning: []string{"id", "name"},
+	})
+	require.NoError(t, err)
+
+	sql, _, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test_models (name) VALUES ($1) RETURNING id, name", sql)
+
+	err = BasicValidator{}.ValidateInsert(InsertRequest{
+		Values:    map[string]interface{}{"name": "Jane"},
+		Returning: []string{"invalid_field"},
+	}, metadata)
+	assert.EqualError(t, err, "invalid field in returning: invalid_field")
+
+	rows, err := defaultRegistry.returningResults(metadata, []map[string]interface{}{{"id": int64(7), "name": "Jane"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(7), "name": "Jane"}}, rows)
+
+	// Returned values go through registered scanners, as in Execute
+	jobs, err := buildModelMetadata(IntervalTestJob{}, false)
+	require.NoError(t, err)
+	rows, err = defaultRegistry.returningResults(jobs, []map[string]interface{}{{"id": int64(1), "every": "01:30:00"}})
+	require.NoError(t, err)
+	assert.Equal(t, []QueryResult{{"id": int64(1), "every": 90 * time.Minute}}, rows)
+}
+
+type AutoTimestampTestModel struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" sqld:"autocreate"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" sqld:"autoupdate"`
+}
+
+func (AutoTimestampTestModel) TableName() string {
+	return "auto_timestamp_test_models"
+}
+
+func TestBuildInsertQueryAutoTimestamps(t *testing.T) {
+	metadata, err := getModelMetadata(AutoTimestampTestModel{})
+	require.NoError(t, err)
+	assert.Equal(t, AutoCreate, metadata.Fields["created_at"].AutoTimestamp)
+	assert.Equal(t, AutoUpdate, metadata.Fields["updated_at"].AutoTimestamp)
+
+	got, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"name": "Jane"},
+	})
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO auto_timestamp_test_models (created_at,name,updated_at) VALUES (now(),$1,now())", sql)
+	assert.Equal(t, []interface{}{"Jane"}, args)
+
+	// An explicit value is kept
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err = defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"name": "Jane", "created_at": createdAt},
+	})
+	require.NoError(t, err)
+	sql, args, err = got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO auto_timestamp_test_models (created_at,name,updated_at) VALUES ($1,$2,now())", sql)
+	assert.Equal(t, []interface{}{createdAt, "Jane"}, args)
+}
+
+type InvalidAutoTimestampTestModel struct {
+	Name string `json:"name" db:"name" sqld:"autocreate"`
+}
+
+func (InvalidAutoTimestampTestModel) TableName() string {
+	return "invalid_auto_timestamp_test_models"
+}
+
+func TestAutoTimestampRequiresTimeField(t *testing.T) {
+	err := NewRegistry().Register(InvalidAutoTimestampTestModel{})
+	assert.ErrorContains(t, err, `field "Name" has sqld:"autocreate" but is not a timestamp`)
+}
@@ -0,0 +1,75 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Interval field types. Both hold interval columns and are validated as
+// time.Duration: condition and insert values are time.Duration values or
+// strings, either Go durations such as "1h30m" or PostgreSQL intervals such as
+// "1 day 02:00:00".
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	intervalType = reflect.TypeOf(pgtype.Interval{})
+)
+
+// daysPerMonth is the length of an interval month when it is converted to a
+// duration, as PostgreSQL does when it compares intervals.
+const daysPerMonth = 30
+
+// durationToInterval returns d as an interval of microseconds.
+func durationToInterval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{Microseconds: d.Microseconds(), Valid: true}
+}
+
+// intervalToDuration returns the length of iv, counting days as 24 hours and
+// months as 30 days.
+func intervalToDuration(iv pgtype.Interval) time.Duration {
+	days := int64(iv.Days) + int64(iv.Months)*daysPerMonth
+	return time.Duration(iv.Microseconds)*time.Microsecond + time.Duration(days)*24*time.Hour
+}
+
+// convertInterval is the converter for interval fields. It binds values as
+// pgtype.Interval, since pgx cannot encode a time.Duration as an interval.
+func convertInterval(v interface{}) (interface{}, error) {
+	switch d := v.(type) {
+	case pgtype.Interval:
+		return d, nil
+	case time.Duration:
+		return durationToInterval(d), nil
+	case string:
+		if parsed, err := time.ParseDuration(d); err == nil {
+			return durationToInterval(parsed), nil
+		}
+		var iv pgtype.Interval
+		if err := iv.Scan(d); err != nil {
+			return nil, fmt.Errorf("invalid interval %q", d)
+		}
+		return iv, nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to an interval", v)
+}
+
+// durationScanner scans interval results, such as pgtype.Interval or their
+// text form, into a time.Duration, or nil for NULL.
+type durationScanner struct {
+	iv pgtype.Interval
+}
+
+func (s *durationScanner) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	return s.iv.Scan(src)
+}
+
+func (s *durationScanner) Value() interface{} {
+	if !s.iv.Valid {
+		return nil
+	}
+	return intervalToDuration(s.iv)
+}
@@ -0,0 +1,93 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type IntervalTestJob struct {
+	ID      int64           `json:"id" db:"id"`
+	Every   time.Duration   `json:"every" db:"every"`
+	Timeout pgtype.Interval `json:"timeout" db:"timeout"`
+}
+
+func (IntervalTestJob) TableName() string {
+	return "scheduled_jobs"
+}
+
+func TestIntervalConditions(t *testing.T) {
+	metadata, err := buildModelMetadata(IntervalTestJob{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, durationType, metadata.Fields["every"].NormalizedType)
+	assert.Equal(t, durationType, metadata.Fields["timeout"].NormalizedType)
+
+	got, err := buildQuery[IntervalTestJob](QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "every", Operator: OpLessThanOrEqual, Value: 90 * time.Minute},
+			{Field: "timeout", Operator: OpGreaterThan, Value: "1 day 02:00:00"},
+			{Field: "every", Operator: OpEqual, Value: "15m"},
+		},
+	})
+	require.NoError(t, err)
+	_, args, err := got.ToSql()
+	require.NoError(t, err)
+	// squirrel binds driver.Valuer values by calling Value()
+	assert.Equal(t, []interface{}{"01:30:00", "1 day 02:00:00", "00:15:00"}, args)
+
+	tests := []struct {
+		name string
+		cond Condition
+	}{
+		{name: "number", cond: Condition{Field: "every", Operator: OpEqual, Value: 60}},
+		{name: "bool", cond: Condition{Field: "timeout", Operator: OpEqual, Value: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"id"}, Where: []Condition{tt.cond}}, metadata)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, CodeTypeMismatch, verr.Rejected[0].Code)
+		})
+	}
+
+	_, err = buildQuery[IntervalTestJob](QueryRequest{
+		Select: []string{"id"},
+		Where:  []Condition{{Field: "every", Operator: OpEqual, Value: "soon"}},
+	})
+	assert.EqualError(t, err, `invalid value for field every: invalid interval "soon"`)
+}
+
+func TestIntervalInsertAndResults(t *testing.T) {
+	metadata, err := buildModelMetadata(IntervalTestJob{}, false)
+	require.NoError(t, err)
+
+	insert, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, InsertRequest{
+		Values: map[string]interface{}{"every": 2 * time.Hour, "timeout": "30s"},
+	})
+	require.NoError(t, err)
+	_, args, err := insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		pgtype.Interval{Microseconds: (2 * time.Hour).Microseconds(), Valid: true},
+		pgtype.Interval{Microseconds: (30 * time.Second).Microseconds(), Valid: true},
+	}, args)
+
+	db, _ := newFakeDB(t, []string{"id", "every"},
+		[]driver.Value{int64(1), "01:30:00"},
+		[]driver.Value{int64(2), []byte("1 mon 2 days 00:00:01")},
+		[]driver.Value{int64(3), nil},
+	)
+	resp, err := Execute[IntervalTestJob](context.Background(), db, QueryRequest{Select: []string{"id", "every"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 3)
+	assert.Equal(t, 90*time.Minute, resp.Data[0]["every"])
+	assert.Equal(t, 32*24*time.Hour+time.Second, resp.Data[1]["every"])
+	assert.Nil(t, resp.Data[2]["every"])
+}
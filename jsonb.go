@@ -0,0 +1,78 @@
+package sqld
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// rawJSONType is the normalized type of JSON fields.
+var rawJSONType = reflect.TypeOf(json.RawMessage{})
+
+// isJSONType reports whether fields of type t hold a json or jsonb column:
+// json.RawMessage, or a map with string keys such as map[string]interface{}.
+func isJSONType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == rawJSONType || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// isJSONOperator reports whether op can be used on JSON fields. They are
+// compared as whole documents, or tested for containment with @>.
+func isJSONOperator(op Operator) bool {
+	switch op {
+	case OpEqual, OpNotEqual, OpContains, OpIsNull, OpIsNotNull:
+		return true
+	}
+	return false
+}
+
+// encodeJSONValue returns the JSON text bound for a value of a JSON field.
+// json.RawMessage values must already be valid JSON; anything else is marshaled,
+// so a condition on a jsonb column can use a plain map, slice or scalar.
+func encodeJSONValue(value interface{}) (interface{}, error) {
+	if raw, ok := value.(json.RawMessage); ok {
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("invalid JSON")
+		}
+		return string(raw), nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// jsonScanner decodes JSON results that arrive as text, as they do from
+// database/sql drivers, so results hold decoded JSON instead of raw bytes.
+// Values that are already decoded, as pgx does for json and jsonb columns,
+// are kept.
+type jsonScanner struct {
+	v interface{}
+}
+
+func (s *jsonScanner) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		s.v = src
+		return nil
+	}
+	if data == nil {
+		s.v = nil
+		return nil
+	}
+	return json.Unmarshal(data, &s.v)
+}
+
+func (s *jsonScanner) Value() interface{} {
+	return s.v
+}
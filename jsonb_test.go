@@ -0,0 +1,118 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type JSONTestModel struct {
+	ID       int64                  `json:"id" db:"id"`
+	Settings map[string]interface{} `json:"settings" db:"settings"`
+	Payload  json.RawMessage        `json:"payload" db:"payload"`
+}
+
+func (JSONTestModel) TableName() string {
+	return "json_test_models"
+}
+
+func TestJSONFieldMetadata(t *testing.T) {
+	metadata, err := buildModelMetadata(JSONTestModel{}, false)
+	require.NoError(t, err)
+
+	for _, name := range []string{"settings", "payload"} {
+		field := metadata.Fields[name]
+		assert.True(t, field.JSON, name)
+		assert.Nil(t, field.Array, name)
+		assert.Equal(t, rawJSONType, field.NormalizedType, name)
+		assert.True(t, field.Nullable, name)
+	}
+	assert.False(t, metadata.Fields["id"].JSON)
+}
+
+func TestJSONConditions(t *testing.T) {
+	got, err := buildQuery[JSONTestModel](QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "settings", Operator: OpContains, Value: map[string]interface{}{"theme": "dark"}},
+			{Field: "payload", Operator: OpEqual, Value: json.RawMessage(`{"a": 1}`)},
+			{Field: "payload", Operator: OpNotEqual, Value: []interface{}{1, 2}},
+			{Field: "settings", Operator: OpIsNotNull},
+		},
+	})
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM json_test_models WHERE settings @> $1 AND payload = $2 AND payload <> $3 AND settings IS NOT NULL", sql)
+	assert.Equal(t, []interface{}{`{"theme":"dark"}`, `{"a": 1}`, `[1,2]`}, args)
+
+	tests := []struct {
+		name     string
+		cond     Condition
+		wantCode ViolationCode
+	}{
+		{
+			name:     "ordering operator",
+			cond:     Condition{Field: "settings", Operator: OpGreaterThan, Value: map[string]interface{}{}},
+			wantCode: CodeJSONField,
+		},
+		{
+			name:     "array operator",
+			cond:     Condition{Field: "payload", Operator: OpOverlap, Value: []interface{}{1}},
+			wantCode: CodeJSONField,
+		},
+		{
+			name:     "invalid raw JSON",
+			cond:     Condition{Field: "payload", Operator: OpEqual, Value: json.RawMessage(`{`)},
+			wantCode: CodeTypeMismatch,
+		},
+		{
+			name:     "unencodable value",
+			cond:     Condition{Field: "settings", Operator: OpContains, Value: func() {}},
+			wantCode: CodeTypeMismatch,
+		},
+	}
+
+	metadata, err := buildModelMetadata(JSONTestModel{}, false)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"id"}, Where: []Condition{tt.cond}}, metadata)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.wantCode, verr.Rejected[0].Code)
+		})
+	}
+}
+
+func TestJSONInsertAndResults(t *testing.T) {
+	metadata, err := buildModelMetadata(JSONTestModel{}, false)
+	require.NoError(t, err)
+
+	req := InsertRequest{Values: map[string]interface{}{
+		"settings": map[string]interface{}{"theme": "dark"},
+		"payload":  json.RawMessage(`[1, 2]`),
+	}}
+	require.NoError(t, BasicValidator{}.ValidateInsert(req, metadata))
+	insert, err := defaultRegistry.buildInsertQuery(metadata.TableName, metadata, req)
+	require.NoError(t, err)
+	_, args, err := insert.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{`[1, 2]`, `{"theme":"dark"}`}, args)
+
+	db, _ := newFakeDB(t, []string{"id", "settings", "payload"},
+		[]driver.Value{int64(1), []byte(`{"theme": "dark", "size": 2}`), "[1, 2]"},
+		[]driver.Value{int64(2), nil, nil},
+	)
+	resp, err := Execute[JSONTestModel](context.Background(), db, QueryRequest{Select: []string{"id", "settings", "payload"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+	assert.Equal(t, map[string]interface{}{"theme": "dark", "size": float64(2)}, resp.Data[0]["settings"])
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, resp.Data[0]["payload"])
+	assert.Nil(t, resp.Data[1]["settings"])
+	assert.Nil(t, resp.Data[1]["payload"])
+}
@@ -0,0 +1,114 @@
+package sqld
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevel is the severity of a log entry of sqld.
+type LogLevel int
+
+// Levels of log entries, from the least to the most severe.
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Logger receives the log entries of sqld. attrs alternate keys, which are
+// strings, and values, as the arguments of slog.Logger.Log do. Log is called
+// from the goroutine running the query, so implementations must be safe for
+// concurrent use. NewSlogLogger adapts a *slog.Logger.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, attrs ...interface{})
+}
+
+// NewSlogLogger returns a Logger writing to logger, or to slog.Default if
+// logger is nil.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return slogLogger{logger: logger}
+}
+
+// slogLogger is a Logger writing to a *slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(ctx context.Context, level LogLevel, msg string, attrs ...interface{}) {
+	var slogLevel slog.Level
+	switch level {
+	case LogDebug:
+		slogLevel = slog.LevelDebug
+	case LogInfo:
+		slogLevel = slog.LevelInfo
+	case LogWarn:
+		slogLevel = slog.LevelWarn
+	default:
+		slogLevel = slog.LevelError
+	}
+	l.logger.Log(ctx, slogLevel, msg, attrs...)
+}
+
+// UseLogger makes the default registry log to logger. See Registry.UseLogger.
+func UseLogger(logger Logger) {
+	defaultRegistry.UseLogger(logger)
+}
+
+// UseLogger makes r log to logger. sqld logs nothing without a logger, and
+// logs the SQL of queries only for calls whose context comes from
+// WithSQLLogging. Configure sets the logger too.
+func (r *Registry) UseLogger(logger Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = logger
+}
+
+// sqlLoggingKey is the context key set by WithSQLLogging.
+type sqlLoggingKey struct{}
+
+// WithSQLLogging returns a context that makes Execute, ExecuteMany, the raw
+// functions and the writes, such as ExecuteInsert, log the SQL and the
+// arguments of the statements they run at LogInfo level. Since arguments may hold personal data, SQL logging is turned
+// on per call, for instance while debugging a single request:
+//
+//	resp, err := sqld.Execute[Employee](sqld.WithSQLLogging(ctx), db, req)
+func WithSQLLogging(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sqlLoggingKey{}, true)
+}
+
+// log logs msg with attrs at level to the logger of r, if any.
+func (r *Registry) log(ctx context.Context, level LogLevel, msg string, attrs ...interface{}) {
+	r.mu.RLock()
+	logger := r.logger
+	r.mu.RUnlock()
+	if logger != nil {
+		logger.Log(ctx, level, msg, attrs...)
+	}
+}
+
+// logSQL logs query, run on table with args, if ctx comes from WithSQLLogging.
+func (r *Registry) logSQL(ctx context.Context, table, query string, args []interface{}) {
+	if on, _ := ctx.Value(sqlLoggingKey{}).(bool); !on {
+		return
+	}
+	r.log(ctx, LogInfo, "sqld query", "table", table, "sql", query, "args", args)
+}
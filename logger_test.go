@@ -0,0 +1,49 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	logger.Log(context.Background(), LogInfo, "filtered out")
+	logger.Log(context.Background(), LogWarn, "slow", "table", "accounts")
+	logger.Log(context.Background(), LogError, "failed", "rows", 2)
+	assert.Equal(t, "level=WARN msg=slow table=accounts\nlevel=ERROR msg=failed rows=2\n", buf.String())
+}
+
+func TestLogSQL(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewRegistry()
+
+	// Without a logger nothing is logged
+	registry.logSQL(WithSQLLogging(context.Background()), "accounts", "SELECT 1", nil)
+
+	registry.UseLogger(NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	registry.logSQL(context.Background(), "accounts", "SELECT 1", nil)
+	assert.Empty(t, buf.String())
+
+	registry.logSQL(WithSQLLogging(context.Background()), "accounts", "SELECT id FROM accounts WHERE id = $1", []interface{}{7})
+	assert.Contains(t, buf.String(), `msg="sqld query" table=accounts sql="SELECT id FROM accounts WHERE id = $1" args=[7]`)
+}
+
+func TestLogLevelString(t *testing.T) {
+	assert.Equal(t, "DEBUG", LogDebug.String())
+	assert.Equal(t, "ERROR", LogError.String())
+	assert.Equal(t, "UNKNOWN", LogLevel(9).String())
+}
@@ -0,0 +1,106 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaterializedViewModel is implemented by models backed by a materialized view
+// rather than a table. Such models can be queried like any other, but inserts,
+// imports and soft deletes are rejected, and RefreshMaterializedView refreshes
+// their data:
+//
+//	func (SalesSummary) MaterializedView() bool { return true }
+type MaterializedViewModel interface {
+	Model
+	MaterializedView() bool
+}
+
+// ErrMaterializedView is returned when writing to a model backed by a materialized view.
+var ErrMaterializedView = errors.New("model is a materialized view")
+
+// RefreshMaterializedView refreshes the materialized view of model with the
+// default registry. See Registry.RefreshMaterializedView.
+func RefreshMaterializedView(ctx context.Context, db interface{}, model Model, concurrently bool) error {
+	return defaultRegistry.RefreshMaterializedView(ctx, db, model, concurrently)
+}
+
+// RefreshMaterializedView runs REFRESH MATERIALIZED VIEW for model, which must
+// implement MaterializedViewModel. With concurrently set, the view is refreshed
+// without locking out readers; PostgreSQL then requires a unique index on it.
+//
+// The time of the refresh is recorded, and responses of Execute on the model
+// report it in QueryResponse.AsOf. Refreshes made outside sqld, by another
+// process or a scheduled job, are not seen. Invalidation handlers are called
+// for the view, like after a write.
+func (r *Registry) RefreshMaterializedView(ctx context.Context, db interface{}, model Model, concurrently bool) (err error) {
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	ctx, finish := r.startWrite(ctx, "sqld.refresh_materialized_view", metadata.TableName)
+	defer func() { finish(0, err) }()
+	if !metadata.MaterializedView {
+		return fmt.Errorf("model %s is not a materialized view", metadata.TableName)
+	}
+	for _, part := range strings.Split(metadata.TableName, ".") {
+		if !identifierRegex.MatchString(part) {
+			return fmt.Errorf("invalid materialized view name %q", metadata.TableName)
+		}
+	}
+
+	query := "REFRESH MATERIALIZED VIEW "
+	if concurrently {
+		query += "CONCURRENTLY "
+	}
+	query += metadata.TableName
+	// The refresh time is taken before the statement: rows committed while it
+	// runs may be missing from the view
+	started := time.Now()
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+	err = r.runRaw(ctx, "sqld.exec", metadata.TableName, timeout, query, nil, func(ctx context.Context) error {
+		if _, err := execStatement(ctx, db, query); err != nil {
+			return wrapDBError("failed to refresh materialized view", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.refreshedAt[metadata.TableName] = started
+	r.mu.Unlock()
+	r.invalidate(metadata.TableName)
+	return nil
+}
+
+// refreshTime returns when the materialized view tableName was last refreshed
+// through r, or nil if it was not.
+func (r *Registry) refreshTime(tableName string) *time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	refreshed, ok := r.refreshedAt[tableName]
+	if !ok {
+		return nil
+	}
+	return &refreshed
+}
+
+// checkWritable returns ErrMaterializedView if metadata describes a materialized view.
+func checkWritable(metadata ModelMetadata) error {
+	if metadata.MaterializedView {
+		return fmt.Errorf("%w: %s cannot be written to", ErrMaterializedView, metadata.TableName)
+	}
+	return nil
+}
+
+// isMaterializedView reports whether model declares it is backed by a materialized view.
+func isMaterializedView(model Model) bool {
+	view, ok := model.(MaterializedViewModel)
+	return ok && view.MaterializedView()
+}
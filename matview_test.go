@@ -0,0 +1,60 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SalesSummary struct {
+	Region string  `json:"region" db:"region"`
+	Total  float64 `json:"total" db:"total"`
+}
+
+func (SalesSummary) TableName() string { return "reporting.sales_summary" }
+
+func (SalesSummary) MaterializedView() bool { return true }
+
+func TestRefreshMaterializedView(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	var invalidated []string
+	OnInvalidate(func(table string) { invalidated = append(invalidated, table) })
+
+	db, fake := newFakeDB(t, []string{"region", "total"}, []driver.Value{"EU", 10.5})
+	resp, err := Execute[SalesSummary](context.Background(), db, QueryRequest{Select: []string{"region", "total"}})
+	require.NoError(t, err)
+	assert.Nil(t, resp.AsOf)
+
+	require.NoError(t, RefreshMaterializedView(context.Background(), db, SalesSummary{}, true))
+	assert.Equal(t, "REFRESH MATERIALIZED VIEW CONCURRENTLY reporting.sales_summary", fake.statements[1])
+	assert.Equal(t, []string{"reporting.sales_summary"}, invalidated)
+
+	resp, err = Execute[SalesSummary](context.Background(), db, QueryRequest{Select: []string{"region", "total"}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.AsOf)
+
+	err = RefreshMaterializedView(context.Background(), db, TestResult{}, false)
+	assert.EqualError(t, err, "model test_results is not a materialized view")
+}
+
+func TestMaterializedViewRejectsWrites(t *testing.T) {
+	db, fake := newFakeDB(t, nil)
+
+	_, err := ExecuteInsert[SalesSummary](context.Background(), db, InsertRequest{
+		Values: map[string]interface{}{"region": "EU", "total": 1.0},
+	})
+	assert.ErrorIs(t, err, ErrMaterializedView)
+	assert.EqualError(t, err, "model is a materialized view: reporting.sales_summary cannot be written to")
+
+	_, err = ExecuteBulkInsert[SalesSummary](context.Background(), db, BulkInsertRequest{
+		Rows: []map[string]interface{}{{"region": "EU", "total": 1.0}},
+	})
+	assert.ErrorIs(t, err, ErrMaterializedView)
+	assert.Empty(t, fake.statements)
+}
@@ -0,0 +1,76 @@
+package sqld
+
+import (
+	"time"
+)
+
+// Operations reported in QueryStats.Operation.
+const (
+	OperationExecute = "execute" // A query run with Execute
+	OperationBatch   = "batch"   // A query run with ExecuteMany
+	OperationRaw     = "raw"     // A query run with ExecuteRaw or another raw function
+	OperationWrite   = "write"   // A write of a model, such as ExecuteInsert or ExecuteImport
+)
+
+// Metrics receives measurements of the queries run with a registry, so they
+// can be exported to a monitoring system. PrometheusMetrics is a ready-made
+// implementation. ObserveQuery is called once per query, after it succeeded or
+// failed, from the goroutine that ran it; implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	ObserveQuery(stats QueryStats)
+}
+
+// QueryStats describes a query run by sqld. The queries of an ExecuteMany
+// batch share the duration and the error of the batch.
+type QueryStats struct {
+	Model     string        // Table name of the model queried, of the result model of raw queries, or written to
+	Operation string        // OperationExecute, OperationBatch, OperationRaw or OperationWrite
+	Duration  time.Duration // Time from the call to its return
+	Rows      int           // Rows returned, or affected by a write or ExecuteRawExec
+	CacheHit  bool          // The SQL of the structured query came from the query cache
+	Err       error         // Error of the query, nil if it succeeded
+}
+
+// UseMetrics makes the default registry report its queries to m. See
+// Registry.UseMetrics.
+func UseMetrics(m Metrics) {
+	defaultRegistry.UseMetrics(m)
+}
+
+// UseMetrics makes Execute, ExecuteMany, the raw functions, such as
+// ExecuteRaw and ExecuteRawPaginated, and the writes, such as ExecuteInsert and
+// ExecuteImport, report each of their queries to m. Nil stops reporting.
+func (r *Registry) UseMetrics(m Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = m
+}
+
+// observe reports stats to the metrics of r, if any.
+func (r *Registry) observe(stats QueryStats) {
+	r.mu.RLock()
+	m := r.metrics
+	r.mu.RUnlock()
+	if m != nil {
+		m.ObserveQuery(stats)
+	}
+}
+
+// observeExecution reports exec, a structured query run since start, to the
+// metrics of r. exec is nil if the query failed before it was built.
+func (r *Registry) observeExecution(operation string, metadata ModelMetadata, exec *execution, start time.Time, err error) {
+	stats := QueryStats{
+		Model:     metadata.TableName,
+		Operation: operation,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+	if exec != nil {
+		stats.CacheHit = exec.cached
+		if err == nil {
+			stats.Rows = len(exec.rows)
+		}
+	}
+	r.observe(stats)
+}
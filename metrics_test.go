@@ -0,0 +1,112 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedMetrics is a Metrics that keeps the stats it observes.
+type recordedMetrics struct {
+	mu    sync.Mutex
+	stats []QueryStats
+}
+
+func (m *recordedMetrics) ObserveQuery(stats QueryStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats = append(m.stats, stats)
+}
+
+func TestMetricsExecute(t *testing.T) {
+	registry := NewRegistry()
+	metrics := &recordedMetrics{}
+	registry.UseMetrics(metrics)
+	db, _ := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+
+	req := QueryRequest{Select: []string{"id"}}
+	for i := 0; i < 2; i++ {
+		_, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+		require.NoError(t, err)
+	}
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, QueryRequest{Select: []string{"balance"}})
+	require.Error(t, err)
+
+	require.Len(t, metrics.stats, 3)
+	first, second, failed := metrics.stats[0], metrics.stats[1], metrics.stats[2]
+	assert.Equal(t, "accounts", first.Model)
+	assert.Equal(t, OperationExecute, first.Operation)
+	assert.Equal(t, 2, first.Rows)
+	assert.False(t, first.CacheHit)
+	assert.NoError(t, first.Err)
+	assert.Positive(t, first.Duration)
+	assert.True(t, second.CacheHit)
+	assert.Equal(t, "accounts", failed.Model)
+	assert.Zero(t, failed.Rows)
+	assert.ErrorContains(t, failed.Err, "invalid field in select: balance")
+}
+
+func TestMetricsExecuteMany(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+	metrics := &recordedMetrics{}
+	UseMetrics(metrics)
+
+	db, fake := newFakeDB(t, nil)
+	fake.queue([]string{"id"}, []driver.Value{int64(1)})
+	fake.queue([]string{"city"}, []driver.Value{"Pune"}, []driver.Value{"Mumbai"})
+
+	_, err := ExecuteMany(context.Background(), db,
+		QueryFor[CompileTestAccount](QueryRequest{Select: []string{"id"}}),
+		QueryFor[BatchTestBranch](QueryRequest{Select: []string{"city"}}),
+	)
+	require.NoError(t, err)
+	require.Len(t, metrics.stats, 2)
+	assert.Equal(t, "accounts", metrics.stats[0].Model)
+	assert.Equal(t, 1, metrics.stats[0].Rows)
+	assert.Equal(t, "branches", metrics.stats[1].Model)
+	assert.Equal(t, OperationBatch, metrics.stats[1].Operation)
+	assert.Equal(t, 2, metrics.stats[1].Rows)
+
+	// Queries after one that fails to build are not reported
+	metrics.stats = nil
+	_, err = ExecuteMany(context.Background(), db,
+		QueryFor[CompileTestAccount](QueryRequest{Select: []string{"id"}}),
+		QueryFor[BatchTestBranch](QueryRequest{Select: []string{"budget"}}),
+		QueryFor[CompileTestAccount](QueryRequest{Select: []string{"id"}}),
+	)
+	require.Error(t, err)
+	require.Len(t, metrics.stats, 1)
+	assert.Equal(t, "accounts", metrics.stats[0].Model)
+	assert.Error(t, metrics.stats[0].Err)
+}
+
+func TestMetricsExecuteRaw(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+	metrics := &recordedMetrics{}
+	UseMetrics(metrics)
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, _ := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+
+	_, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, metrics.stats, 1)
+	assert.Equal(t, QueryStats{
+		Model:     "test_results",
+		Operation: OperationRaw,
+		Duration:  metrics.stats[0].Duration,
+		Rows:      1,
+	}, metrics.stats[0])
+}
@@ -0,0 +1,64 @@
+package sqld
+
+import (
+	"strings"
+	"unicode"
+)
+
+// InferColumnNames makes the default registry derive missing db and json tags
+// from field names. See Registry.InferColumnNames.
+func InferColumnNames() {
+	defaultRegistry.InferColumnNames()
+}
+
+// InferColumnNames lets models registered afterwards leave out db and json
+// tags. A field without a db tag is stored in the snake_case form of its name,
+// as UserID in user_id, and a field without a json tag uses its column name,
+// so plain structs register without decorating every field:
+//
+//	type Employee struct {
+//	    ID        int64
+//	    FirstName string
+//	    HiredAt   time.Time `db:"hire_date"` // json name hire_date
+//	}
+//
+// Unexported fields, and fields tagged "-", are skipped. Models registered
+// before the call keep their metadata.
+func (r *Registry) InferColumnNames() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inferNames = true
+}
+
+// inferFieldNames fills in the db and json names of the Go field goName when
+// their tags are empty.
+func inferFieldNames(goName, dbName, jsonName string) (string, string) {
+	if dbName == "" {
+		dbName = snakeCase(goName)
+	}
+	if jsonName == "" {
+		jsonName = dbName
+	}
+	return dbName, jsonName
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping initialisms
+// together: ID is id, UserID is user_id and HTTPServer is http_server.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// A word starts at an upper case letter following a lower case
+			// letter or digit, or at the last upper case letter of an initialism
+			// followed by a lower case letter
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
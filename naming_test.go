@@ -0,0 +1,62 @@
+package sqld
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type InferredNamesModel struct {
+	ID        int64
+	UserID    int64
+	FirstName string
+	HiredAt   time.Time `db:"hire_date"`
+	Salary    float64   `json:"pay"`
+	internal  string
+}
+
+func (InferredNamesModel) TableName() string {
+	return "inferred_names"
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"FirstName":  "first_name",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+		"V2Name":     "v2_name",
+		"name":       "name",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, snakeCase(name), name)
+	}
+}
+
+func TestInferColumnNames(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Register(InferredNamesModel{})
+	assert.EqualError(t, err, `field "ID" missing required db tag`)
+
+	registry = NewRegistry()
+	registry.InferColumnNames()
+	require.NoError(t, registry.Register(InferredNamesModel{internal: "x"}))
+
+	metadata, err := registry.GetModelMetadata(InferredNamesModel{})
+	require.NoError(t, err)
+
+	names := make(map[string]string)
+	for jsonName, field := range metadata.Fields {
+		names[jsonName] = field.Name
+	}
+	assert.Equal(t, map[string]string{
+		"id":         "id",
+		"user_id":    "user_id",
+		"first_name": "first_name",
+		"hire_date":  "hire_date",
+		"pay":        "salary",
+	}, names)
+}
@@ -0,0 +1,121 @@
+package sqld
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+)
+
+// Network field types. netip.Addr holds inet columns of single hosts, such as
+// the client address of an audit log, and netip.Prefix holds inet and cidr
+// columns. Condition and insert values are netip.Addr or netip.Prefix values,
+// or strings such as "10.0.0.1" or "10.0.0.0/8".
+var (
+	addrType   = reflect.TypeOf(netip.Addr{})
+	prefixType = reflect.TypeOf(netip.Prefix{})
+)
+
+// isNetworkType reports whether fields of type t hold an inet or cidr column.
+func isNetworkType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == addrType || t == prefixType
+}
+
+// isNetworkOperator reports whether op only applies to network fields.
+func isNetworkOperator(op Operator) bool {
+	return op == OpSubnetOf || op == OpSupernetOf
+}
+
+// parseNetwork parses s as an address, or failing that as a network.
+func parseNetwork(s string) (interface{}, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr, nil
+	}
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix, nil
+	}
+	return nil, fmt.Errorf("invalid IP address or network %q", s)
+}
+
+// convertNetwork is the converter for network fields. Addresses and networks
+// are bound as netip values, which pgx encodes as inet or cidr; strings are
+// parsed into one or the other.
+func convertNetwork(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case netip.Addr, netip.Prefix:
+		return n, nil
+	case string:
+		return parseNetwork(n)
+	}
+	return nil, fmt.Errorf("cannot convert %T to an IP address or network", v)
+}
+
+// addrScanner scans inet results into a netip.Addr. pgx returns inet values
+// as a netip.Prefix, whose address is kept; database/sql drivers return text.
+type addrScanner struct {
+	v interface{}
+}
+
+func (s *addrScanner) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	if str, ok := src.(string); ok {
+		parsed, err := parseNetwork(str)
+		if err != nil {
+			return err
+		}
+		src = parsed
+	}
+	switch n := src.(type) {
+	case nil:
+		s.v = nil
+	case netip.Addr:
+		s.v = n
+	case netip.Prefix:
+		s.v = n.Addr()
+	default:
+		return fmt.Errorf("cannot scan %T into netip.Addr", src)
+	}
+	return nil
+}
+
+func (s *addrScanner) Value() interface{} {
+	return s.v
+}
+
+// prefixScanner scans inet and cidr results into a netip.Prefix. Addresses
+// without a mask, as PostgreSQL writes hosts, become single-host networks.
+type prefixScanner struct {
+	v interface{}
+}
+
+func (s *prefixScanner) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	if str, ok := src.(string); ok {
+		parsed, err := parseNetwork(str)
+		if err != nil {
+			return err
+		}
+		src = parsed
+	}
+	switch n := src.(type) {
+	case nil:
+		s.v = nil
+	case netip.Prefix:
+		s.v = n
+	case netip.Addr:
+		s.v = netip.PrefixFrom(n, n.BitLen())
+	default:
+		return fmt.Errorf("cannot scan %T into netip.Prefix", src)
+	}
+	return nil
+}
+
+func (s *prefixScanner) Value() interface{} {
+	return s.v
+}
@@ -0,0 +1,98 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type NetworkTestAuditLog struct {
+	ID       int64         `json:"id" db:"id"`
+	ClientIP netip.Addr    `json:"client_ip" db:"client_ip"`
+	Network  *netip.Prefix `json:"network" db:"network"`
+}
+
+func (NetworkTestAuditLog) TableName() string {
+	return "audit_log"
+}
+
+func TestNetworkConditions(t *testing.T) {
+	metadata, err := buildModelMetadata(NetworkTestAuditLog{}, false)
+	require.NoError(t, err)
+	assert.Contains(t, fieldOperators(metadata.Fields["client_ip"]), OpSubnetOf)
+	assert.NotContains(t, fieldOperators(metadata.Fields["id"]), OpSubnetOf)
+
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "client_ip", Operator: OpEqual, Value: "10.0.0.1"},
+			{Field: "client_ip", Operator: OpSubnetOf, Value: "10.0.0.0/8"},
+			{Field: "network", Operator: OpSupernetOf, Value: netip.MustParseAddr("192.168.1.7")},
+			{Field: "client_ip", Operator: OpIn, Value: []interface{}{"::1", netip.MustParseAddr("127.0.0.1")}},
+		},
+	}
+	require.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+	got, err := buildQuery[NetworkTestAuditLog](req)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM audit_log WHERE client_ip = $1 AND client_ip << $2 AND network >> $3 AND client_ip IN ($4,$5)", sql)
+	assert.Equal(t, []interface{}{
+		netip.MustParseAddr("10.0.0.1"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParseAddr("192.168.1.7"),
+		netip.MustParseAddr("::1"),
+		netip.MustParseAddr("127.0.0.1"),
+	}, args)
+
+	req.Where = []Condition{{Field: "id", Operator: OpSubnetOf, Value: "10.0.0.0/8"}}
+	var verr *ValidationError
+	require.ErrorAs(t, BasicValidator{}.ValidateQuery(req, metadata), &verr)
+	assert.Equal(t, []Violation{{
+		Code:    CodeNotNetworkField,
+		Path:    "where[0].operator",
+		Message: "operator << requires an inet or cidr field, but id is not one",
+	}}, verr.Rejected)
+
+	req.Where = []Condition{{Field: "client_ip", Operator: OpEqual, Value: 10}}
+	require.ErrorAs(t, BasicValidator{}.ValidateQuery(req, metadata), &verr)
+	assert.Equal(t, CodeTypeMismatch, verr.Rejected[0].Code)
+
+	req.Where = []Condition{{Field: "client_ip", Operator: OpEqual, Value: "10.0.0.300"}}
+	_, err = buildQuery[NetworkTestAuditLog](req)
+	assert.EqualError(t, err, `invalid value for field client_ip: invalid IP address or network "10.0.0.300"`)
+}
+
+func TestNetworkResults(t *testing.T) {
+	db, _ := newFakeDB(t, []string{"id", "client_ip", "network"},
+		[]driver.Value{int64(1), "10.0.0.1", "10.0.0.0/8"},
+		[]driver.Value{int64(2), "::1", "192.168.1.7"},
+		[]driver.Value{int64(3), "10.0.0.2", nil},
+	)
+	resp, err := Execute[NetworkTestAuditLog](context.Background(), db, QueryRequest{Select: []string{"id", "client_ip", "network"}})
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), resp.Data[0]["client_ip"])
+
+	encoded, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "client_ip": "10.0.0.1", "network": "10.0.0.0/8"},
+		{"id": 2, "client_ip": "::1", "network": "192.168.1.7/32"},
+		{"id": 3, "client_ip": "10.0.0.2", "network": null}
+	]`, string(encoded))
+}
+
+func TestNetworkScannersKeepAddressOfPgxPrefix(t *testing.T) {
+	registry := NewRegistry()
+	addr, err := registry.applyScanner(addrType, netip.MustParsePrefix("10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+
+	_, err = registry.applyScanner(prefixType, "not an address")
+	assert.EqualError(t, err, `invalid IP address or network "not an address"`)
+}
@@ -0,0 +1,59 @@
+package sqld
+
+import "reflect"
+
+// RegisterTypeNormalizer makes the default registry validate fields of type t
+// as values of type underlying. See Registry.RegisterTypeNormalizer.
+func RegisterTypeNormalizer(t, underlying reflect.Type) {
+	defaultRegistry.RegisterTypeNormalizer(t, underlying)
+}
+
+// RegisterTypeNormalizer makes models registered afterwards validate fields of
+// the custom type t, and arrays of it, as if they had type underlying. Wrapper
+// types sqld cannot see through, such as a struct holding an employee number,
+// otherwise only accept condition values of their own type:
+//
+//	sqld.RegisterTypeNormalizer(reflect.TypeOf(EmployeeID{}), reflect.TypeOf(int64(0)))
+//
+// Values are still bound as given, so register a converter for t if the driver
+// needs a value of type t. Models registered before the call keep their metadata.
+func (r *Registry) RegisterTypeNormalizer(t, underlying reflect.Type) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.normalizers[t] = underlying
+}
+
+// normalizeType is normalizeReflectType, seeing through the custom types
+// registered with RegisterTypeNormalizer.
+func (r *Registry) normalizeType(t reflect.Type) reflect.Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.normalizeTypeLocked(t)
+}
+
+// normalizeTypeLocked is normalizeType for callers that hold r.mu.
+func (r *Registry) normalizeTypeLocked(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if underlying, ok := r.normalizers[t]; ok {
+		t = underlying
+	}
+	return normalizeReflectType(t)
+}
+
+// applyNormalizersLocked renormalizes the fields of metadata whose type, or
+// element type, has a registered normalizer. The caller must hold r.mu.
+func (r *Registry) applyNormalizersLocked(metadata ModelMetadata) {
+	if len(r.normalizers) == 0 {
+		return
+	}
+	for name, field := range metadata.Fields {
+		field.NormalizedType = r.normalizeTypeLocked(field.Type)
+		if field.Array != nil {
+			elem, _ := arrayElemType(field.Type)
+			field.Array = &ArrayInfo{ElementType: r.normalizeTypeLocked(elem)}
+		}
+		metadata.Fields[name] = field
+	}
+}
@@ -0,0 +1,55 @@
+package sqld
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type NormalizerTestBadge struct {
+	Number int64
+}
+
+type NormalizerTestModel struct {
+	ID      int64                 `json:"id" db:"id"`
+	Badge   NormalizerTestBadge   `json:"badge" db:"badge"`
+	Mentors []NormalizerTestBadge `json:"mentors" db:"mentors"`
+}
+
+func (NormalizerTestModel) TableName() string {
+	return "normalizer_test_models"
+}
+
+func TestRegisterTypeNormalizer(t *testing.T) {
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where: []Condition{
+			{Field: "badge", Operator: OpEqual, Value: int64(42)},
+			{Field: "mentors", Operator: OpAny, Value: 7},
+		},
+	}
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(NormalizerTestModel{}))
+	metadata, err := registry.GetModelMetadata(NormalizerTestModel{})
+	require.NoError(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, BasicValidator{}.ValidateQuery(req, metadata), &verr)
+	assert.Equal(t, CodeTypeMismatch, verr.Rejected[0].Code)
+
+	registry = NewRegistry()
+	registry.RegisterTypeNormalizer(reflect.TypeOf(NormalizerTestBadge{}), reflect.TypeOf(int64(0)))
+	require.NoError(t, registry.Register(NormalizerTestModel{}))
+	metadata, err = registry.GetModelMetadata(NormalizerTestModel{})
+	require.NoError(t, err)
+
+	assert.Equal(t, reflect.TypeOf(int64(0)), metadata.Fields["badge"].NormalizedType)
+	assert.Equal(t, reflect.TypeOf(int64(0)), metadata.Fields["mentors"].Array.ElementType)
+	assert.Equal(t, reflect.TypeOf(int64(0)), metadata.Fields["id"].NormalizedType)
+	assert.NoError(t, BasicValidator{}.ValidateQuery(req, metadata))
+
+	req.Where = []Condition{{Field: "badge", Operator: OpEqual, Value: "forty-two"}}
+	assert.Error(t, BasicValidator{}.ValidateQuery(req, metadata))
+}
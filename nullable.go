@@ -0,0 +1,68 @@
+package sqld
+
+import (
+	"reflect"
+	"sort"
+)
+
+// RequireInsertValues makes the default registry reject inserts that leave out
+// required fields. See Registry.RequireInsertValues.
+func RequireInsertValues() {
+	defaultRegistry.RequireInsertValues()
+}
+
+// RequireInsertValues makes inserts into models registered afterwards fail
+// validation when they leave out a required field: one whose Go type cannot
+// hold NULL, whose column has no database default and that is not an
+// auto-managed timestamp. Columns filled by the database, such as serial keys,
+// are tagged sqld:"default":
+//
+//	type Employee struct {
+//	    ID        int64   `json:"id" db:"id" sqld:"default"`
+//	    FirstName string  `json:"first_name" db:"first_name"` // required
+//	    Manager   *string `json:"manager" db:"manager"`       // nullable, optional
+//	}
+//
+// Models registered before the call keep their metadata.
+func (r *Registry) RequireInsertValues() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requireAll = true
+}
+
+// Required reports whether an insert must provide a value for the field.
+// It is only enforced for models with ModelMetadata.RequireValues set.
+func (f Field) Required() bool {
+	return !f.Nullable && !f.HasDefault && f.AutoTimestamp == AutoNone
+}
+
+// missingRequiredFields returns the JSON names of the required fields of
+// metadata that values leaves out, sorted, or nil if the model does not
+// require values.
+func missingRequiredFields(metadata ModelMetadata, values map[string]interface{}) []string {
+	if !metadata.RequireValues {
+		return nil
+	}
+	var missing []string
+	for name, field := range metadata.Fields {
+		if _, ok := values[name]; !ok && field.Required() {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// isNullableType reports whether a field of type t can hold NULL: a pointer,
+// slice, map or interface, or a struct with a bool Valid field such as
+// pgtype.Text and sql.NullString.
+func isNullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+		valid, ok := t.FieldByName("Valid")
+		return ok && valid.Type.Kind() == reflect.Bool
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package sqld
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RequiredTestModel struct {
+	ID        int64       `json:"id" db:"id" sqld:"default"`
+	Name      string      `json:"name" db:"name"`
+	Age       int         `json:"age" db:"age"`
+	Manager   *string     `json:"manager" db:"manager"`
+	Nickname  pgtype.Text `json:"nickname" db:"nickname"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at" sqld:"autocreate"`
+}
+
+func (RequiredTestModel) TableName() string {
+	return "required_models"
+}
+
+func TestIsNullableType(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: 0, want: false},
+		{value: time.Time{}, want: false},
+		{value: new(string), want: true},
+		{value: []int{}, want: true},
+		{value: map[string]interface{}{}, want: true},
+		{value: pgtype.Int8{}, want: true},
+		{value: sql.NullString{}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(reflect.TypeOf(tt.value).String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, isNullableType(reflect.TypeOf(tt.value)))
+		})
+	}
+}
+
+func TestValidatorRejectsNullOnNonNullableField(t *testing.T) {
+	metadata, err := buildModelMetadata(RequiredTestModel{}, false)
+	require.NoError(t, err)
+
+	err = BasicValidator{}.ValidateQuery(QueryRequest{
+		Select: []string{"id"},
+		Where:  []Condition{{Field: "name", Operator: OpEqual, Value: nil}},
+	}, metadata)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, CodeNotNullable, verr.Rejected[0].Code)
+	assert.Equal(t, "where[0].value", verr.Rejected[0].Path)
+	assert.EqualError(t, err, "field name cannot be NULL, use IS NULL or IS NOT NULL to test for NULL")
+
+	err = BasicValidator{}.ValidateQuery(QueryRequest{
+		Select: []string{"id"},
+		Where:  []Condition{{Field: "manager", Operator: OpEqual, Value: nil}},
+	}, metadata)
+	assert.NoError(t, err)
+}
+
+func TestRequireInsertValues(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RequiredTestModel{}))
+	lenient, err := registry.GetModelMetadata(RequiredTestModel{})
+	require.NoError(t, err)
+	assert.False(t, lenient.RequireValues)
+	assert.NoError(t, BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"name": "Jane"}}, lenient))
+
+	registry = NewRegistry()
+	registry.RequireInsertValues()
+	require.NoError(t, registry.Register(RequiredTestModel{}))
+	metadata, err := registry.GetModelMetadata(RequiredTestModel{})
+	require.NoError(t, err)
+
+	err = BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"name": "Jane"}}, metadata)
+	assert.EqualError(t, err, "missing value for required field age")
+
+	err = BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"name": "Jane", "age": 30}}, metadata)
+	assert.NoError(t, err)
+
+	rowErrors := validateRow(0, map[string]interface{}{"manager": "Joe"}, metadata)
+	assert.Equal(t, []RowError{
+		{Row: 0, Field: "age", Message: "missing required field"},
+		{Row: 0, Field: "name", Message: "missing required field"},
+	}, rowErrors)
+}
+
+func TestInsertRejectsNullOnRequiredField(t *testing.T) {
+	registry := NewRegistry()
+	registry.RequireInsertValues()
+	require.NoError(t, registry.Register(RequiredTestModel{}))
+	metadata, err := registry.GetModelMetadata(RequiredTestModel{})
+	require.NoError(t, err)
+
+	// A present but NULL value does not satisfy a required field
+	err = BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{"name": nil, "age": 30}}, metadata)
+	assert.EqualError(t, err, "field name cannot be NULL")
+
+	// Nullable fields, defaults and auto-managed timestamps accept NULL
+	err = BasicValidator{}.ValidateInsert(InsertRequest{Values: map[string]interface{}{
+		"id": nil, "name": "Jane", "age": 30, "manager": nil, "nickname": nil, "created_at": nil,
+	}}, metadata)
+	assert.NoError(t, err)
+
+	rowErrors := validateRow(0, map[string]interface{}{"name": "Jane", "age": nil}, metadata)
+	assert.Equal(t, []RowError{{Row: 0, Field: "age", Message: "field age cannot be NULL"}}, rowErrors)
+}
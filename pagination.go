@@ -46,6 +46,19 @@ func CalculatePagination(totalItems, pageSize, currentPage int) *PaginationRespo
 	}
 }
 
+// pageRowCount returns the number of rows in a page of totalItems rows that
+// skips offset rows and holds at most limit rows. Nil values mean no limit or offset.
+func pageRowCount(totalItems int, limit, offset *int) int {
+	n := totalItems
+	if offset != nil {
+		n -= *offset
+	}
+	if limit != nil && *limit < n {
+		n = *limit
+	}
+	return max(n, 0)
+}
+
 // HasNextPage checks if there is a next page
 func HasNextPage(totalItems, pageSize, currentPage int) bool {
 	return CalculatePagination(totalItems, pageSize, currentPage).TotalPages > currentPage
@@ -0,0 +1,32 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageRowCount(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name       string
+		totalItems int
+		limit      *int
+		offset     *int
+		want       int
+	}{
+		{"full page", 25, intPtr(10), intPtr(10), 10},
+		{"last page", 25, intPtr(10), intPtr(20), 5},
+		{"past the end", 25, intPtr(10), intPtr(30), 0},
+		{"limit only", 25, intPtr(10), nil, 10},
+		{"offset only", 25, nil, intPtr(5), 20},
+		{"limit above total", 3, intPtr(10), nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pageRowCount(tt.totalItems, tt.limit, tt.offset))
+		})
+	}
+}
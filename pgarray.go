@@ -0,0 +1,37 @@
+package sqld
+
+import (
+	"reflect"
+	"strings"
+)
+
+// pgtypePkgPath is the import path of the pgtype package, whose generic
+// Array[T] type is matched by name.
+const pgtypePkgPath = "github.com/jackc/pgx/v5/pgtype"
+
+// pgArrayElem returns the element type T of a pgtype.Array[T], as used by SQLC
+// models with type overrides for array columns. It reports false for any other type.
+func pgArrayElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.PkgPath() != pgtypePkgPath || !strings.HasPrefix(t.Name(), "Array[") {
+		return nil, false
+	}
+	elements, ok := t.FieldByName("Elements")
+	if !ok || elements.Type.Kind() != reflect.Slice {
+		return nil, false
+	}
+	return elements.Type.Elem(), true
+}
+
+// arrayElemType returns the element type of fields of type t that hold an
+// array column: slices, including pgtype.FlatArray[T], and pgtype.Array[T].
+// JSON and bytea types are single values rather than arrays, so it reports
+// false for them.
+func arrayElemType(t reflect.Type) (reflect.Type, bool) {
+	if isJSONType(t) || isBytesType(t) {
+		return nil, false
+	}
+	if t.Kind() == reflect.Slice {
+		return t.Elem(), true
+	}
+	return pgArrayElem(t)
+}
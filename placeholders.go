@@ -0,0 +1,177 @@
+package sqld
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle selects the positional parameter syntax of the SQL sent to
+// the database by the raw query executors. Queries are always validated in
+// PostgreSQL's $N form; the placeholders are rewritten just before execution,
+// so the query text itself must stay within the SQL the parser accepts.
+// ExplainRaw and queries sent in a pgx batch only target PostgreSQL and
+// always use $N.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderDollar uses $1, $2, ... as PostgreSQL does. It is the default.
+	PlaceholderDollar PlaceholderStyle = iota
+
+	// PlaceholderQuestion uses ? as MySQL and SQLite do. A parameter used more
+	// than once is passed once per occurrence.
+	PlaceholderQuestion
+
+	// PlaceholderAtP uses @p1, @p2, ... as SQL Server does.
+	PlaceholderAtP
+
+	// PlaceholderColon uses :p1, :p2, ... as Oracle and SQLite do, passing
+	// the arguments as sql.Named values.
+	PlaceholderColon
+)
+
+// String returns the name of the placeholder style.
+func (s PlaceholderStyle) String() string {
+	switch s {
+	case PlaceholderDollar:
+		return "dollar"
+	case PlaceholderQuestion:
+		return "question"
+	case PlaceholderAtP:
+		return "at"
+	case PlaceholderColon:
+		return "colon"
+	default:
+		return fmt.Sprintf("PlaceholderStyle(%d)", int(s))
+	}
+}
+
+// formatPlaceholders rewrites the $N placeholders of query, and the matching
+// args, to style. Placeholders inside string literals, quoted identifiers and
+// comments are left alone.
+func formatPlaceholders(query string, args []interface{}, style PlaceholderStyle) (string, []interface{}, error) {
+	switch style {
+	case PlaceholderDollar:
+		return query, args, nil
+	case PlaceholderQuestion:
+		var reordered []interface{}
+		var err error
+		query = replaceDollarPlaceholders(query, func(n int) string {
+			if n > len(args) {
+				err = fmt.Errorf("placeholder $%d has no argument", n)
+				return ""
+			}
+			reordered = append(reordered, args[n-1])
+			return "?"
+		})
+		return query, reordered, err
+	case PlaceholderAtP:
+		return replaceDollarPlaceholders(query, func(n int) string { return "@p" + strconv.Itoa(n) }), args, nil
+	case PlaceholderColon:
+		named := make([]interface{}, len(args))
+		for i, arg := range args {
+			named[i] = sql.Named("p"+strconv.Itoa(i+1), arg)
+		}
+		return replaceDollarPlaceholders(query, func(n int) string { return ":p" + strconv.Itoa(n) }), named, nil
+	default:
+		return "", nil, fmt.Errorf("unknown placeholder style %v", style)
+	}
+}
+
+// replaceDollarPlaceholders replaces each $N placeholder of query outside
+// literals, quoted identifiers and comments with replace(N).
+func replaceDollarPlaceholders(query string, replace func(n int) string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		end := skipQuoted(query, i)
+		if end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+
+		if query[i] == '$' && i+1 < len(query) && isDigit(query[i+1]) && !isIdentChar(query, i-1) {
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			b.WriteString(replace(n))
+			i = j
+			continue
+		}
+		b.WriteByte(query[i])
+		i++
+	}
+	return b.String()
+}
+
+// skipQuoted returns the end of the string literal, quoted identifier or
+// comment starting at i, or i if none starts there.
+func skipQuoted(query string, i int) int {
+	switch {
+	case query[i] == '\'':
+		// E'...' literals escape quotes with backslashes
+		escapes := i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && !isIdentChar(query, i-2)
+		return skipUntilQuote(query, i+1, '\'', escapes)
+	case query[i] == '"':
+		return skipUntilQuote(query, i+1, '"', false)
+	case strings.HasPrefix(query[i:], "--"):
+		if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	case query[i] == '$' && !isIdentChar(query, i-1):
+		// Dollar-quoted string such as $$...$$ or $body$...$body$
+		j := i + 1
+		for j < len(query) && isIdentChar(query, j) {
+			j++
+		}
+		if j >= len(query) || query[j] != '$' || (j > i+1 && isDigit(query[i+1])) {
+			return i
+		}
+		tag := query[i : j+1]
+		if end := strings.Index(query[j+1:], tag); end >= 0 {
+			return j + 1 + end + len(tag)
+		}
+		return len(query)
+	}
+	return i
+}
+
+// skipUntilQuote returns the position after the quote closing the literal that
+// starts at i. Doubled quotes, and backslash escapes if escapes is set, do not close it.
+func skipUntilQuote(query string, i int, quote byte, escapes bool) int {
+	for i < len(query) {
+		switch {
+		case escapes && query[i] == '\\':
+			i += 2
+		case query[i] == quote && i+1 < len(query) && query[i+1] == quote:
+			i += 2
+		case query[i] == quote:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(query)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar reports whether query[i] can be part of an identifier.
+func isIdentChar(query string, i int) bool {
+	if i < 0 || i >= len(query) {
+		return false
+	}
+	c := query[i]
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,80 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPlaceholders(t *testing.T) {
+	const query = "SELECT id FROM t WHERE a = $1 AND b = $2 AND c = $1 AND d = '$1' AND \"$2\" = 1 -- $1\nAND e = $$ $2 $$ /* $1 */ AND f$1 = E'\\' $2'"
+	args := []interface{}{"x", 2}
+
+	tests := []struct {
+		name      string
+		style     PlaceholderStyle
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "dollar",
+			style:     PlaceholderDollar,
+			wantQuery: query,
+			wantArgs:  args,
+		},
+		{
+			name:      "question",
+			style:     PlaceholderQuestion,
+			wantQuery: "SELECT id FROM t WHERE a = ? AND b = ? AND c = ? AND d = '$1' AND \"$2\" = 1 -- $1\nAND e = $$ $2 $$ /* $1 */ AND f$1 = E'\\' $2'",
+			wantArgs:  []interface{}{"x", 2, "x"},
+		},
+		{
+			name:      "at",
+			style:     PlaceholderAtP,
+			wantQuery: "SELECT id FROM t WHERE a = @p1 AND b = @p2 AND c = @p1 AND d = '$1' AND \"$2\" = 1 -- $1\nAND e = $$ $2 $$ /* $1 */ AND f$1 = E'\\' $2'",
+			wantArgs:  args,
+		},
+		{
+			name:      "colon",
+			style:     PlaceholderColon,
+			wantQuery: "SELECT id FROM t WHERE a = :p1 AND b = :p2 AND c = :p1 AND d = '$1' AND \"$2\" = 1 -- $1\nAND e = $$ $2 $$ /* $1 */ AND f$1 = E'\\' $2'",
+			wantArgs:  []interface{}{sql.Named("p1", "x"), sql.Named("p2", 2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotArgs, err := formatPlaceholders(query, args, tt.style)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, gotQuery)
+			assert.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+
+	_, _, err := formatPlaceholders(query, args, PlaceholderStyle(9))
+	assert.EqualError(t, err, "unknown placeholder style PlaceholderStyle(9)")
+}
+
+func TestExecuteRawPlaceholderStyle(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	results, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:        "SELECT id, name FROM test_results WHERE id = {{id}} OR parent_id = {{id}} AND name = {{name}}",
+		Params:       map[string]interface{}{"id": 1, "name": "Jane"},
+		Placeholders: PlaceholderQuestion,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": 1, "name": "Jane"}}, results)
+	assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE id = ? OR parent_id = ? AND name = ?"}, fake.statements)
+	assert.Equal(t, [][]driver.Value{{int64(1), int64(1), "Jane"}}, fake.args)
+}
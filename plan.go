@@ -0,0 +1,129 @@
+package sqld
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// resultPlan converts the rows of one query into result maps. It is built once
+// per query, so looking up scanners, locating struct fields and deciding which
+// timestamps to convert is not repeated for every row.
+type resultPlan struct {
+	fields         []plannedField
+	numericStrings bool
+	loc            *time.Location
+}
+
+// plannedField is how one field of the rows of a query is converted.
+type plannedField struct {
+	field   Field
+	key     string             // Key of the value in result maps
+	index   []int              // Index of the field in struct rows, nil for map rows
+	scanner func() sql.Scanner // Scanner registered for the field's type, nil if none
+	zoned   bool               // Timestamps are converted to the plan's location, see hasZone
+}
+
+// newResultPlan plans the conversion of the fields of metadata named keys. If
+// rowType is not nil, rows are structs of that type; fields it does not have
+// are left out. Otherwise rows are maps keyed by column name. Timestamps are
+// converted to loc unless it is nil.
+func (r *Registry) newResultPlan(metadata ModelMetadata, keys []string, rowType reflect.Type, loc *time.Location) *resultPlan {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plan := &resultPlan{
+		fields:         make([]plannedField, 0, len(keys)),
+		numericStrings: r.numericStrings,
+		loc:            loc,
+	}
+	for _, key := range keys {
+		field, ok := metadata.Fields[key]
+		if !ok {
+			continue
+		}
+		planned := plannedField{field: field, key: key, zoned: loc != nil && hasZone(field.Type)}
+		if rowType != nil {
+			structField, ok := rowType.FieldByName(field.GoFieldName)
+			if !ok {
+				continue
+			}
+			planned.index = structField.Index
+		}
+		t := field.Type
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		planned.scanner = r.scanners[t]
+		plan.fields = append(plan.fields, planned)
+	}
+	return plan
+}
+
+// mapRow converts a row scanned into a map keyed by column name.
+func (p *resultPlan) mapRow(row map[string]interface{}) (QueryResult, error) {
+	result := make(QueryResult, len(p.fields))
+	for _, f := range p.fields {
+		val, ok := row[f.field.Name]
+		if !ok {
+			continue
+		}
+		converted, err := p.value(f, val)
+		if err != nil {
+			return nil, err
+		}
+		result[f.key] = converted
+	}
+	return result, nil
+}
+
+// structRow converts a row scanned into a struct of the plan's row type.
+func (p *resultPlan) structRow(row reflect.Value) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(p.fields))
+	for _, f := range p.fields {
+		converted, err := p.value(f, row.FieldByIndex(f.index).Interface())
+		if err != nil {
+			return nil, err
+		}
+		result[f.key] = converted
+	}
+	return result, nil
+}
+
+// value converts the value of the field f.
+func (p *resultPlan) value(f plannedField, val interface{}) (interface{}, error) {
+	converted, err := convertResultValue(f.field, f.scanner, val, p.numericStrings)
+	if err != nil {
+		return nil, err
+	}
+	if f.zoned {
+		converted = localizeValue(converted, p.loc)
+	}
+	return converted, nil
+}
+
+// convertResultValue runs val, the value of field in a result row, through
+// scanner unless it is nil, fails if an enum field holds a label that was not
+// registered, and renders numerics as strings if numericStrings is set.
+func convertResultValue(field Field, scanner func() sql.Scanner, val interface{}, numericStrings bool) (interface{}, error) {
+	if scanner != nil {
+		scanned, err := scanWith(scanner, val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan field %s: %w", field.JSONName, err)
+		}
+		val = scanned
+	}
+	if field.Enum != nil {
+		if label, ok := unknownEnumLabel(field.Enum, val); ok {
+			return nil, fmt.Errorf("field %s has label %q, which is not registered for enum %s",
+				field.JSONName, label, field.Enum.Type)
+		}
+	}
+	if numericStrings {
+		if s, ok := numericString(val); ok {
+			val = s
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,80 @@
+package sqld
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PlanTestEvent struct {
+	ID       int64         `json:"id" db:"id"`
+	Name     string        `json:"name" db:"event_name"`
+	Every    time.Duration `json:"every" db:"every"`
+	StartsAt time.Time     `json:"starts_at" db:"starts_at"`
+}
+
+func (PlanTestEvent) TableName() string {
+	return "events"
+}
+
+type PlanTestEventRow struct {
+	ID   int64  `json:"id" db:"id"`
+	Name string `json:"name" db:"event_name"`
+}
+
+func TestResultPlanMapRows(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(PlanTestEvent{}))
+	metadata, err := registry.GetModelMetadata(PlanTestEvent{})
+	require.NoError(t, err)
+
+	ist := time.FixedZone("IST", 5*3600+1800)
+	plan := registry.newResultPlan(metadata, []string{"name", "every", "starts_at", "missing"}, nil, ist)
+	require.Len(t, plan.fields, 3)
+	assert.NotNil(t, plan.fields[1].scanner)
+	assert.True(t, plan.fields[2].zoned)
+
+	// Scanners registered after planning do not affect the plan
+	registry.RegisterScanner(reflect.TypeOf(""), func() sql.Scanner { return &jsonScanner{} })
+
+	startsAt := time.Date(2024, 3, 1, 4, 30, 0, 0, time.UTC)
+	for _, row := range []map[string]interface{}{
+		{"id": int64(1), "event_name": "standup", "every": "1 day", "starts_at": startsAt},
+		{"id": int64(2), "event_name": "retro", "every": "14 days", "starts_at": startsAt},
+	} {
+		result, err := plan.mapRow(row)
+		require.NoError(t, err)
+		assert.Equal(t, row["event_name"], result["name"])
+		assert.NotContains(t, result, "id")
+		assert.IsType(t, time.Duration(0), result["every"])
+		assert.Equal(t, ist, result["starts_at"].(time.Time).Location())
+	}
+
+	_, err = plan.mapRow(map[string]interface{}{"every": "often"})
+	assert.ErrorContains(t, err, "failed to scan field every")
+}
+
+func TestResultPlanStructRows(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(PlanTestEvent{}))
+	metadata, err := registry.GetModelMetadata(PlanTestEvent{})
+	require.NoError(t, err)
+
+	// Fields the row type lacks are left out
+	plan := rawResultPlan[PlanTestEventRow](registry, metadata, nil)
+	results, err := rawResultsToMaps(registry, []PlanTestEventRow{{ID: 1, Name: "standup"}, {ID: 2, Name: "retro"}}, metadata, nil)
+	require.NoError(t, err)
+	assert.Len(t, plan.fields, 2)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": int64(1), "name": "standup"},
+		{"id": int64(2), "name": "retro"},
+	}, results)
+
+	results, err = rawResultsToMaps(registry, []PlanTestEventRow{{ID: 1, Name: "standup"}}, metadata, []string{"event_name"})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": "standup"}}, results)
+}
@@ -0,0 +1,376 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// ErrPolicyDenied is returned when a policy denies a request.
+var ErrPolicyDenied = errors.New("request denied by policy")
+
+// Claims are the attributes of the caller, such as user id, roles or
+// departments, typically taken from an authentication token.
+type Claims map[string]interface{}
+
+// PolicyInput is the normalized description of a request that a Policy decides on.
+// Field names are JSON field names.
+type PolicyInput struct {
+	Model      string      `json:"model"`      // Table name of the queried model
+	Fields     []string    `json:"fields"`     // Selected fields; SelectAll is expanded
+	Operators  []Operator  `json:"operators"`  // Distinct operators used in Filters
+	Filters    []Condition `json:"filters"`    // Conditions of the request
+	OrderBy    []string    `json:"order_by"`   // Fields the request sorts on
+	Aggregates []string    `json:"aggregates"` // Fields the request aggregates
+	Facets     []string    `json:"facets"`     // Fields the request counts the values of
+	Claims     Claims      `json:"claims"`     // Attributes of the caller
+}
+
+// PolicyDecision is the outcome of a policy evaluation. An allowed request is
+// executed with its obligations: ForcedFilters are ANDed into its conditions
+// and DropFields are removed from its selection. Forced filter values may
+// refer to the caller's claims with ClaimRef.
+type PolicyDecision struct {
+	Allow         bool        `json:"allow"`
+	Reason        string      `json:"reason,omitempty"`
+	ForcedFilters []Condition `json:"forced_filters,omitempty"`
+	DropFields    []string    `json:"drop_fields,omitempty"`
+}
+
+// Policy decides whether a request may run and under which obligations.
+// PolicyInput and PolicyDecision marshal to JSON, so a Policy can delegate to
+// an external engine such as OPA; see OPAPolicy.
+type Policy interface {
+	Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+}
+
+// PolicyFunc adapts a function to the Policy interface.
+type PolicyFunc func(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+
+// Evaluate calls f.
+func (f PolicyFunc) Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	return f(ctx, input)
+}
+
+// NewPolicyInput describes req, a request for the model described by metadata,
+// made by a caller with the given claims.
+func NewPolicyInput(req QueryRequest, metadata ModelMetadata, claims Claims) PolicyInput {
+	input := PolicyInput{
+		Model:   metadata.TableName,
+		Fields:  req.Select,
+		Filters: req.Where,
+		Claims:  claims,
+	}
+	if len(req.Select) == 1 && req.Select[0] == SelectAll {
+		input.Fields = sortedFieldNames(metadata)
+	}
+
+	seen := make(map[Operator]bool)
+	for _, cond := range req.Where {
+		if !seen[cond.Operator] {
+			seen[cond.Operator] = true
+			input.Operators = append(input.Operators, cond.Operator)
+		}
+	}
+	for _, orderBy := range req.OrderBy {
+		input.OrderBy = append(input.OrderBy, orderBy.Field)
+	}
+	for _, agg := range req.Aggregates {
+		input.Aggregates = append(input.Aggregates, agg.Field)
+	}
+	input.Facets = req.Facets
+	return input
+}
+
+// ApplyPolicy evaluates policy for req and returns a copy of req with the
+// decision's obligations applied. Denied requests fail with ErrPolicyDenied,
+// as do requests that filter, sort, aggregate or facet on a dropped field.
+func ApplyPolicy(ctx context.Context, policy Policy, claims Claims, req QueryRequest, metadata ModelMetadata) (QueryRequest, error) {
+	input := NewPolicyInput(req, metadata, claims)
+	decision, err := policy.Evaluate(ctx, input)
+	if err != nil {
+		return QueryRequest{}, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+	if !decision.Allow {
+		if decision.Reason != "" {
+			return QueryRequest{}, fmt.Errorf("%w: %s", ErrPolicyDenied, decision.Reason)
+		}
+		return QueryRequest{}, ErrPolicyDenied
+	}
+
+	if len(decision.DropFields) > 0 {
+		var selected []string
+		for _, field := range input.Fields {
+			if !contains(decision.DropFields, field) {
+				selected = append(selected, field)
+			}
+		}
+		if len(selected) == 0 {
+			return QueryRequest{}, fmt.Errorf("%w: none of the selected fields may be read", ErrPolicyDenied)
+		}
+		req.Select = selected
+
+		for _, cond := range req.Where {
+			if contains(decision.DropFields, cond.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be filtered on", ErrPolicyDenied, cond.Field)
+			}
+		}
+		for _, orderBy := range req.OrderBy {
+			if contains(decision.DropFields, orderBy.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be sorted on", ErrPolicyDenied, orderBy.Field)
+			}
+		}
+		for _, agg := range req.Aggregates {
+			if contains(decision.DropFields, agg.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be aggregated", ErrPolicyDenied, agg.Field)
+			}
+		}
+		for _, facet := range req.Facets {
+			if contains(decision.DropFields, facet) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be faceted", ErrPolicyDenied, facet)
+			}
+		}
+	}
+
+	forced, err := resolveClaimRefs(decision.ForcedFilters, claims)
+	if err != nil {
+		return QueryRequest{}, err
+	}
+
+	// Copy the conditions so the caller's slice is never modified
+	where := make([]Condition, 0, len(req.Where)+len(forced))
+	where = append(where, req.Where...)
+	req.Where = append(where, forced...)
+
+	return req, nil
+}
+
+// ClaimRef is a condition value referring to a claim of the caller. Forced
+// filters use it to restrict rows by caller attributes, so a single rule
+// serves every caller:
+//
+//	{Field: "department", Operator: sqld.OpIn, Value: sqld.ClaimRef("departments")}
+//
+// In JSON, such as in OPA decisions, a claim reference is written as
+// {"claim": "departments"}.
+type ClaimRef string
+
+// resolveClaimRefs returns a copy of filters with claim references replaced by
+// the values of the claims. A scalar claim used with IN or NOT IN becomes a
+// one-element list. A missing claim denies the request, since the filter could
+// not restrict it.
+func resolveClaimRefs(filters []Condition, claims Claims) ([]Condition, error) {
+	resolved := make([]Condition, len(filters))
+	for i, cond := range filters {
+		name, ok := claimRefName(cond.Value)
+		if !ok {
+			resolved[i] = cond
+			continue
+		}
+
+		value, ok := claims[name]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("%w: claim %s required by a forced filter on %s is missing", ErrPolicyDenied, name, cond.Field)
+		}
+		if cond.Operator == OpIn || cond.Operator == OpNotIn {
+			if kind := reflect.TypeOf(value).Kind(); kind != reflect.Slice && kind != reflect.Array {
+				value = []interface{}{value}
+			}
+		}
+		cond.Value = value
+		resolved[i] = cond
+	}
+	return resolved, nil
+}
+
+// claimRefName returns the claim referred to by a condition value, given as
+// a ClaimRef or as a JSON-decoded {"claim": name} object.
+func claimRefName(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case ClaimRef:
+		return string(v), true
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return "", false
+		}
+		name, ok := v["claim"].(string)
+		return name, ok
+	}
+	return "", false
+}
+
+// ExecuteWithPolicy evaluates policy for req on behalf of a caller with the
+// given claims, applies the decision and then runs the request with Execute.
+func ExecuteWithPolicy[T Model](ctx context.Context, db interface{}, policy Policy, claims Claims, req QueryRequest) (QueryResponse[T], error) {
+	return executeWithPolicyWith[T](ctx, defaultRegistry, db, policy, claims, req)
+}
+
+// executeWithPolicyWith implements ExecuteWithPolicy with the models of r.
+func executeWithPolicyWith[T Model](ctx context.Context, r *Registry, db interface{}, policy Policy, claims Claims, req QueryRequest) (QueryResponse[T], error) {
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return QueryResponse[T]{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	req, err = ApplyPolicy(ctx, policy, claims, req, metadata)
+	if err != nil {
+		return QueryResponse[T]{}, err
+	}
+
+	return executeWith[T](ctx, r, db, req)
+}
+
+// sortedFieldNames returns the JSON names of all fields of the model in sorted order.
+func sortedFieldNames(metadata ModelMetadata) []string {
+	names := make([]string, 0, len(metadata.Fields))
+	for name := range metadata.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Effect is the outcome of a matching PolicyRule.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// PolicyRule is a declarative rule of a RulePolicy.
+type PolicyRule struct {
+	// Model is the table name the rule applies to, or "*" for every model.
+	Model string `json:"model"`
+
+	// Claims must all match the caller's claims for the rule to apply. A claim
+	// matches if it equals the value, or if it is a list containing the value.
+	// Empty means the rule applies to every caller.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+
+	Effect        Effect      `json:"effect"`
+	Reason        string      `json:"reason,omitempty"`
+	ForcedFilters []Condition `json:"forced_filters,omitempty"`
+	DropFields    []string    `json:"drop_fields,omitempty"`
+}
+
+// RulePolicy is an in-process Policy evaluating rules in order: the first rule
+// matching the model and the caller's claims decides. Requests matching no
+// rule are denied.
+//
+//	policy := sqld.RulePolicy{
+//	    {Model: "employees", Claims: map[string]interface{}{"roles": "hr"}, Effect: sqld.EffectAllow},
+//	    {Model: "employees", Effect: sqld.EffectAllow, DropFields: []string{"salary"}},
+//	}
+type RulePolicy []PolicyRule
+
+// Evaluate returns the decision of the first matching rule.
+func (p RulePolicy) Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	for i, rule := range p {
+		if rule.Model != "*" && rule.Model != input.Model {
+			continue
+		}
+		if !claimsMatch(rule.Claims, input.Claims) {
+			continue
+		}
+
+		switch rule.Effect {
+		case EffectAllow:
+			return PolicyDecision{
+				Allow:         true,
+				Reason:        rule.Reason,
+				ForcedFilters: rule.ForcedFilters,
+				DropFields:    rule.DropFields,
+			}, nil
+		case EffectDeny:
+			return PolicyDecision{Reason: rule.Reason}, nil
+		default:
+			return PolicyDecision{}, fmt.Errorf("policy rule %d has invalid effect %q", i, rule.Effect)
+		}
+	}
+	return PolicyDecision{Reason: fmt.Sprintf("no policy rule allows access to %s", input.Model)}, nil
+}
+
+// claimsMatch reports whether claims satisfy every required claim.
+func claimsMatch(required map[string]interface{}, claims Claims) bool {
+	for name, want := range required {
+		if !claimMatches(claims[name], want) {
+			return false
+		}
+	}
+	return true
+}
+
+// claimMatches reports whether claim equals want or is a list containing it.
+func claimMatches(claim, want interface{}) bool {
+	if claim == nil {
+		return false
+	}
+	v := reflect.ValueOf(claim)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if claimMatches(v.Index(i).Interface(), want) {
+				return true
+			}
+		}
+		return false
+	}
+	wantType := reflect.TypeOf(want)
+	return wantType != nil && wantType.Comparable() && v.Type().Comparable() && claim == want
+}
+
+// OPAPolicy evaluates requests with an Open Policy Agent server through its
+// data API. URL is the full address of the decision document, e.g.
+// http://localhost:8181/v1/data/sqld/decision, whose result must have the
+// shape of PolicyDecision. The PolicyInput is sent as the input document.
+type OPAPolicy struct {
+	URL    string
+	Client *http.Client // Optional - defaults to http.DefaultClient
+}
+
+// Evaluate queries the OPA server for the decision.
+func (p OPAPolicy) Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	body, err := json.Marshal(struct {
+		Input PolicyInput `json:"input"`
+	}{input})
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to create OPA request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to query OPA: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return PolicyDecision{}, fmt.Errorf("OPA returned status %s", resp.Status)
+	}
+
+	var result struct {
+		Result *PolicyDecision `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return PolicyDecision{}, fmt.Errorf("failed to decode OPA response: %w", err)
+	}
+	if result.Result == nil {
+		// OPA omits the result when the document is undefined
+		return PolicyDecision{Reason: "policy decision is undefined"}, nil
+	}
+	return *result.Result, nil
+}
@@ -0,0 +1,248 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulePolicy(t *testing.T) {
+	policy := RulePolicy{
+		{Model: "test_models", Claims: map[string]interface{}{"roles": "hr"}, Effect: EffectAllow},
+		{Model: "test_models", Claims: map[string]interface{}{"suspended": true}, Effect: EffectDeny, Reason: "account suspended"},
+		{Model: "test_models", Effect: EffectAllow, DropFields: []string{"salary"}},
+	}
+
+	tests := []struct {
+		name   string
+		model  string
+		claims Claims
+		want   PolicyDecision
+	}{
+		{
+			name:   "claim list contains role",
+			model:  "test_models",
+			claims: Claims{"roles": []string{"staff", "hr"}},
+			want:   PolicyDecision{Allow: true},
+		},
+		{
+			name:   "deny rule",
+			model:  "test_models",
+			claims: Claims{"roles": []string{"staff"}, "suspended": true},
+			want:   PolicyDecision{Reason: "account suspended"},
+		},
+		{
+			name:   "fallback rule with obligations",
+			model:  "test_models",
+			claims: Claims{"roles": "staff"},
+			want:   PolicyDecision{Allow: true, DropFields: []string{"salary"}},
+		},
+		{
+			name:  "no matching rule",
+			model: "accounts",
+			want:  PolicyDecision{Reason: "no policy rule allows access to accounts"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Evaluate(context.Background(), PolicyInput{Model: tt.model, Claims: tt.claims})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := RulePolicy{{Model: "*", Effect: "maybe"}}.Evaluate(context.Background(), PolicyInput{Model: "x"})
+	assert.EqualError(t, err, `policy rule 0 has invalid effect "maybe"`)
+}
+
+func TestApplyPolicy(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	decision := PolicyDecision{
+		Allow:         true,
+		ForcedFilters: []Condition{{Field: "active", Operator: OpEqual, Value: true}},
+		DropFields:    []string{"salary", "email"},
+	}
+	var input PolicyInput
+	policy := PolicyFunc(func(ctx context.Context, in PolicyInput) (PolicyDecision, error) {
+		input = in
+		return decision, nil
+	})
+
+	tests := []struct {
+		name       string
+		request    QueryRequest
+		wantSelect []string
+		wantErr    string
+	}{
+		{
+			name:       "select all is expanded without dropped fields",
+			request:    QueryRequest{Select: []string{SelectAll}},
+			wantSelect: []string{"active", "age", "id", "name", "nullable"},
+		},
+		{
+			name:       "dropped field is removed from selection",
+			request:    QueryRequest{Select: []string{"id", "salary"}, Where: []Condition{{Field: "age", Operator: OpGreaterThan, Value: 30}}},
+			wantSelect: []string{"id"},
+		},
+		{
+			name:    "only dropped fields",
+			request: QueryRequest{Select: []string{"salary"}},
+			wantErr: "request denied by policy: none of the selected fields may be read",
+		},
+		{
+			name:    "filter on dropped field",
+			request: QueryRequest{Select: []string{"id"}, Where: []Condition{{Field: "salary", Operator: OpGreaterThan, Value: 1.0}}},
+			wantErr: "request denied by policy: field salary cannot be filtered on",
+		},
+		{
+			name:    "sort on dropped field",
+			request: QueryRequest{Select: []string{"id"}, OrderBy: []OrderByClause{{Field: "email"}}},
+			wantErr: "request denied by policy: field email cannot be sorted on",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPolicy(context.Background(), policy, Claims{"sub": "u1"}, tt.request, metadata)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, ErrPolicyDenied)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSelect, got.Select)
+			assert.Equal(t, append(tt.request.Where, decision.ForcedFilters...), got.Where)
+			assert.Equal(t, Claims{"sub": "u1"}, input.Claims)
+		})
+	}
+}
+
+func TestNewPolicyInput(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	input := NewPolicyInput(QueryRequest{
+		Select: []string{"id", "name"},
+		Where: []Condition{
+			{Field: "age", Operator: OpGreaterThan, Value: 30},
+			{Field: "salary", Operator: OpGreaterThan, Value: 1.0},
+			{Field: "name", Operator: OpLike, Value: "J%"},
+		},
+		OrderBy:    []OrderByClause{{Field: "age", Desc: true}},
+		Aggregates: []Aggregate{{Func: AggSum, Field: "salary"}, {Func: AggCount, Field: "id"}},
+		Facets:     []string{"active"},
+	}, metadata, nil)
+
+	assert.Equal(t, "test_models", input.Model)
+	assert.Equal(t, []string{"id", "name"}, input.Fields)
+	assert.Equal(t, []Operator{OpGreaterThan, OpLike}, input.Operators)
+	assert.Equal(t, []string{"age"}, input.OrderBy)
+	assert.Equal(t, []string{"salary", "id"}, input.Aggregates)
+	assert.Equal(t, []string{"active"}, input.Facets)
+}
+
+func TestPolicyDeniesAggregateOnHiddenField(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	// The rule only looks at the input, as an external policy would
+	policy := PolicyFunc(func(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+		for _, fields := range [][]string{input.Fields, input.Aggregates, input.Facets} {
+			if contains(fields, "salary") {
+				return PolicyDecision{Reason: "salary is hidden"}, nil
+			}
+		}
+		return PolicyDecision{Allow: true}, nil
+	})
+
+	for name, req := range map[string]QueryRequest{
+		"aggregate": {Select: []string{"id"}, Aggregates: []Aggregate{{Func: AggSum, Field: "salary"}}},
+		"facet":     {Select: []string{"id"}, Facets: []string{"salary"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ApplyPolicy(context.Background(), policy, nil, req, metadata)
+			assert.EqualError(t, err, "request denied by policy: salary is hidden")
+		})
+	}
+
+	_, err = ApplyPolicy(context.Background(), policy, nil, QueryRequest{
+		Select:     []string{"id"},
+		Aggregates: []Aggregate{{Func: AggSum, Field: "age"}},
+	}, metadata)
+	assert.NoError(t, err)
+}
+
+func TestOPAPolicy(t *testing.T) {
+	var received struct {
+		Input PolicyInput `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if r.URL.Path == "/v1/data/sqld/undefined" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"result": {"allow": true, "drop_fields": ["salary"]}}`))
+	}))
+	defer server.Close()
+
+	policy := OPAPolicy{URL: server.URL + "/v1/data/sqld/decision"}
+	decision, err := policy.Evaluate(context.Background(), PolicyInput{Model: "employees", Claims: Claims{"sub": "u1"}})
+	require.NoError(t, err)
+	assert.Equal(t, PolicyDecision{Allow: true, DropFields: []string{"salary"}}, decision)
+	assert.Equal(t, "employees", received.Input.Model)
+	assert.Equal(t, "u1", received.Input.Claims["sub"])
+
+	policy.URL = server.URL + "/v1/data/sqld/undefined"
+	decision, err = policy.Evaluate(context.Background(), PolicyInput{Model: "employees"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allow)
+}
+
+func TestApplyPolicyClaimRefs(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	policy := RulePolicy{{
+		Model:  "test_models",
+		Effect: EffectAllow,
+		ForcedFilters: []Condition{
+			{Field: "name", Operator: OpIn, Value: ClaimRef("names")},
+			{Field: "age", Operator: OpLessThanOrEqual, Value: map[string]interface{}{"claim": "max_age"}},
+			{Field: "email", Operator: OpNotIn, Value: ClaimRef("email")},
+		},
+	}}
+	req := QueryRequest{Select: []string{"id"}}
+
+	got, err := ApplyPolicy(context.Background(), policy, Claims{
+		"names":   []interface{}{"Jane", "John"},
+		"max_age": 40,
+		"email":   "jane@example.com",
+	}, req, metadata)
+	require.NoError(t, err)
+	assert.Equal(t, []Condition{
+		{Field: "name", Operator: OpIn, Value: []interface{}{"Jane", "John"}},
+		{Field: "age", Operator: OpLessThanOrEqual, Value: 40},
+		{Field: "email", Operator: OpNotIn, Value: []interface{}{"jane@example.com"}},
+	}, got.Where)
+	assert.NoError(t, BasicValidator{}.ValidateQuery(got, metadata))
+
+	// The rule's conditions keep their claim references for the next caller
+	assert.Equal(t, ClaimRef("names"), policy[0].ForcedFilters[0].Value)
+
+	_, err = ApplyPolicy(context.Background(), policy, Claims{"names": []string{"Jane"}}, req, metadata)
+	assert.ErrorIs(t, err, ErrPolicyDenied)
+	assert.EqualError(t, err, "request denied by policy: claim max_age required by a forced filter on age is missing")
+}
@@ -0,0 +1,115 @@
+package sqld
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolOption configures the connection setup installed by ConfigurePool.
+type PoolOption func(*poolSetup)
+
+// poolSetup is what ConfigurePool does on every new connection.
+type poolSetup struct {
+	registry       *Registry
+	skipEnums      bool
+	skipDomains    bool
+	skipComposites bool
+	composites     []string
+}
+
+// WithRegistry makes ConfigurePool store enum labels in the enum catalog of
+// registry instead of the default registry's.
+func WithRegistry(registry *Registry) PoolOption {
+	return func(s *poolSetup) {
+		s.registry = registry
+	}
+}
+
+// WithoutEnums keeps ConfigurePool from registering the enums of the current
+// schema, for instance when the application registers its own codecs for them.
+func WithoutEnums() PoolOption {
+	return func(s *poolSetup) {
+		s.skipEnums = true
+	}
+}
+
+// WithoutDomains keeps ConfigurePool from registering the domains of the
+// current schema.
+func WithoutDomains() PoolOption {
+	return func(s *poolSetup) {
+		s.skipDomains = true
+	}
+}
+
+// WithoutComposites keeps ConfigurePool from registering the composite types
+// of the current schema.
+func WithoutComposites() PoolOption {
+	return func(s *poolSetup) {
+		s.skipComposites = true
+	}
+}
+
+// WithCompositeTypes makes ConfigurePool register the named composite types,
+// and arrays of them, such as types of other schemas given by qualified name.
+// Their field types must be known to pgx or registered by the same call.
+func WithCompositeTypes(names ...string) PoolOption {
+	return func(s *poolSetup) {
+		s.composites = append(s.composites, names...)
+	}
+}
+
+// ConfigurePool installs an AfterConnect hook on cfg that prepares every new
+// connection of the pool for sqld: the enums of the current schema are
+// registered, and their labels loaded, as AutoRegisterEnums does, then its
+// domains and composite types as AutoRegisterDomains and
+// AutoRegisterComposites do, and finally the composite types given with
+// WithCompositeTypes. An AfterConnect hook already set on cfg runs first.
+//
+//	cfg, err := pgxpool.ParseConfig(dsn)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	sqld.ConfigurePool(cfg, sqld.WithCompositeTypes("billing.address"))
+//	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+func ConfigurePool(cfg *pgxpool.Config, opts ...PoolOption) {
+	var setup poolSetup
+	for _, opt := range opts {
+		opt(&setup)
+	}
+
+	previous := cfg.AfterConnect
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if previous != nil {
+			if err := previous(ctx, conn); err != nil {
+				return err
+			}
+		}
+		return setup.afterConnect(ctx, conn)
+	}
+}
+
+// afterConnect registers the types of s with conn.
+func (s poolSetup) afterConnect(ctx context.Context, conn *pgx.Conn) error {
+	if !s.skipEnums {
+		registry := s.registry
+		if registry == nil {
+			registry = defaultRegistry
+		}
+		if err := registry.AutoRegisterEnums(ctx, conn); err != nil {
+			return err
+		}
+	}
+	if !s.skipDomains {
+		if err := AutoRegisterDomains(ctx, conn); err != nil {
+			return err
+		}
+	}
+	if !s.skipComposites {
+		if err := AutoRegisterComposites(ctx, conn); err != nil {
+			return err
+		}
+	}
+	return registerDerivedTypes(ctx, conn, "composite", s.composites)
+}
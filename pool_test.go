@@ -0,0 +1,37 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolOptions(t *testing.T) {
+	var setup poolSetup
+	for _, opt := range []PoolOption{WithoutEnums(), WithoutDomains(), WithoutComposites(), WithCompositeTypes("address"), WithCompositeTypes("money_amount")} {
+		opt(&setup)
+	}
+	assert.Equal(t, poolSetup{skipEnums: true, skipDomains: true, skipComposites: true, composites: []string{"address", "money_amount"}}, setup)
+}
+
+func TestConfigurePoolRunsExistingHookFirst(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://localhost/test")
+	require.NoError(t, err)
+
+	hookErr := errors.New("hook failed")
+	calls := 0
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		calls++
+		return hookErr
+	}
+	ConfigurePool(cfg)
+
+	// The failing hook stops the setup before it uses the connection
+	assert.ErrorIs(t, cfg.AfterConnect(context.Background(), nil), hookErr)
+	assert.Equal(t, 1, calls)
+}
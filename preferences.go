@@ -0,0 +1,160 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PreferencesTableName is the table in which sqld stores per-user column preferences.
+const PreferencesTableName = "sqld_column_preferences"
+
+// ColumnPreferences is a user's preferred column set and sort order for a model.
+// Field names are JSON field names, the same as in QueryRequest.
+type ColumnPreferences struct {
+	Select  []string        `json:"select"`
+	OrderBy []OrderByClause `json:"order_by,omitempty"`
+}
+
+// EnsurePreferencesTable creates the preferences table if it does not exist.
+// Call it once at startup, or create the table through your migration tool
+// using the same definition.
+func EnsurePreferencesTable(ctx context.Context, db interface{}) error {
+	_, err := execStatement(ctx, db, `
+		CREATE TABLE IF NOT EXISTS `+PreferencesTableName+` (
+			user_id       TEXT        NOT NULL,
+			model         TEXT        NOT NULL,
+			select_fields JSONB       NOT NULL,
+			order_by      JSONB       NOT NULL,
+			updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (user_id, model)
+		)`)
+	if err != nil {
+		return wrapDBError("failed to create preferences table", err)
+	}
+	return nil
+}
+
+// SaveColumnPreferences stores prefs for the given user and model T,
+// replacing any previously saved preferences.
+// All field names are validated against the model's metadata.
+func SaveColumnPreferences[T Model](ctx context.Context, db interface{}, userID string, prefs ColumnPreferences) error {
+	var model T
+	metadata, err := getModelMetadata(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	if userID == "" {
+		return fmt.Errorf("user id cannot be empty")
+	}
+	for _, field := range prefs.Select {
+		if _, ok := metadata.Fields[field]; !ok {
+			return fmt.Errorf("invalid field in preferred select: %s", field)
+		}
+	}
+	for _, orderBy := range prefs.OrderBy {
+		if _, ok := metadata.Fields[orderBy.Field]; !ok {
+			return fmt.Errorf("invalid field in preferred order by: %s", orderBy.Field)
+		}
+	}
+
+	selectJSON, err := json.Marshal(prefs.Select)
+	if err != nil {
+		return fmt.Errorf("failed to serialize preferred select: %w", err)
+	}
+	if prefs.OrderBy == nil {
+		prefs.OrderBy = []OrderByClause{}
+	}
+	orderByJSON, err := json.Marshal(prefs.OrderBy)
+	if err != nil {
+		return fmt.Errorf("failed to serialize preferred order by: %w", err)
+	}
+
+	_, err = execStatement(ctx, db, `
+		INSERT INTO `+PreferencesTableName+` (user_id, model, select_fields, order_by, updated_at)
+		VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (user_id, model)
+		DO UPDATE SET select_fields = EXCLUDED.select_fields, order_by = EXCLUDED.order_by, updated_at = now()`,
+		userID, metadata.TableName, string(selectJSON), string(orderByJSON))
+	if err != nil {
+		return wrapDBError("failed to save column preferences", err)
+	}
+	return nil
+}
+
+// LoadColumnPreferences returns the saved preferences of the given user for model T,
+// or nil if none were saved. Fields that no longer exist on the model are dropped,
+// so preferences saved before a schema change never cause invalid requests.
+func LoadColumnPreferences[T Model](ctx context.Context, db interface{}, userID string) (*ColumnPreferences, error) {
+	var model T
+	metadata, err := getModelMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	var row struct {
+		SelectFields string `db:"select_fields"`
+		OrderBy      string `db:"order_by"`
+	}
+	err = getOne(ctx, db, &row, `
+		SELECT select_fields::text AS select_fields, order_by::text AS order_by
+		FROM `+PreferencesTableName+`
+		WHERE user_id = $1 AND model = $2`,
+		userID, metadata.TableName)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, wrapDBError("failed to load column preferences", err)
+	}
+
+	var stored ColumnPreferences
+	if err := json.Unmarshal([]byte(row.SelectFields), &stored.Select); err != nil {
+		return nil, fmt.Errorf("failed to decode preferred select: %w", err)
+	}
+	if err := json.Unmarshal([]byte(row.OrderBy), &stored.OrderBy); err != nil {
+		return nil, fmt.Errorf("failed to decode preferred order by: %w", err)
+	}
+
+	prefs := &ColumnPreferences{}
+	for _, field := range stored.Select {
+		if _, ok := metadata.Fields[field]; ok {
+			prefs.Select = append(prefs.Select, field)
+		}
+	}
+	for _, orderBy := range stored.OrderBy {
+		if _, ok := metadata.Fields[orderBy.Field]; ok {
+			prefs.OrderBy = append(prefs.OrderBy, orderBy)
+		}
+	}
+	return prefs, nil
+}
+
+// DeleteColumnPreferences removes the saved preferences of the given user for model T.
+func DeleteColumnPreferences[T Model](ctx context.Context, db interface{}, userID string) error {
+	var model T
+	_, err := execStatement(ctx, db,
+		`DELETE FROM `+PreferencesTableName+` WHERE user_id = $1 AND model = $2`,
+		userID, model.TableName())
+	if err != nil {
+		return wrapDBError("failed to delete column preferences", err)
+	}
+	return nil
+}
+
+// ApplyColumnPreferences fills in the parts of req the caller left empty from prefs.
+// Select and OrderBy explicitly set on the request always win over saved preferences.
+// A nil prefs returns req unchanged.
+func ApplyColumnPreferences(req QueryRequest, prefs *ColumnPreferences) QueryRequest {
+	if prefs == nil {
+		return req
+	}
+	if len(req.Select) == 0 && len(prefs.Select) > 0 {
+		req.Select = append([]string(nil), prefs.Select...)
+	}
+	if len(req.OrderBy) == 0 && len(prefs.OrderBy) > 0 {
+		req.OrderBy = append([]OrderByClause(nil), prefs.OrderBy...)
+	}
+	return req
+}
@@ -0,0 +1,55 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyColumnPreferences(t *testing.T) {
+	prefs := &ColumnPreferences{
+		Select:  []string{"id", "name"},
+		OrderBy: []OrderByClause{{Field: "name"}},
+	}
+
+	// Empty request parts are filled from preferences
+	got := ApplyColumnPreferences(QueryRequest{}, prefs)
+	assert.Equal(t, []string{"id", "name"}, got.Select)
+	assert.Equal(t, []OrderByClause{{Field: "name"}}, got.OrderBy)
+
+	// Explicit request values win
+	got = ApplyColumnPreferences(QueryRequest{
+		Select:  []string{"email"},
+		OrderBy: []OrderByClause{{Field: "age", Desc: true}},
+	}, prefs)
+	assert.Equal(t, []string{"email"}, got.Select)
+	assert.Equal(t, []OrderByClause{{Field: "age", Desc: true}}, got.OrderBy)
+
+	// No saved preferences
+	got = ApplyColumnPreferences(QueryRequest{Select: []string{"id"}}, nil)
+	assert.Equal(t, []string{"id"}, got.Select)
+}
+
+func TestSaveColumnPreferencesValidatesFields(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	var mockDB *MockDB
+
+	err := SaveColumnPreferences[BuilderTestModel](context.Background(), mockDB, "user-1", ColumnPreferences{
+		Select: []string{"id", "invalid_field"},
+	})
+	assert.EqualError(t, err, "invalid field in preferred select: invalid_field")
+
+	err = SaveColumnPreferences[BuilderTestModel](context.Background(), mockDB, "user-1", ColumnPreferences{
+		Select:  []string{"id"},
+		OrderBy: []OrderByClause{{Field: "invalid_field"}},
+	})
+	assert.EqualError(t, err, "invalid field in preferred order by: invalid_field")
+
+	// Valid preferences get as far as the database
+	err = SaveColumnPreferences[BuilderTestModel](context.Background(), mockDB, "user-1", ColumnPreferences{
+		Select: []string{"id", "name"},
+	})
+	assert.EqualError(t, err, "unsupported database type: *sqld.MockDB")
+}
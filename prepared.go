@@ -0,0 +1,254 @@
+package sqld
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxPreparedStatements bounds the number of statements a registry keeps
+// prepared per *sql.DB. Preparing another one closes the least recently used.
+const maxPreparedStatements = 256
+
+// PrepareStatements makes the default registry run structured queries as
+// reusable prepared statements. See Registry.PrepareStatements.
+func PrepareStatements() {
+	defaultRegistry.PrepareStatements()
+}
+
+// PrepareStatements makes structured queries run as prepared statements that
+// are reused by later queries with the same SQL. Since the SQL of a query is
+// the same for every request of its shape, repeated requests skip parsing and
+// planning in the database.
+//
+// On pgx handles, queries use pgx.QueryExecModeCacheStatement, so every
+// connection prepares a statement once in its statement cache, even if it was
+// configured with another exec mode. On a *sql.DB, the registry keeps a
+// *sql.Stmt per SQL, which database/sql prepares on each connection it runs
+// on, keeping the most recently used ones. Queries in a *sql.Tx are not
+// prepared, since the transaction does not tell which database its statements
+// would belong to. The registry holds the statements, and the *sql.DB, until
+// ClosePreparedStatements is called.
+func (r *Registry) PrepareStatements() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prepare = true
+}
+
+// ClosePreparedStatements closes the statements the default registry prepared
+// on db. See Registry.ClosePreparedStatements.
+func ClosePreparedStatements(db *sql.DB) error {
+	return defaultRegistry.ClosePreparedStatements(db)
+}
+
+// ClosePreparedStatements closes the statements r prepared on db and forgets
+// db, so a database that is closed or no longer used is not kept alive by r.
+// Call it before closing a *sql.DB queried with PrepareStatements; later
+// queries on db prepare their statements again. A nil db closes the statements
+// of every database. Statements still running a query are closed once it ends.
+func (r *Registry) ClosePreparedStatements(db *sql.DB) error {
+	r.stmtsMu.Lock()
+	var closing []*sql.Stmt
+	for key, cache := range r.stmts {
+		if db == nil || key == db {
+			closing = append(closing, cache.evictAll()...)
+			delete(r.stmts, key)
+		}
+	}
+	r.stmtsMu.Unlock()
+
+	var errs []error
+	for _, stmt := range closing {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selectPrepared is selectAll, running query as a prepared statement if
+// PrepareStatements was called.
+func (r *Registry) selectPrepared(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	querier, args, release, err := r.preparedQuerier(ctx, db, query, args)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if querier != nil {
+		return sqlscan.Select(ctx, querier, dest, query, args...)
+	}
+	return selectAll(ctx, db, dest, query, args...)
+}
+
+// getPrepared is getOne, running query as a prepared statement if
+// PrepareStatements was called.
+func (r *Registry) getPrepared(ctx context.Context, db interface{}, dest interface{}, query string, args ...interface{}) error {
+	querier, args, release, err := r.preparedQuerier(ctx, db, query, args)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if querier != nil {
+		return sqlscan.Get(ctx, querier, dest, query, args...)
+	}
+	return getOne(ctx, db, dest, query, args...)
+}
+
+// preparedQuerier returns how query runs on db as a prepared statement. For a
+// *sql.DB it returns a querier running the statement, and release must be
+// called once the query is done with it. For pgx handles the querier is nil
+// and the returned args make pgx cache the statement. If statements are not
+// prepared for db, the querier is nil and args are unchanged.
+func (r *Registry) preparedQuerier(ctx context.Context, db interface{}, query string, args []interface{}) (sqlscan.Querier, []interface{}, func(), error) {
+	release := func() {}
+	r.mu.RLock()
+	prepare := r.prepare
+	r.mu.RUnlock()
+	if !prepare {
+		return nil, args, release, nil
+	}
+
+	switch db := db.(type) {
+	case *pgx.Conn, *pgxpool.Pool, pgx.Tx:
+		return nil, append([]interface{}{pgx.QueryExecModeCacheStatement}, args...), release, nil
+	case *sql.DB:
+		stmt, err := r.preparedStmt(ctx, db, query)
+		if err != nil {
+			return nil, args, release, err
+		}
+		return stmtQuerier{stmt.stmt}, args, func() { r.releaseStmt(stmt) }, nil
+	}
+	return nil, args, release, nil
+}
+
+// preparedStmt returns the statement of query prepared on db, preparing it on
+// first use, and marks it in use until releaseStmt is called.
+func (r *Registry) preparedStmt(ctx context.Context, db *sql.DB, query string) (*cachedStmt, error) {
+	r.stmtsMu.Lock()
+	if stmt := r.stmts[db].get(query); stmt != nil {
+		stmt.users++
+		r.stmtsMu.Unlock()
+		return stmt, nil
+	}
+	r.stmtsMu.Unlock()
+
+	prepared, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	r.stmtsMu.Lock()
+	cache := r.stmts[db]
+	if cache == nil {
+		cache = newStmtCache()
+		r.stmts[db] = cache
+	}
+	// Another goroutine may have prepared the statement in the meantime
+	if stmt := cache.get(query); stmt != nil {
+		stmt.users++
+		r.stmtsMu.Unlock()
+		prepared.Close()
+		return stmt, nil
+	}
+	stmt := &cachedStmt{query: query, stmt: prepared, users: 1}
+	evicted := cache.add(stmt)
+	r.stmtsMu.Unlock()
+
+	for _, old := range evicted {
+		old.Close()
+	}
+	return stmt, nil
+}
+
+// releaseStmt ends a use of stmt, closing it if it was evicted meanwhile.
+func (r *Registry) releaseStmt(stmt *cachedStmt) {
+	r.stmtsMu.Lock()
+	stmt.users--
+	closing := stmt.evicted && stmt.users == 0
+	r.stmtsMu.Unlock()
+	if closing {
+		stmt.stmt.Close()
+	}
+}
+
+// cachedStmt is a statement prepared by a registry.
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	users   int  // Queries running the statement
+	evicted bool // Removed from its cache, closed once users drops to 0
+}
+
+// stmtCache holds the statements prepared on a database by SQL, up to
+// maxPreparedStatements, evicting the least recently used one beyond that.
+// It is guarded by the stmtsMu of its registry.
+type stmtCache struct {
+	stmts map[string]*list.Element // Elements of lru by SQL
+	lru   *list.List               // *cachedStmt, most recently used first
+}
+
+func newStmtCache() *stmtCache {
+	return &stmtCache{stmts: make(map[string]*list.Element), lru: list.New()}
+}
+
+// get returns the statement of query, or nil, and marks it as recently used.
+func (c *stmtCache) get(query string) *cachedStmt {
+	if c == nil {
+		return nil
+	}
+	elem, ok := c.stmts[query]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cachedStmt)
+}
+
+// add adds stmt and returns the evicted statements that must be closed.
+func (c *stmtCache) add(stmt *cachedStmt) []*sql.Stmt {
+	c.stmts[stmt.query] = c.lru.PushFront(stmt)
+	var closing []*sql.Stmt
+	for c.lru.Len() > maxPreparedStatements {
+		if old := c.evict(c.lru.Back()); old != nil {
+			closing = append(closing, old)
+		}
+	}
+	return closing
+}
+
+// evictAll evicts every statement and returns those that must be closed.
+func (c *stmtCache) evictAll() []*sql.Stmt {
+	var closing []*sql.Stmt
+	for c.lru.Len() > 0 {
+		if old := c.evict(c.lru.Back()); old != nil {
+			closing = append(closing, old)
+		}
+	}
+	return closing
+}
+
+// evict removes the statement of elem. It returns the statement if it must be
+// closed now, or nil if a query still runs it and releaseStmt will close it.
+func (c *stmtCache) evict(elem *list.Element) *sql.Stmt {
+	stmt := c.lru.Remove(elem).(*cachedStmt)
+	delete(c.stmts, stmt.query)
+	stmt.evicted = true
+	if stmt.users > 0 {
+		return nil
+	}
+	return stmt.stmt
+}
+
+// stmtQuerier runs every query it is given as its prepared statement.
+type stmtQuerier struct {
+	stmt *sql.Stmt
+}
+
+func (q stmtQuerier) QueryContext(ctx context.Context, _ string, args ...interface{}) (*sql.Rows, error) {
+	return q.stmt.QueryContext(ctx, args...)
+}
@@ -0,0 +1,125 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareStatements(t *testing.T) {
+	run := func(registry *Registry) *fakeDB {
+		db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+		for _, owner := range []string{"Jane", "John"} {
+			req := QueryRequest{
+				Select: []string{"id"},
+				Where:  []Condition{{Field: "owner", Operator: OpEqual, Value: owner}},
+			}
+			_, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+			require.NoError(t, err)
+		}
+		require.Len(t, fake.statements, 2)
+		assert.Equal(t, []driver.Value{"John"}, fake.args[1])
+		return fake
+	}
+
+	assert.Equal(t, 2, run(NewRegistry()).prepares)
+
+	registry := NewRegistry()
+	registry.PrepareStatements()
+	assert.Equal(t, 1, run(registry).prepares)
+}
+
+func TestPreparedStatementsServeEveryPage(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	registry := NewRegistry()
+	registry.PrepareStatements()
+
+	for page := 1; page <= 3; page++ {
+		fake.queue([]string{"count"}, []driver.Value{int64(30)})
+		req := QueryRequest{
+			Select:     []string{"id"},
+			Pagination: &PaginationRequest{Page: page, PageSize: 10},
+		}
+		_, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+		require.NoError(t, err)
+	}
+
+	// The count and the rows of every page run as two statements
+	assert.Equal(t, 2, fake.prepares)
+	assert.Equal(t, "SELECT id FROM accounts LIMIT $1 OFFSET $2", fake.statements[5])
+	assert.Equal(t, []driver.Value{int64(10), int64(20)}, fake.args[5])
+}
+
+func TestPreparedStatementsEvictLeastRecentlyUsed(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	registry := NewRegistry()
+	registry.PrepareStatements()
+	ctx := context.Background()
+
+	query := func(i int) {
+		var ids []int64
+		require.NoError(t, registry.selectPrepared(ctx, db, &ids, fmt.Sprintf("SELECT %d", i)))
+	}
+	for i := 0; i < maxPreparedStatements; i++ {
+		query(i)
+	}
+	query(0)
+
+	// Preparing beyond the limit evicts the statement used longest ago
+	query(maxPreparedStatements)
+	cache := registry.stmts[db]
+	assert.Equal(t, maxPreparedStatements, cache.lru.Len())
+	assert.Contains(t, cache.stmts, "SELECT 0")
+	assert.NotContains(t, cache.stmts, "SELECT 1")
+
+	query(1)
+	assert.Equal(t, maxPreparedStatements+2, fake.prepares)
+}
+
+func TestPreparedQuerierForPgx(t *testing.T) {
+	registry := NewRegistry()
+	var conn *pgx.Conn
+
+	querier, args, _, err := registry.preparedQuerier(context.Background(), conn, "SELECT 1", []interface{}{"a"})
+	require.NoError(t, err)
+	assert.Nil(t, querier)
+	assert.Equal(t, []interface{}{"a"}, args)
+
+	registry.PrepareStatements()
+	querier, args, _, err = registry.preparedQuerier(context.Background(), conn, "SELECT 1", []interface{}{"a"})
+	require.NoError(t, err)
+	assert.Nil(t, querier)
+	assert.Equal(t, []interface{}{pgx.QueryExecModeCacheStatement, "a"}, args)
+}
+
+func TestClosePreparedStatements(t *testing.T) {
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	other, _ := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	client := NewClient(db)
+	client.PrepareStatements()
+	req := QueryRequest{Select: []string{"id"}}
+
+	for _, handle := range []*Client{client, client.WithDB(other)} {
+		_, err := ExecuteOn[CompileTestAccount](context.Background(), handle, req)
+		require.NoError(t, err)
+	}
+	require.Len(t, client.stmts, 2)
+
+	// Only the statements of the database of the client are closed
+	require.NoError(t, client.Close())
+	assert.NotContains(t, client.stmts, db)
+	assert.Contains(t, client.stmts, other)
+
+	// Later queries prepare their statements again
+	_, err := ExecuteOn[CompileTestAccount](context.Background(), client, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.prepares)
+
+	require.NoError(t, client.ClosePreparedStatements(nil))
+	assert.Empty(t, client.stmts)
+}
@@ -0,0 +1,65 @@
+package sqld
+
+import "fmt"
+
+// KeyedModel is implemented by models that declare their primary key by
+// column, typically composite keys or models generated by sqlc whose struct
+// tags cannot be edited:
+//
+//	func (OrderLine) PrimaryKey() []string { return []string{"order_id", "line_no"} }
+//
+// The key can also be declared by tagging its fields sqld:"pk", in which case
+// the key columns are in struct order; a model may use one or the other, not
+// both. The key is recorded in ModelMetadata.PrimaryKey when the model is registered.
+type KeyedModel interface {
+	Model
+	PrimaryKey() []string
+}
+
+// modelPrimaryKey returns the JSON names of the primary key fields of model,
+// declared by KeyedModel or by the fields tagged sqld:"pk", listed in tagged.
+func modelPrimaryKey(model Model, metadata ModelMetadata, tagged []string) ([]string, error) {
+	keyed, ok := model.(KeyedModel)
+	if !ok {
+		return tagged, nil
+	}
+	if len(tagged) > 0 {
+		return nil, fmt.Errorf("model %s declares its primary key with both sqld:\"pk\" and PrimaryKey", metadata.TableName)
+	}
+
+	columns := keyed.PrimaryKey()
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("model %s has an empty primary key", metadata.TableName)
+	}
+	key := make([]string, len(columns))
+	for i, column := range columns {
+		field, ok := metadata.fieldByColumn(column)
+		if !ok {
+			return nil, fmt.Errorf("primary key column %s is not a field of model %s", column, metadata.TableName)
+		}
+		if contains(key[:i], field.JSONName) {
+			return nil, fmt.Errorf("primary key column %s of model %s is listed twice", column, metadata.TableName)
+		}
+		key[i] = field.JSONName
+	}
+	return key, nil
+}
+
+// PrimaryKeyColumns returns the columns of the primary key of the model, in key order.
+func (m ModelMetadata) PrimaryKeyColumns() []string {
+	columns := make([]string, len(m.PrimaryKey))
+	for i, name := range m.PrimaryKey {
+		columns[i] = m.Fields[name].Name
+	}
+	return columns
+}
+
+// fieldByColumn returns the field stored in column.
+func (m ModelMetadata) fieldByColumn(column string) (Field, bool) {
+	for _, field := range m.Fields {
+		if field.Name == column {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
@@ -0,0 +1,92 @@
+package sqld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TaggedKeyModel struct {
+	ID   int    `db:"id" json:"id" sqld:"pk"`
+	Name string `db:"name" json:"name"`
+}
+
+func (TaggedKeyModel) TableName() string { return "tagged_keys" }
+
+type CompositeKeyModel struct {
+	OrderID int `db:"order_id" json:"orderId"`
+	LineNo  int `db:"line_no" json:"lineNo"`
+	Qty     int `db:"qty" json:"qty"`
+}
+
+func (CompositeKeyModel) TableName() string { return "order_lines" }
+
+func (CompositeKeyModel) PrimaryKey() []string { return []string{"order_id", "line_no"} }
+
+type BadKeyModel struct {
+	ID int `db:"id" json:"id" sqld:"pk"`
+}
+
+func (BadKeyModel) TableName() string { return "bad_keys" }
+
+func (BadKeyModel) PrimaryKey() []string { return []string{"id"} }
+
+type UnknownKeyModel struct {
+	ID int `db:"id" json:"id"`
+}
+
+func (UnknownKeyModel) TableName() string { return "unknown_keys" }
+
+func (UnknownKeyModel) PrimaryKey() []string { return []string{"uuid"} }
+
+func TestPrimaryKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       Model
+		wantKey     []string
+		wantColumns []string
+		wantErr     string
+	}{
+		{
+			name:        "tag",
+			model:       TaggedKeyModel{},
+			wantKey:     []string{"id"},
+			wantColumns: []string{"id"},
+		},
+		{
+			name:        "interface",
+			model:       CompositeKeyModel{},
+			wantKey:     []string{"orderId", "lineNo"},
+			wantColumns: []string{"order_id", "line_no"},
+		},
+		{
+			name:        "none",
+			model:       TestResult{},
+			wantColumns: []string{},
+		},
+		{
+			name:    "tag and interface",
+			model:   BadKeyModel{},
+			wantErr: `model bad_keys declares its primary key with both sqld:"pk" and PrimaryKey`,
+		},
+		{
+			name:    "unknown column",
+			model:   UnknownKeyModel{},
+			wantErr: "primary key column uuid is not a field of model unknown_keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := buildModelMetadata(tt.model, false)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, metadata.PrimaryKey)
+			assert.Equal(t, tt.wantColumns, metadata.PrimaryKeyColumns())
+		})
+	}
+}
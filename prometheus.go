@@ -0,0 +1,101 @@
+package sqld
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the query
+// duration histogram of NewPrometheusMetrics when none are given.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a Metrics that counts queries per model and operation.
+// It is a prometheus.Collector, to be registered with the registry an
+// application serves, or with its own served by promhttp:
+//
+//	metrics := sqld.NewPrometheusMetrics()
+//	sqld.UseMetrics(metrics)
+//	registry := prometheus.NewRegistry()
+//	registry.MustRegister(metrics)
+//	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+//
+// It exposes, labelled with model and operation, sqld_queries_total,
+// sqld_query_errors_total, sqld_query_rows_total, sqld_query_cache_hits_total
+// and the histogram sqld_query_duration_seconds.
+type PrometheusMetrics struct {
+	queries   *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	rows      *prometheus.CounterVec
+	cacheHits *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+// prometheusLabelNames are the names of the labels of every series.
+var prometheusLabelNames = []string{"model", "operation"}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics whose duration
+// histogram has the upper bounds buckets, in seconds, or DefaultDurationBuckets
+// if none are given.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, prometheusLabelNames)
+	}
+	return &PrometheusMetrics{
+		queries:   counter("sqld_queries_total", "Queries run by sqld."),
+		errors:    counter("sqld_query_errors_total", "Queries run by sqld that failed."),
+		rows:      counter("sqld_query_rows_total", "Rows returned by queries run by sqld."),
+		cacheHits: counter("sqld_query_cache_hits_total", "Structured queries whose SQL came from the query cache."),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sqld_query_duration_seconds",
+			Help:    "Duration of queries run by sqld.",
+			Buckets: buckets,
+		}, prometheusLabelNames),
+	}
+}
+
+// ObserveQuery adds the query described by stats to the series of its model
+// and operation.
+func (m *PrometheusMetrics) ObserveQuery(stats QueryStats) {
+	// Every series of a model and operation is created on its first query,
+	// so errors and cache hits start at zero rather than being absent
+	labels := []string{stats.Model, stats.Operation}
+	m.queries.WithLabelValues(labels...).Inc()
+	failures := m.errors.WithLabelValues(labels...)
+	if stats.Err != nil {
+		failures.Inc()
+	}
+	m.rows.WithLabelValues(labels...).Add(float64(stats.Rows))
+	cacheHits := m.cacheHits.WithLabelValues(labels...)
+	if stats.CacheHit {
+		cacheHits.Inc()
+	}
+	m.duration.WithLabelValues(labels...).Observe(stats.Duration.Seconds())
+}
+
+// collectors returns the metric vectors of m.
+func (m *PrometheusMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.queries, m.errors, m.rows, m.cacheHits, m.duration}
+}
+
+// Describe sends the descriptors of the metrics of m, as prometheus.Collector
+// requires.
+func (m *PrometheusMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect sends the current values of the series of m, as
+// prometheus.Collector requires.
+func (m *PrometheusMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
@@ -0,0 +1,96 @@
+package sqld
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics(1, 0.5)
+	metrics.ObserveQuery(QueryStats{Model: "accounts", Operation: OperationExecute, Duration: 250 * time.Millisecond, Rows: 3})
+	metrics.ObserveQuery(QueryStats{Model: "accounts", Operation: OperationExecute, Duration: 500 * time.Millisecond, Rows: 2, CacheHit: true})
+	metrics.ObserveQuery(QueryStats{Model: "accounts", Operation: OperationExecute, Duration: 2 * time.Second, Err: errors.New("failed")})
+	metrics.ObserveQuery(QueryStats{Model: `a"b`, Operation: OperationRaw, Duration: time.Millisecond})
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var out strings.Builder
+	for _, family := range families {
+		_, err := expfmt.MetricFamilyToText(&out, family)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, `# HELP sqld_queries_total Queries run by sqld.
+# TYPE sqld_queries_total counter
+sqld_queries_total{model="a\"b",operation="raw"} 1
+sqld_queries_total{model="accounts",operation="execute"} 3
+# HELP sqld_query_cache_hits_total Structured queries whose SQL came from the query cache.
+# TYPE sqld_query_cache_hits_total counter
+sqld_query_cache_hits_total{model="a\"b",operation="raw"} 0
+sqld_query_cache_hits_total{model="accounts",operation="execute"} 1
+# HELP sqld_query_duration_seconds Duration of queries run by sqld.
+# TYPE sqld_query_duration_seconds histogram
+sqld_query_duration_seconds_bucket{model="a\"b",operation="raw",le="0.5"} 1
+sqld_query_duration_seconds_bucket{model="a\"b",operation="raw",le="1"} 1
+sqld_query_duration_seconds_bucket{model="a\"b",operation="raw",le="+Inf"} 1
+sqld_query_duration_seconds_sum{model="a\"b",operation="raw"} 0.001
+sqld_query_duration_seconds_count{model="a\"b",operation="raw"} 1
+sqld_query_duration_seconds_bucket{model="accounts",operation="execute",le="0.5"} 2
+sqld_query_duration_seconds_bucket{model="accounts",operation="execute",le="1"} 2
+sqld_query_duration_seconds_bucket{model="accounts",operation="execute",le="+Inf"} 3
+sqld_query_duration_seconds_sum{model="accounts",operation="execute"} 2.75
+sqld_query_duration_seconds_count{model="accounts",operation="execute"} 3
+# HELP sqld_query_errors_total Queries run by sqld that failed.
+# TYPE sqld_query_errors_total counter
+sqld_query_errors_total{model="a\"b",operation="raw"} 0
+sqld_query_errors_total{model="accounts",operation="execute"} 1
+# HELP sqld_query_rows_total Rows returned by queries run by sqld.
+# TYPE sqld_query_rows_total counter
+sqld_query_rows_total{model="a\"b",operation="raw"} 0
+sqld_query_rows_total{model="accounts",operation="execute"} 5
+`, out.String())
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	metrics := NewPrometheusMetrics(1, 0.5)
+	metrics.ObserveQuery(QueryStats{Model: "accounts", Operation: OperationExecute, Duration: 250 * time.Millisecond, Rows: 3})
+	metrics.ObserveQuery(QueryStats{Model: "accounts", Operation: OperationExecute, Duration: 2 * time.Second, Err: errors.New("failed")})
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		require.Len(t, family.GetMetric(), 1, family.GetName())
+		metric := family.GetMetric()[0]
+		for _, label := range metric.GetLabel() {
+			assert.Contains(t, []string{"model=accounts", "operation=execute"}, label.GetName()+"="+label.GetValue())
+		}
+		if histogram := metric.GetHistogram(); histogram != nil {
+			values[family.GetName()] = histogram.GetSampleSum()
+			assert.Equal(t, uint64(2), histogram.GetSampleCount())
+			require.Len(t, histogram.GetBucket(), 2)
+			assert.Equal(t, 0.5, histogram.GetBucket()[0].GetUpperBound())
+			assert.Equal(t, uint64(1), histogram.GetBucket()[0].GetCumulativeCount())
+			continue
+		}
+		values[family.GetName()] = metric.GetCounter().GetValue()
+	}
+	assert.Equal(t, map[string]float64{
+		"sqld_queries_total":          2,
+		"sqld_query_errors_total":     1,
+		"sqld_query_rows_total":       3,
+		"sqld_query_cache_hits_total": 0,
+		"sqld_query_duration_seconds": 2.25,
+	}, values)
+}
@@ -0,0 +1,186 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// BatchQuery is a raw query prepared for ExecuteRawBatch by RawBatchQuery.
+type BatchQuery struct {
+	query string
+	args  []interface{}
+	table string // Table of the result model
+	err   error  // Validation error, reported by ExecuteRawBatch
+
+	// scan scans the rows of the query into R and converts them to maps
+	scan func(rows pgx.Rows) ([]map[string]interface{}, error)
+
+	// run executes the query on a database/sql handle through the registry
+	// running the batch
+	run func(ctx context.Context, r *Registry, db interface{}) ([]map[string]interface{}, error)
+}
+
+// RawBatchQuery validates a raw SELECT like ExecuteRaw and prepares it for
+// ExecuteRawBatch. Validation errors are reported by ExecuteRawBatch.
+func RawBatchQuery[P Model, R Model](req ExecuteRawRequest) BatchQuery {
+	return rawBatchQueryWith[P, R](defaultRegistry, req)
+}
+
+// rawBatchQueryWith implements RawBatchQuery with the models, queries and rules
+// of r.
+func rawBatchQueryWith[P Model, R Model](r *Registry, req ExecuteRawRequest) BatchQuery {
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return BatchQuery{err: err}
+	}
+	if err := r.validateSQLSyntax(finalQuery); err != nil {
+		return BatchQuery{err: err}
+	}
+
+	var result R
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return BatchQuery{err: fmt.Errorf("failed to get model metadata: %w", err)}
+	}
+	if err := validateResultColumns(finalQuery, metadata); err != nil {
+		return BatchQuery{err: err}
+	}
+	if err := validateSelectFields(req.SelectFields, metadata); err != nil {
+		return BatchQuery{err: err}
+	}
+	finalQuery, err = r.orderRawQuery(finalQuery, req.OrderBy, metadata)
+	if err != nil {
+		return BatchQuery{err: err}
+	}
+
+	return BatchQuery{
+		query: finalQuery,
+		args:  args,
+		table: metadata.TableName,
+		scan: func(rows pgx.Rows) ([]map[string]interface{}, error) {
+			var structResults []R
+			if err := pgxscan.ScanAll(&structResults, rows); err != nil {
+				return nil, wrapDBError("failed to execute query", err)
+			}
+			return rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+		},
+		run: func(ctx context.Context, runner *Registry, db interface{}) ([]map[string]interface{}, error) {
+			query, args, err := formatPlaceholders(finalQuery, args, req.Placeholders)
+			if err != nil {
+				return nil, err
+			}
+			ctx, cancel, timeout := runner.queryContext(ctx, req.Timeout)
+			defer cancel()
+			var structResults []R
+			err = runner.runRaw(ctx, "sqld.query", metadata.TableName, timeout, query, args, func(ctx context.Context) error {
+				if err := selectAll(ctx, db, &structResults, query, args...); err != nil {
+					return wrapDBError("failed to execute query", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+		},
+	}
+}
+
+// batchSender is implemented by *pgx.Conn, *pgxpool.Pool and pgx.Tx.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// ExecuteRawBatch runs several raw queries and returns their results in order.
+// With a pgx handle the queries are sent in a single pgx.Batch round trip; with
+// a *sql.DB or *sql.Tx they run one after the other. All queries are validated
+// before any is sent, and the first failing query fails the whole batch.
+// The Timeout of each request only applies when the queries run one after the
+// other; bound ctx to limit a pgx batch.
+//
+//	results, err := sqld.ExecuteRawBatch(ctx, pool,
+//	    sqld.RawBatchQuery[DeptParams, HeadcountResult](headcountReq),
+//	    sqld.RawBatchQuery[DeptParams, PayrollResult](payrollReq),
+//	)
+//	headcount, payroll := results[0], results[1]
+func ExecuteRawBatch(ctx context.Context, db interface{}, queries ...BatchQuery) ([][]map[string]interface{}, error) {
+	return executeRawBatchWith(ctx, defaultRegistry, db, queries)
+}
+
+// executeRawBatchWith implements ExecuteRawBatch with the circuit breaker,
+// metrics, tracing and logging of r.
+func executeRawBatchWith(ctx context.Context, r *Registry, db interface{}, queries []BatchQuery) (results [][]map[string]interface{}, err error) {
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, "sqld.execute_raw_batch", "", spanOperationKey.String(OperationRaw))
+	defer func() {
+		endSpan(span, err)
+		for i, q := range queries {
+			stats := QueryStats{Model: q.table, Operation: OperationRaw, Duration: time.Since(start), Err: err}
+			if err == nil {
+				stats.Rows = len(results[i])
+			}
+			r.observe(stats)
+		}
+	}()
+
+	for i, q := range queries {
+		if q.err != nil {
+			return nil, fmt.Errorf("batch query %d: %w", i, q.err)
+		}
+	}
+
+	results = make([][]map[string]interface{}, len(queries))
+	sender, ok := db.(batchSender)
+	if !ok {
+		for i, q := range queries {
+			rows, err := q.run(ctx, r, db)
+			if err != nil {
+				return nil, fmt.Errorf("batch query %d: %w", i, err)
+			}
+			results[i] = rows
+		}
+		return results, nil
+	}
+
+	if err := r.sendRawBatch(ctx, sender, queries, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// sendRawBatch runs queries in a single pgx.Batch, traced as the span
+// sqld.batch, and stores their rows in results.
+func (r *Registry) sendRawBatch(ctx context.Context, sender batchSender, queries []BatchQuery, results [][]map[string]interface{}) (err error) {
+	batch := &pgx.Batch{}
+	models := make([]string, len(queries))
+	for i, q := range queries {
+		models[i] = q.table
+		r.logSQL(ctx, q.table, q.query, q.args)
+		batch.Queue(q.query, q.args...)
+	}
+	ctx, span := r.startSpan(ctx, "sqld.batch", "", spanStatementsKey.Int(batch.Len()))
+	defer func() { endSpan(span, err) }()
+
+	return r.guard(func() error {
+		start := time.Now()
+		defer func() { r.logSlowBatch(ctx, models, batch.Len(), time.Since(start)) }()
+		br := sender.SendBatch(ctx, batch)
+		defer br.Close()
+
+		for i, q := range queries {
+			rows, err := br.Query()
+			if err != nil {
+				return fmt.Errorf("batch query %d: %w", i, wrapDBError("failed to execute query", err))
+			}
+			results[i], err = q.scan(rows)
+			if err != nil {
+				return fmt.Errorf("batch query %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawBatch(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	t.Run("results in order", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+		fake.queue([]string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+		fake.queue([]string{"id", "name"}, []driver.Value{int64(2), "John"}, []driver.Value{int64(3), "Joan"})
+
+		results, err := ExecuteRawBatch(context.Background(), db,
+			RawBatchQuery[TestParams, TestResult](ExecuteRawRequest{
+				Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+				Params: map[string]interface{}{"id": 1},
+			}),
+			RawBatchQuery[TestParams, TestResult](ExecuteRawRequest{
+				Query:        "SELECT id, name FROM test_results WHERE name <> {{name}}",
+				Params:       map[string]interface{}{"name": "Jane"},
+				SelectFields: []string{"name"},
+			}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, [][]map[string]interface{}{
+			{{"id": 1, "name": "Jane"}},
+			{{"name": "John"}, {"name": "Joan"}},
+		}, results)
+		assert.Equal(t, []string{
+			"SELECT id, name FROM test_results WHERE id = $1",
+			"SELECT id, name FROM test_results WHERE name <> $1",
+		}, fake.statements)
+	})
+
+	t.Run("invalid query fails the batch before sending", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+
+		_, err := ExecuteRawBatch(context.Background(), db,
+			RawBatchQuery[TestParams, TestResult](ExecuteRawRequest{
+				Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+				Params: map[string]interface{}{"id": 1},
+			}),
+			RawBatchQuery[TestParams, TestResult](ExecuteRawRequest{Query: "DELETE FROM test_results"}),
+		)
+		assert.EqualError(t, err, "batch query 1: only SELECT statements are allowed")
+		assert.Empty(t, fake.statements)
+	})
+}
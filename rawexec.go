@@ -0,0 +1,211 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+// ExecuteRawExec runs a raw INSERT, UPDATE or DELETE statement with named
+// parameters and returns the number of rows it affected. Parameters are
+// validated against P exactly as in ExecuteRaw; SELECT and DDL statements
+// are rejected. Request.SelectFields is ignored.
+//
+//	n, err := sqld.ExecuteRawExec[RaiseParams](ctx, db, sqld.ExecuteRawRequest{
+//	    Query: `UPDATE employees SET salary = salary * {{factor}} WHERE department = {{department}}`,
+//	    Params: map[string]interface{}{
+//	        "factor":     1.05,
+//	        "department": "Engineering",
+//	    },
+//	})
+func ExecuteRawExec[P Model](ctx context.Context, db interface{}, req ExecuteRawRequest) (int64, error) {
+	return executeRawExecWith[P](ctx, defaultRegistry, db, req)
+}
+
+// executeRawExecWith implements ExecuteRawExec with the models, queries and rules of r.
+func executeRawExecWith[P Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest) (rowsAffected int64, err error) {
+	ctx, setTable, finish := r.startRawExec(ctx, "sqld.execute_raw_exec")
+	defer func() { finish(int(rowsAffected), err) }()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return 0, err
+	}
+	if len(req.OrderBy) > 0 {
+		return 0, fmt.Errorf("OrderBy is only supported for SELECT queries")
+	}
+
+	tables, _, err := r.validateDMLSyntax(finalQuery)
+	if err != nil {
+		return 0, err
+	}
+	table := tables[0]
+	setTable(table)
+
+	finalQuery, args, err = formatPlaceholders(finalQuery, args, req.Placeholders)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	err = r.runRaw(ctx, "sqld.exec", table, timeout, finalQuery, args, func(ctx context.Context) (err error) {
+		rowsAffected, err = execStatement(ctx, db, finalQuery, args...)
+		if err != nil {
+			return wrapDBError("failed to execute statement", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, table := range tables {
+		r.invalidate(table)
+	}
+	return rowsAffected, nil
+}
+
+// ExecuteRawExecReturning runs a raw INSERT, UPDATE or DELETE statement that has
+// a RETURNING clause, and returns the returned rows scanned into R and converted
+// to maps like the results of ExecuteRaw.
+func ExecuteRawExecReturning[P Model, R Model](ctx context.Context, db interface{}, req ExecuteRawRequest) ([]map[string]interface{}, error) {
+	return executeRawExecReturningWith[P, R](ctx, defaultRegistry, db, req)
+}
+
+// executeRawExecReturningWith implements ExecuteRawExecReturning with the models,
+// queries and rules of r.
+func executeRawExecReturningWith[P Model, R Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest) (results []map[string]interface{}, err error) {
+	var result R
+	ctx, finish := r.startRaw(ctx, "sqld.execute_raw_exec", result.TableName())
+	defer func() { finish(len(results), err) }()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.OrderBy) > 0 {
+		return nil, fmt.Errorf("OrderBy is only supported for SELECT queries")
+	}
+
+	tables, returning, err := r.validateDMLSyntax(finalQuery)
+	if err != nil {
+		return nil, err
+	}
+	table := tables[0]
+	if !returning {
+		return nil, fmt.Errorf("statement has no RETURNING clause")
+	}
+
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	finalQuery, args, err = formatPlaceholders(finalQuery, args, req.Placeholders)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	var structResults []R
+	err = r.runRaw(ctx, "sqld.exec", table, timeout, finalQuery, args, func(ctx context.Context) error {
+		if err := selectAll(ctx, db, &structResults, finalQuery, args...); err != nil {
+			return wrapDBError("failed to execute statement", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		r.invalidate(table)
+	}
+	return rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+}
+
+// validateDMLSyntax parses query and checks that it is a single INSERT, UPDATE
+// or DELETE statement. It returns the tables the statement writes to, its
+// target first followed by those of the data-modifying statements of its WITH
+// clause, and whether it has a RETURNING clause. The statement must also pass
+// the rules of r, set with SetQueryRules.
+func (r *Registry) validateDMLSyntax(query string) ([]string, bool, error) {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return nil, false, fmt.Errorf("SQL syntax error: %w", err)
+	}
+	if err := r.checkQueryRules(stmt.AST); err != nil {
+		return nil, false, err
+	}
+
+	switch ast := stmt.AST.(type) {
+	case *tree.Insert:
+		return r.writeTargets(nil, ast.Table, ast.With), tree.HasReturningClause(ast.Returning), nil
+	case *tree.Update:
+		return r.writeTargets(nil, ast.Table, ast.With), tree.HasReturningClause(ast.Returning), nil
+	case *tree.Delete:
+		return r.writeTargets(nil, ast.Table, ast.With), tree.HasReturningClause(ast.Returning), nil
+	default:
+		return nil, false, fmt.Errorf("only INSERT, UPDATE and DELETE statements are allowed")
+	}
+}
+
+// writeTargets appends to tables the name of table and of the tables written by
+// the data-modifying statements of with, as registered models name them,
+// skipping the names already in tables.
+func (r *Registry) writeTargets(tables []string, table tree.TableExpr, with *tree.With) []string {
+	if name := r.registeredTable(dmlTableName(table)); !slices.Contains(tables, name) {
+		tables = append(tables, name)
+	}
+	if with == nil {
+		return tables
+	}
+	for _, cte := range with.CTEList {
+		switch stmt := cte.Stmt.(type) {
+		case *tree.Insert:
+			tables = r.writeTargets(tables, stmt.Table, stmt.With)
+		case *tree.Update:
+			tables = r.writeTargets(tables, stmt.Table, stmt.With)
+		case *tree.Delete:
+			tables = r.writeTargets(tables, stmt.Table, stmt.With)
+		}
+	}
+	return tables
+}
+
+// dmlTableName returns the unquoted name of the table a statement writes to,
+// without its alias.
+func dmlTableName(table tree.TableExpr) string {
+	if aliased, ok := table.(*tree.AliasedTableExpr); ok {
+		table = aliased.Expr
+	}
+	return tree.AsStringWithFlags(table, tree.FmtBareIdentifiers)
+}
+
+// registeredTable returns the name under which a registered model knows the
+// table name, with or without the default schema public. Tables of no
+// registered model are returned unchanged.
+func (r *Registry) registeredTable(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registered := func(table string) bool {
+		for _, metadata := range r.models {
+			if metadata.TableName == table {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case registered(name):
+		return name
+	case strings.HasPrefix(name, "public.") && registered(strings.TrimPrefix(name, "public.")):
+		return strings.TrimPrefix(name, "public.")
+	case !strings.Contains(name, ".") && registered("public."+name):
+		return "public." + name
+	}
+	return name
+}
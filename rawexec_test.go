@@ -0,0 +1,72 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawExec(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 2
+
+	n, err := ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+		Query:  "UPDATE test_results SET name = {{name}} WHERE id = {{id}} OR parent_id = {{id}}",
+		Params: map[string]interface{}{"id": 1, "name": "renamed"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []string{"UPDATE test_results SET name = $1 WHERE id = $2 OR parent_id = $2"}, fake.statements)
+	assert.Equal(t, [][]driver.Value{{"renamed", int64(1)}}, fake.args)
+}
+
+func TestExecuteRawExecReturning(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, _ := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(5), "Jane"})
+
+	rows, err := ExecuteRawExecReturning[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "DELETE FROM test_results WHERE name = {{name}} RETURNING id, name",
+		Params: map[string]interface{}{"name": "Jane"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": 5, "name": "Jane"}}, rows)
+}
+
+func TestExecuteRawExecRejectsStatements(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, _ := newFakeDB(t, nil)
+
+	tests := []struct {
+		name      string
+		query     string
+		returning bool
+		wantErr   string
+	}{
+		{"select", "SELECT id FROM test_results WHERE id = {{id}}", false, "only INSERT, UPDATE and DELETE statements are allowed"},
+		{"ddl", "DROP TABLE test_results", false, "only INSERT, UPDATE and DELETE statements are allowed"},
+		{"syntax error", "UPDATE test_results SET WHERE id = {{id}}", false, "SQL syntax error"},
+		{"missing returning", "DELETE FROM test_results WHERE id = {{id}}", true, "statement has no RETURNING clause"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := ExecuteRawRequest{Query: tt.query}
+			if tt.query != "DROP TABLE test_results" {
+				req.Params = map[string]interface{}{"id": 1}
+			}
+			var err error
+			if tt.returning {
+				_, err = ExecuteRawExecReturning[TestParams, TestResult](context.Background(), db, req)
+			} else {
+				_, err = ExecuteRawExec[TestParams](context.Background(), db, req)
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sqld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+// orderRawQuery validates a raw SELECT and appends an ORDER BY clause built from
+// orderBy. Each entry must name a field of the result model by its db or json
+// name, and is ordered by the field's column, so user input never reaches the
+// SQL text. The query must not have its own top-level ORDER BY, LIMIT or OFFSET.
+func (r *Registry) orderRawQuery(query string, orderBy []OrderByClause, metadata ModelMetadata) (string, error) {
+	if len(orderBy) == 0 {
+		return query, nil
+	}
+	if err := r.validateSQLSyntax(query); err != nil {
+		return "", err
+	}
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return "", fmt.Errorf("SQL syntax error: %w", err)
+	}
+	sel := stmt.AST.(*tree.Select)
+	if sel.OrderBy != nil {
+		return "", fmt.Errorf("OrderBy cannot be used with a query that has an ORDER BY clause")
+	}
+	if sel.Limit != nil {
+		return "", fmt.Errorf("OrderBy cannot be used with a query that has a LIMIT or OFFSET clause")
+	}
+
+	clauses := make([]string, len(orderBy))
+	for i, order := range orderBy {
+		column, ok := resultColumn(order.Field, metadata)
+		if !ok {
+			return "", fmt.Errorf("unknown order field for result model %s: %s", metadata.TableName, order.Field)
+		}
+		direction := "ASC"
+		if order.Desc {
+			direction = "DESC"
+		}
+		clauses[i] = column + " " + direction
+	}
+
+	// The newline keeps a trailing line comment from swallowing the ORDER BY
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	return query + "\nORDER BY " + strings.Join(clauses, ", "), nil
+}
+
+// resultColumn returns the column of the result model field named name, by
+// its db or json name.
+func resultColumn(name string, metadata ModelMetadata) (string, bool) {
+	for _, field := range metadata.Fields {
+		if field.Name == name || field.JSONName == name {
+			return field.Name, true
+		}
+	}
+	return "", false
+}
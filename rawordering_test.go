@@ -0,0 +1,103 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRawQuery(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestResult]())
+	metadata, err := getModelMetadata(TestResult{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		query   string
+		orderBy []OrderByClause
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no order",
+			query: "SELECT id, name FROM test_results",
+			want:  "SELECT id, name FROM test_results",
+		},
+		{
+			name:    "columns and directions",
+			query:   "SELECT id, name FROM test_results -- all rows",
+			orderBy: []OrderByClause{{Field: "name", Desc: true}, {Field: "id"}},
+			want:    "SELECT id, name FROM test_results -- all rows\nORDER BY name DESC, id ASC",
+		},
+		{
+			name:    "trailing semicolon",
+			query:   "SELECT id, name FROM test_results;",
+			orderBy: []OrderByClause{{Field: "id"}},
+			want:    "SELECT id, name FROM test_results\nORDER BY id ASC",
+		},
+		{
+			name:    "unknown field",
+			query:   "SELECT id, name FROM test_results",
+			orderBy: []OrderByClause{{Field: "id; DROP TABLE test_results"}},
+			wantErr: "unknown order field for result model test_results: id; DROP TABLE test_results",
+		},
+		{
+			name:    "existing order by",
+			query:   "SELECT id, name FROM test_results ORDER BY id",
+			orderBy: []OrderByClause{{Field: "name"}},
+			wantErr: "OrderBy cannot be used with a query that has an ORDER BY clause",
+		},
+		{
+			name:    "existing limit",
+			query:   "SELECT id, name FROM test_results LIMIT 10",
+			orderBy: []OrderByClause{{Field: "name"}},
+			wantErr: "OrderBy cannot be used with a query that has a LIMIT or OFFSET clause",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaultRegistry.orderRawQuery(tt.query, tt.orderBy, metadata)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecuteRawPaginatedOrderBy(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+
+	_, err := ExecuteRawPaginated[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:   "SELECT id, name FROM test_results WHERE id > {{id}}",
+		Params:  map[string]interface{}{"id": 0},
+		OrderBy: []OrderByClause{{Field: "name", Desc: true}},
+	}, &PaginationRequest{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, name FROM test_results WHERE id > $1\nORDER BY name DESC\nLIMIT $2 OFFSET $3", fake.statements[1])
+
+	_, err = ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+		Query:   "DELETE FROM test_results WHERE id = {{id}}",
+		Params:  map[string]interface{}{"id": 1},
+		OrderBy: []OrderByClause{{Field: "name"}},
+	})
+	assert.EqualError(t, err, "OrderBy is only supported for SELECT queries")
+}
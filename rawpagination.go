@@ -0,0 +1,139 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+// RawPaginatedResponse is the result of ExecuteRawPaginated.
+type RawPaginatedResponse struct {
+	Data       []map[string]interface{} `json:"data"`
+	Pagination *PaginationResponse      `json:"pagination"`
+	Schema     []ResultField            `json:"schema,omitempty"` // Set if req.WithSchema is set, see RawResultSchema
+}
+
+// ExecuteRawPaginated runs a raw SELECT like ExecuteRaw and returns one page of its
+// rows together with the same pagination metadata as Execute.
+//
+// The total is counted with SELECT COUNT(*) FROM (query), and the page is selected
+// by appending LIMIT and OFFSET as bound parameters, so the query itself must not
+// have a LIMIT or OFFSET. Give it an ORDER BY, or set req.OrderBy, to make pages stable.
+// A nil pagination request returns the first page of DefaultPageSize rows.
+//
+//	resp, err := sqld.ExecuteRawPaginated[QueryParams, EmployeeResult](ctx, db, sqld.ExecuteRawRequest{
+//	    Query:  `SELECT id, first_name, salary FROM employees WHERE department = {{department}} ORDER BY id`,
+//	    Params: map[string]interface{}{"department": "Engineering"},
+//	}, &sqld.PaginationRequest{Page: 2, PageSize: 20})
+func ExecuteRawPaginated[P Model, R Model](ctx context.Context, db interface{}, req ExecuteRawRequest, pagination *PaginationRequest) (RawPaginatedResponse, error) {
+	return executeRawPaginatedWith[P, R](ctx, defaultRegistry, db, req, pagination)
+}
+
+// executeRawPaginatedWith implements ExecuteRawPaginated with the models,
+// queries and rules of r.
+func executeRawPaginatedWith[P Model, R Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest, pagination *PaginationRequest) (resp RawPaginatedResponse, err error) {
+	var result R
+	ctx, finish := r.startRaw(ctx, "sqld.execute_raw_paginated", result.TableName())
+	defer func() { finish(len(resp.Data), err) }()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return RawPaginatedResponse{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	finalQuery, err = r.orderRawQuery(finalQuery, req.OrderBy, metadata)
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+	countQuery, pageQuery, err := r.paginateRawQuery(finalQuery, len(args))
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+	if err := validateResultColumns(finalQuery, metadata); err != nil {
+		return RawPaginatedResponse{}, err
+	}
+	if err := validateSelectFields(req.SelectFields, metadata); err != nil {
+		return RawPaginatedResponse{}, err
+	}
+
+	countQuery, countArgs, err := formatPlaceholders(countQuery, args, req.Placeholders)
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+	// The timeout covers both the count and the page query
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	var totalItems int
+	err = r.runRaw(ctx, "sqld.count", metadata.TableName, timeout, countQuery, countArgs, func(ctx context.Context) error {
+		if err := getOne(ctx, db, &totalItems, countQuery, countArgs...); err != nil {
+			return wrapDBError("failed to get total count", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+
+	pagination = ValidatePagination(pagination)
+	limit, offset := pagination.PageSize, CalculateOffset(pagination.Page, pagination.PageSize)
+	pageQuery, pageArgs, err := formatPlaceholders(pageQuery, append(args[:len(args):len(args)], limit, offset), req.Placeholders)
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+
+	structResults := make([]R, 0, pageRowCount(totalItems, &limit, &offset))
+	err = r.runRaw(ctx, "sqld.query", metadata.TableName, timeout, pageQuery, pageArgs, func(ctx context.Context) error {
+		if err := selectAll(ctx, db, &structResults, pageQuery, pageArgs...); err != nil {
+			return wrapDBError("failed to execute query", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+
+	data, err := rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+	if err != nil {
+		return RawPaginatedResponse{}, err
+	}
+	resp = RawPaginatedResponse{
+		Data:       data,
+		Pagination: CalculatePagination(totalItems, pagination.PageSize, pagination.Page),
+	}
+	if req.WithSchema {
+		resp.Schema = resultSchema(reflect.TypeOf(result), metadata, req.SelectFields)
+	}
+	return resp, nil
+}
+
+// paginateRawQuery validates a raw SELECT with numArgs positional parameters
+// against the rules of r and returns the query counting its rows and the query
+// selecting one page of them. The page query takes the limit and offset as
+// parameters numArgs+1 and numArgs+2.
+func (r *Registry) paginateRawQuery(query string, numArgs int) (string, string, error) {
+	if err := r.validateSQLSyntax(query); err != nil {
+		return "", "", err
+	}
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return "", "", fmt.Errorf("SQL syntax error: %w", err)
+	}
+	if sel := stmt.AST.(*tree.Select); sel.Limit != nil {
+		return "", "", fmt.Errorf("paginated queries must not have a LIMIT or OFFSET clause")
+	}
+
+	// The newlines keep a trailing line comment from swallowing the added clauses
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	countQuery := "SELECT COUNT(*) FROM (\n" + query + "\n) AS sqld_page"
+	pageQuery := fmt.Sprintf("%s\nLIMIT $%d OFFSET $%d", query, numArgs+1, numArgs+2)
+	return countQuery, pageQuery, nil
+}
@@ -0,0 +1,88 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawPaginated(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, fake := newFakeDB(t, nil)
+	fake.queue([]string{"count"}, []driver.Value{int64(25)})
+	fake.queue([]string{"id", "name"}, []driver.Value{int64(11), "Jane"}, []driver.Value{int64(12), "John"})
+
+	resp, err := ExecuteRawPaginated[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE name = {{name}} ORDER BY id;",
+		Params: map[string]interface{}{"name": "J"},
+	}, &PaginationRequest{Page: 2, PageSize: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]interface{}{
+		{"id": 11, "name": "Jane"},
+		{"id": 12, "name": "John"},
+	}, resp.Data)
+	assert.Equal(t, &PaginationResponse{Page: 2, PageSize: 10, TotalItems: 25, TotalPages: 3}, resp.Pagination)
+	assert.Equal(t, []string{
+		"SELECT COUNT(*) FROM (\nSELECT id, name FROM test_results WHERE name = $1 ORDER BY id\n) AS sqld_page",
+		"SELECT id, name FROM test_results WHERE name = $1 ORDER BY id\nLIMIT $2 OFFSET $3",
+	}, fake.statements)
+	assert.Equal(t, [][]driver.Value{{"J"}, {"J", int64(10), int64(10)}}, fake.args)
+}
+
+func TestPaginateRawQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		numArgs   int
+		wantCount string
+		wantPage  string
+		wantErr   string
+	}{
+		{
+			name:      "trailing comment",
+			query:     "SELECT id FROM t -- all rows",
+			wantCount: "SELECT COUNT(*) FROM (\nSELECT id FROM t -- all rows\n) AS sqld_page",
+			wantPage:  "SELECT id FROM t -- all rows\nLIMIT $1 OFFSET $2",
+		},
+		{
+			name:      "union",
+			query:     "SELECT id FROM a WHERE x = $1 UNION SELECT id FROM b WHERE y = $2",
+			numArgs:   2,
+			wantCount: "SELECT COUNT(*) FROM (\nSELECT id FROM a WHERE x = $1 UNION SELECT id FROM b WHERE y = $2\n) AS sqld_page",
+			wantPage:  "SELECT id FROM a WHERE x = $1 UNION SELECT id FROM b WHERE y = $2\nLIMIT $3 OFFSET $4",
+		},
+		{
+			name:    "existing limit",
+			query:   "SELECT id FROM t LIMIT 5",
+			wantErr: "paginated queries must not have a LIMIT or OFFSET clause",
+		},
+		{
+			name:    "existing offset",
+			query:   "SELECT id FROM t OFFSET 5",
+			wantErr: "paginated queries must not have a LIMIT or OFFSET clause",
+		},
+		{
+			name:    "not a select",
+			query:   "DELETE FROM t",
+			wantErr: "only SELECT statements are allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			countQuery, pageQuery, err := defaultRegistry.paginateRawQuery(tt.query, tt.numArgs)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCount, countQuery)
+			assert.Equal(t, tt.wantPage, pageQuery)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package sqld
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// startRaw starts the span name of a raw call on table, the table of its
+// result model or of the table it writes, and returns a context holding it
+// with the function ending the call. finish ends the span and reports the
+// call, which returned rows and err, to the metrics of r.
+func (r *Registry) startRaw(ctx context.Context, name, table string) (context.Context, func(rows int, err error)) {
+	return r.startCall(ctx, OperationRaw, name, table)
+}
+
+// startWrite is startRaw for the writes of models, such as ExecuteInsert, on
+// their table, reported as OperationWrite.
+func (r *Registry) startWrite(ctx context.Context, name, table string) (context.Context, func(rows int, err error)) {
+	return r.startCall(ctx, OperationWrite, name, table)
+}
+
+// startRawExec is startRaw for a raw statement whose table is only known once
+// the statement is parsed. setTable records the table for the span and for
+// the stats finish reports, which have no model if the call fails before.
+func (r *Registry) startRawExec(ctx context.Context, name string) (context.Context, func(table string), func(rows int, err error)) {
+	return r.startUntabled(ctx, OperationRaw, name)
+}
+
+// startCall implements startRaw and startWrite for calls of operation.
+func (r *Registry) startCall(ctx context.Context, operation, name, table string) (context.Context, func(rows int, err error)) {
+	ctx, setTable, finish := r.startUntabled(ctx, operation, name)
+	setTable(table)
+	return ctx, finish
+}
+
+// startUntabled implements startCall and startRawExec.
+func (r *Registry) startUntabled(ctx context.Context, operation, name string) (context.Context, func(table string), func(rows int, err error)) {
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, name, "", spanOperationKey.String(operation))
+	var table string
+	setTable := func(t string) {
+		table = t
+		if t != "" {
+			span.SetAttributes(attribute.String("db.sql.table", t))
+		}
+	}
+	return ctx, setTable, func(rows int, err error) {
+		span.SetAttributes(spanRowsKey.Int(rows))
+		endSpan(span, err)
+		r.observe(QueryStats{
+			Model:     table,
+			Operation: operation,
+			Duration:  time.Since(start),
+			Rows:      rows,
+			Err:       err,
+		})
+	}
+}
+
+// runRaw runs query, a statement of a raw call or a write on table, with fn,
+// in the span name. Like the statements of Execute, the query goes through the circuit
+// breaker of r and is logged, if ctx comes from WithSQLLogging, and logged as
+// slow if it is. Errors of fn are reported as ErrQueryTimeout if ctx reached
+// timeout, see queryContext.
+func (r *Registry) runRaw(ctx context.Context, name, table string, timeout time.Duration, query string, args []interface{}, fn func(ctx context.Context) error) (err error) {
+	ctx, span := r.startSpan(ctx, name, table, spanSQL(query))
+	defer func() { endSpan(span, err) }()
+	r.logSQL(ctx, table, query, args)
+
+	return r.guard(func() error {
+		start := time.Now()
+		err := fn(ctx)
+		r.logSlowQuery(ctx, table, query, args, time.Since(start))
+		return timeoutError(ctx, timeout, err)
+	})
+}
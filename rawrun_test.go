@@ -0,0 +1,164 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRawFunctionsInstrumented(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	metrics := &recordedMetrics{}
+	registry := NewRegistry()
+	registry.Configure(Config{
+		QueryTimeout:   10 * time.Millisecond,
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	})
+	registry.UseMetrics(metrics)
+	registry.UseTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+	ctx := context.Background()
+	req := ExecuteRawRequest{Query: "SELECT id, name FROM test_results ORDER BY id"}
+
+	// Each statement of a paginated query is traced under the call
+	resp, err := executeRawPaginatedWith[TestParams, TestResult](ctx, registry, db, req, nil)
+	require.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	require.Equal(t, []string{"sqld.count", "sqld.query", "sqld.execute_raw_paginated"}, names)
+	call := spans[2]
+	for _, span := range spans[:2] {
+		assert.Equal(t, call.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+	attrs := spanAttributes(call)
+	assert.Equal(t, OperationRaw, attrs[spanOperationKey].AsString())
+	assert.Equal(t, int64(1), attrs[spanRowsKey].AsInt64())
+
+	require.Len(t, metrics.stats, 1)
+	assert.Equal(t, QueryStats{
+		Model:     "test_results",
+		Operation: OperationRaw,
+		Duration:  metrics.stats[0].Duration,
+		Rows:      1,
+	}, metrics.stats[0])
+
+	// A timeout opens the circuit for every raw function
+	fake.delay = time.Second
+	_, err = executeRawSingleWith[TestParams, TestResult](ctx, registry, db, req)
+	var timeoutErr *ErrQueryTimeout
+	require.ErrorAs(t, err, &timeoutErr)
+	fake.delay = 0
+
+	var open *ErrCircuitOpen
+	err = executeRawStreamWith[TestParams, TestResult](ctx, registry, db, req, func(map[string]interface{}) error { return nil })
+	require.ErrorAs(t, err, &open)
+	_, err = executeRawBatchWith(ctx, registry, db, []BatchQuery{rawBatchQueryWith[TestParams, TestResult](registry, req)})
+	require.ErrorAs(t, err, &open)
+	assert.Len(t, fake.statements, 2)
+
+	require.Len(t, metrics.stats, 4)
+	assert.ErrorAs(t, metrics.stats[1].Err, &timeoutErr)
+	assert.ErrorAs(t, metrics.stats[3].Err, &open)
+}
+
+func TestWritesInstrumented(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	metrics := &recordedMetrics{}
+	registry := NewRegistry()
+	registry.Configure(Config{
+		QueryTimeout:   10 * time.Millisecond,
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	})
+	registry.UseMetrics(metrics)
+	registry.UseTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 1
+	ctx := context.Background()
+
+	resp, err := executeInsertWith[BuilderTestModel](ctx, registry, db, InsertRequest{Values: map[string]interface{}{"name": "Jane"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "sqld.exec", spans[0].Name())
+	assert.Equal(t, "INSERT INTO test_models (name) VALUES ($1)", spanAttributes(spans[0])["db.statement"].AsString())
+	assert.Equal(t, "sqld.execute_insert", spans[1].Name())
+	assert.Equal(t, OperationWrite, spanAttributes(spans[1])[spanOperationKey].AsString())
+	require.Len(t, metrics.stats, 1)
+	assert.Equal(t, QueryStats{
+		Model:     "test_models",
+		Operation: OperationWrite,
+		Duration:  metrics.stats[0].Duration,
+		Rows:      1,
+	}, metrics.stats[0])
+
+	// A timeout of a bulk insert opens the circuit for the other writes
+	fake.delay = time.Second
+	_, err = executeBulkInsertWith[BuilderTestModel](ctx, registry, db, BulkInsertRequest{Rows: []map[string]interface{}{{"name": "John"}}})
+	var timeoutErr *ErrQueryTimeout
+	require.ErrorAs(t, err, &timeoutErr)
+	fake.delay = 0
+
+	var open *ErrCircuitOpen
+	_, err = executeImportWith[BuilderTestModel](ctx, registry, db, strings.NewReader(`{"name": "Joe"}`), ImportRequest{Format: ImportNDJSON})
+	require.ErrorAs(t, err, &open)
+	_, err = executeInsertWith[BuilderTestModel](ctx, registry, db, InsertRequest{Values: map[string]interface{}{"name": "Joe"}})
+	require.ErrorAs(t, err, &open)
+	assert.Len(t, fake.statements, 1)
+
+	require.Len(t, metrics.stats, 4)
+	assert.ErrorAs(t, metrics.stats[1].Err, &timeoutErr)
+	assert.ErrorAs(t, metrics.stats[2].Err, &open)
+}
+
+func TestRawExecInstrumentedBeforeParsing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	metrics := &recordedMetrics{}
+	registry := NewRegistry()
+	registry.UseMetrics(metrics)
+	registry.UseTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	db, _ := newFakeDB(t, nil)
+	ctx := context.Background()
+
+	// Statements rejected before they run are traced and reported too
+	_, err := executeRawExecWith[TestParams](ctx, registry, db, ExecuteRawRequest{Query: "SELECT id FROM test_results"})
+	require.EqualError(t, err, "only INSERT, UPDATE and DELETE statements are allowed")
+	_, err = executeRawExecWith[TestParams](ctx, registry, db, ExecuteRawRequest{Query: "DELETE FROM test_results WHERE id = {{missing}}"})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, "sqld.execute_raw_exec", span.Name())
+		assert.NotEmpty(t, span.Events(), "error recorded")
+	}
+	require.Len(t, metrics.stats, 2)
+	assert.Empty(t, metrics.stats[0].Model)
+	assert.Error(t, metrics.stats[0].Err)
+
+	// Once parsed, the statement's table labels the call
+	_, err = executeRawExecWith[TestParams](ctx, registry, db, ExecuteRawRequest{
+		Query:  "DELETE FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, metrics.stats, 3)
+	assert.Equal(t, "test_results", metrics.stats[2].Model)
+	assert.Equal(t, "test_results", spanAttributes(recorder.Ended()[3])["db.sql.table"].AsString())
+}
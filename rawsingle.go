@@ -0,0 +1,123 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+var (
+	// ErrNoRows is returned by ExecuteRawSingle when the query returns no rows.
+	ErrNoRows = errors.New("query returned no rows")
+
+	// ErrTooManyRows is returned by ExecuteRawSingle when the query returns more than one row.
+	ErrTooManyRows = errors.New("query returned more than one row")
+)
+
+// ExecuteRawSingle runs a raw SELECT like ExecuteRaw and returns its only row.
+// It fails with ErrNoRows if there is no row and with ErrTooManyRows if there
+// is more than one, so fetch-by-id queries need no length checks:
+//
+//	employee, err := sqld.ExecuteRawSingle[IDParams, EmployeeResult](ctx, db, sqld.ExecuteRawRequest{
+//	    Query:  `SELECT id, first_name FROM employees WHERE id = {{id}}`,
+//	    Params: map[string]interface{}{"id": 42},
+//	})
+//	if errors.Is(err, sqld.ErrNoRows) {
+//	    // 404
+//	}
+//
+// Unless the query has its own LIMIT, at most two rows are fetched.
+func ExecuteRawSingle[P Model, R Model](ctx context.Context, db interface{}, req ExecuteRawRequest) (map[string]interface{}, error) {
+	return executeRawSingleWith[P, R](ctx, defaultRegistry, db, req)
+}
+
+// executeRawSingleWith implements ExecuteRawSingle with the models, queries and rules of r.
+func executeRawSingleWith[P Model, R Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest) (row map[string]interface{}, err error) {
+	var result R
+	ctx, finish := r.startRaw(ctx, "sqld.execute_raw_single", result.TableName())
+	defer func() {
+		rows := 0
+		if row != nil {
+			rows = 1
+		}
+		finish(rows, err)
+	}()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	finalQuery, err = r.orderRawQuery(finalQuery, req.OrderBy, metadata)
+	if err != nil {
+		return nil, err
+	}
+	singleQuery, err := r.limitRawQuery(finalQuery, 2)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateResultColumns(finalQuery, metadata); err != nil {
+		return nil, err
+	}
+	if err := validateSelectFields(req.SelectFields, metadata); err != nil {
+		return nil, err
+	}
+
+	singleQuery, args, err = formatPlaceholders(singleQuery, args, req.Placeholders)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	structResults := make([]R, 0, 2)
+	err = r.runRaw(ctx, "sqld.query", metadata.TableName, timeout, singleQuery, args, func(ctx context.Context) error {
+		if err := selectAll(ctx, db, &structResults, singleQuery, args...); err != nil {
+			return wrapDBError("failed to execute query", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch len(structResults) {
+	case 0:
+		return nil, ErrNoRows
+	case 1:
+	default:
+		return nil, ErrTooManyRows
+	}
+
+	results, err := rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// limitRawQuery validates a raw SELECT against the rules of r and appends
+// LIMIT n to it, unless it already has a top-level LIMIT or OFFSET.
+func (r *Registry) limitRawQuery(query string, n int) (string, error) {
+	if err := r.validateSQLSyntax(query); err != nil {
+		return "", err
+	}
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return "", fmt.Errorf("SQL syntax error: %w", err)
+	}
+	if sel := stmt.AST.(*tree.Select); sel.Limit != nil {
+		return query, nil
+	}
+
+	// The newline keeps a trailing line comment from swallowing the LIMIT
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	return fmt.Sprintf("%s\nLIMIT %d", query, n), nil
+}
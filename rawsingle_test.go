@@ -0,0 +1,50 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawSingle(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	req := ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}};",
+		Params: map[string]interface{}{"id": 1},
+	}
+
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{name: "one row", rows: [][]driver.Value{{int64(1), "Jane"}}, want: map[string]interface{}{"id": 1, "name": "Jane"}},
+		{name: "no rows", wantErr: ErrNoRows},
+		{name: "two rows", rows: [][]driver.Value{{int64(1), "Jane"}, {int64(1), "John"}}, wantErr: ErrTooManyRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, []string{"id", "name"}, tt.rows...)
+
+			got, err := ExecuteRawSingle[TestParams, TestResult](context.Background(), db, req)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE id = $1\nLIMIT 2"}, fake.statements)
+		})
+	}
+}
+
+func TestLimitRawQuery(t *testing.T) {
+	got, err := defaultRegistry.limitRawQuery("SELECT id FROM t ORDER BY id LIMIT 1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t ORDER BY id LIMIT 1", got)
+
+	_, err = defaultRegistry.limitRawQuery("DELETE FROM t", 2)
+	assert.EqualError(t, err, "only SELECT statements are allowed")
+}
@@ -0,0 +1,167 @@
+package sqld
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExecuteRawStream runs a raw SELECT like ExecuteRaw, but scans the rows one at
+// a time and passes each to fn instead of returning them all, so large exports
+// never hold the whole result in memory. Iteration stops at the first error
+// returned by fn, which ExecuteRawStream returns.
+//
+//	err := sqld.ExecuteRawStream[QueryParams, EmployeeResult](ctx, db, req, func(row map[string]interface{}) error {
+//	    return encoder.Encode(row)
+//	})
+func ExecuteRawStream[P Model, R Model](ctx context.Context, db interface{}, req ExecuteRawRequest, fn func(row map[string]interface{}) error) error {
+	return executeRawStreamWith[P, R](ctx, defaultRegistry, db, req, fn)
+}
+
+// executeRawStreamWith implements ExecuteRawStream with the models, queries and rules of r.
+func executeRawStreamWith[P Model, R Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest, fn func(row map[string]interface{}) error) (err error) {
+	var result R
+	var rows int
+	ctx, finish := r.startRaw(ctx, "sqld.execute_raw_stream", result.TableName())
+	defer func() { finish(rows, err) }()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return err
+	}
+	if err := r.validateSQLSyntax(finalQuery); err != nil {
+		return err
+	}
+
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := validateResultColumns(finalQuery, metadata); err != nil {
+		return err
+	}
+	if err := validateSelectFields(req.SelectFields, metadata); err != nil {
+		return err
+	}
+	finalQuery, err = r.orderRawQuery(finalQuery, req.OrderBy, metadata)
+	if err != nil {
+		return err
+	}
+
+	finalQuery, args, err = formatPlaceholders(finalQuery, args, req.Placeholders)
+	if err != nil {
+		return err
+	}
+	// The timeout, like the span and the slow query log, covers reading the
+	// rows, including the time spent in fn
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+	plan := rawResultPlan[R](r, metadata, req.SelectFields)
+	return r.runRaw(ctx, "sqld.query", metadata.TableName, timeout, finalQuery, args, func(ctx context.Context) error {
+		it, err := queryRows(ctx, db, finalQuery, args...)
+		if err != nil {
+			return wrapDBError("failed to execute query", err)
+		}
+		defer it.Close()
+
+		for it.Next() {
+			var row R
+			if err := it.Scan(&row); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			resultMap, err := plan.structRow(reflect.ValueOf(row))
+			if err != nil {
+				return err
+			}
+			if err := fn(resultMap); err != nil {
+				return err
+			}
+			rows++
+		}
+		if err := it.Err(); err != nil {
+			return wrapDBError("failed to read rows", err)
+		}
+		return nil
+	})
+}
+
+// rowIterator scans the rows of a query one at a time into structs.
+type rowIterator interface {
+	Next() bool
+	Scan(dest interface{}) error
+	Err() error
+	Close()
+}
+
+// queryRows runs query and returns an iterator over its rows. The connection
+// taken by acquireConn, if any, is released when the iterator is closed.
+func queryRows(ctx context.Context, db interface{}, query string, args ...interface{}) (rowIterator, error) {
+	db, release, err := acquireConn(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var sqlRows *sql.Rows
+	var pgxRows pgx.Rows
+	switch db := db.(type) {
+	case *sql.DB:
+		sqlRows, err = db.QueryContext(ctx, query, args...)
+	case *sql.Conn:
+		sqlRows, err = db.QueryContext(ctx, query, args...)
+	case *sql.Tx:
+		sqlRows, err = db.QueryContext(ctx, query, args...)
+	case *pgx.Conn:
+		pgxRows, err = db.Query(ctx, query, args...)
+	case *pgxpool.Pool:
+		pgxRows, err = db.Query(ctx, query, args...)
+	case pgx.Tx:
+		pgxRows, err = db.Query(ctx, query, args...)
+	default:
+		err = &ErrUnsupportedDB{DB: db}
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if sqlRows != nil {
+		return &sqlRowIterator{rows: sqlRows, scanner: sqlscan.NewRowScanner(sqlRows), release: release}, nil
+	}
+	return &pgxRowIterator{rows: pgxRows, scanner: pgxscan.NewRowScanner(pgxRows), release: release}, nil
+}
+
+// sqlRowIterator iterates over database/sql rows.
+type sqlRowIterator struct {
+	rows    *sql.Rows
+	scanner *sqlscan.RowScanner
+	release func() // Releases the connection of rows
+}
+
+func (it *sqlRowIterator) Next() bool                  { return it.rows.Next() }
+func (it *sqlRowIterator) Scan(dest interface{}) error { return it.scanner.Scan(dest) }
+func (it *sqlRowIterator) Err() error                  { return it.rows.Err() }
+func (it *sqlRowIterator) Close() {
+	it.rows.Close()
+	it.release()
+}
+
+// pgxRowIterator iterates over pgx rows.
+type pgxRowIterator struct {
+	rows    pgx.Rows
+	scanner *pgxscan.RowScanner
+	release func() // Releases the connection of rows
+}
+
+func (it *pgxRowIterator) Next() bool                  { return it.rows.Next() }
+func (it *pgxRowIterator) Scan(dest interface{}) error { return it.scanner.Scan(dest) }
+func (it *pgxRowIterator) Err() error                  { return it.rows.Err() }
+func (it *pgxRowIterator) Close() {
+	it.rows.Close()
+	it.release()
+}
@@ -0,0 +1,80 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawStream(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	req := ExecuteRawRequest{
+		Query:        "SELECT id, name FROM test_results WHERE id > {{id}} ORDER BY id",
+		Params:       map[string]interface{}{"id": 0},
+		SelectFields: []string{"name"},
+	}
+	rows := [][]driver.Value{{int64(1), "Jane"}, {int64(2), "John"}, {int64(3), "Joan"}}
+
+	t.Run("all rows", func(t *testing.T) {
+		db, fake := newFakeDB(t, []string{"id", "name"}, rows...)
+
+		var got []map[string]interface{}
+		err := ExecuteRawStream[TestParams, TestResult](context.Background(), db, req, func(row map[string]interface{}) error {
+			got = append(got, row)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{{"name": "Jane"}, {"name": "John"}, {"name": "Joan"}}, got)
+		assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE id > $1 ORDER BY id"}, fake.statements)
+	})
+
+	t.Run("callback error stops iteration", func(t *testing.T) {
+		db, _ := newFakeDB(t, []string{"id", "name"}, rows...)
+		errStop := errors.New("stop")
+
+		calls := 0
+		err := ExecuteRawStream[TestParams, TestResult](context.Background(), db, req, func(row map[string]interface{}) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("unsupported db", func(t *testing.T) {
+		err := ExecuteRawStream[TestParams, TestResult](context.Background(), "db", req, func(map[string]interface{}) error {
+			return nil
+		})
+		var unsupported *ErrUnsupportedDB
+		assert.ErrorAs(t, err, &unsupported)
+	})
+
+	t.Run("acquire timeout", func(t *testing.T) {
+		db, _ := newFakeDB(t, []string{"id", "name"}, rows...)
+		registry := NewRegistry()
+		registry.Configure(Config{AcquireTimeout: 10 * time.Millisecond})
+		stream := func() error {
+			return executeRawStreamWith[TestParams, TestResult](context.Background(), registry, db, req, func(map[string]interface{}) error {
+				return nil
+			})
+		}
+
+		// With the only connection held, the stream waits no longer than the acquire timeout
+		db.SetMaxOpenConns(1)
+		held, err := db.Conn(context.Background())
+		require.NoError(t, err)
+		assert.ErrorContains(t, stream(), "no connection available within 10ms")
+		require.NoError(t, held.Close())
+
+		// The connection is released once the rows are read
+		require.NoError(t, stream())
+		assert.Zero(t, db.Stats().InUse)
+		require.NoError(t, stream())
+	})
+}
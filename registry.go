@@ -2,27 +2,79 @@ package sqld
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Registry is a type-safe registry for model metadata and scanners
 type Registry struct {
-	models   map[reflect.Type]ModelMetadata
-	scanners map[reflect.Type]func() sql.Scanner
-	mu       sync.RWMutex
+	models     map[reflect.Type]ModelMetadata
+	failed     map[reflect.Type]error
+	inflight   map[reflect.Type]chan struct{} // Closed when the registration of the type ends
+	scanners   map[reflect.Type]func() sql.Scanner
+	converters map[reflect.Type]ValueConverter
+	mu         sync.RWMutex
+
+	normalizers map[reflect.Type]reflect.Type // Underlying types of custom types, see RegisterTypeNormalizer
+	enums       map[reflect.Type]Enum         // PostgreSQL enums of Go string types, see RegisterEnum
+	enumCatalog map[string][]string           // Labels of the database's enums by type, see LoadEnumLabels
+
+	invalidationHandlers []InvalidationHandler
+	refreshedAt          map[string]time.Time // Refresh times of materialized views by table
+
+	queries     map[string]registeredQuery // Registered raw queries by name
+	queryHashes map[string]registeredQuery // Registered raw queries by hash
+	rawLocked   bool                       // Only registered raw queries may run
+	queryRules  QueryRules                 // Constructs denied in raw queries
+	inferNames  bool                       // Derive missing db and json tags from field names
+	requireAll  bool                       // Make inserts provide every required field
+
+	resultLocation *time.Location // Zone timestamps in results are converted to, see SetResultLocation
+	numericStrings bool           // Return numeric results as strings, see RenderNumericsAsStrings
+
+	relations map[reflect.Type]map[string]Relationship // Relationships by model and name
+	rawTags   map[reflect.Type]*rawStructTags          // Tags of raw query structs, see rawStructTags
+
+	prepare bool                   // Run structured queries as prepared statements, see PrepareStatements
+	stmts   map[*sql.DB]*stmtCache // Prepared statements by database, see preparedStmt
+	stmtsMu sync.Mutex             // Guards stmts and their statements
+
+	acquireTimeout time.Duration // How long queries wait for a pool connection, see Configure
+	queryTimeout   time.Duration // Timeout of queries without their own, see Configure
+	logger         Logger        // Logger of sqld, see UseLogger
+
+	slowQueryThreshold time.Duration // Duration above which statements are logged, see Config.SlowQueryThreshold
+
+	breaker *CircuitBreaker // Circuit breaker of queries, see UseCircuitBreaker
+	metrics Metrics         // Receiver of query measurements, see UseMetrics
+	tracer  trace.Tracer    // Tracer of query spans, see UseTracerProvider
 }
 
 // NewRegistry returns a new instance of the registry
 func NewRegistry() *Registry {
 	return &Registry{
-		models:   make(map[reflect.Type]ModelMetadata),
-		scanners: make(map[reflect.Type]func() sql.Scanner),
+		models:      make(map[reflect.Type]ModelMetadata),
+		failed:      make(map[reflect.Type]error),
+		inflight:    make(map[reflect.Type]chan struct{}),
+		refreshedAt: make(map[string]time.Time),
+		scanners:    defaultScanners(),
+		converters:  defaultConverters(),
+		normalizers: make(map[reflect.Type]reflect.Type),
+		enums:       make(map[reflect.Type]Enum),
+		queries:     make(map[string]registeredQuery),
+		queryHashes: make(map[string]registeredQuery),
+		relations:   make(map[reflect.Type]map[string]Relationship),
+		rawTags:     make(map[reflect.Type]*rawStructTags),
+		stmts:       make(map[*sql.DB]*stmtCache),
 	}
 }
 
@@ -35,35 +87,26 @@ func Register[T Model]() error {
 	return defaultRegistry.Register(model)
 }
 
+// MustRegisterAll registers all models in the default registry and panics if any
+// of them is invalid. It is meant for deterministic registration at startup, so
+// that model errors surface immediately instead of on the first request.
+//
+//	sqld.MustRegisterAll(Employee{}, Account{})
+func MustRegisterAll(models ...Model) {
+	if err := defaultRegistry.RegisterAll(models...); err != nil {
+		panic(err)
+	}
+}
+
 // RegisterScanner registers a function that creates scanners for a specific type
 func RegisterScanner(t reflect.Type, scannerFactory func() sql.Scanner) {
 	defaultRegistry.RegisterScanner(t, scannerFactory)
 }
 
-// getModelMetadata retrieves metadata for a model type
+// getModelMetadata retrieves metadata for a model type, registering the model
+// in the default registry on first use.
 func getModelMetadata(model Model) (ModelMetadata, error) {
-	// First attempt to get from registry
-	metadata, err := defaultRegistry.GetModelMetadata(model)
-	if err != nil {
-		// Check if it's a "not registered" error
-		var notRegistered *ErrModelNotRegistered
-		if errors.As(err, &notRegistered) {
-			// Attempt lazy registration with proper locking
-			if regErr := defaultRegistry.Register(model); regErr != nil {
-				return ModelMetadata{}, fmt.Errorf("failed lazy-registering model: %w", regErr)
-			}
-
-			// After registration, try to get metadata again
-			metadata, err = defaultRegistry.GetModelMetadata(model)
-			if err != nil {
-				return ModelMetadata{}, fmt.Errorf("failed to get model metadata after lazy registration: %w", err)
-			}
-			return metadata, nil
-		}
-		// Some other error occurred
-		return ModelMetadata{}, err
-	}
-	return metadata, nil
+	return defaultRegistry.getOrRegister(model)
 }
 
 // ErrModelNotRegistered is returned when a model is not found in the registry
@@ -75,42 +118,227 @@ func (e *ErrModelNotRegistered) Error() string {
 	return fmt.Sprintf("model %s not registered", e.ModelType.Name())
 }
 
-// Register adds a model's metadata to the registry
+// Register adds a model's metadata to the registry.
+// Registering the same model again is a no-op. The struct is reflected over at most
+// once per type: concurrent callers wait for the first registration, and a model
+// that failed to register keeps returning the same error. Reflection runs outside
+// the registry lock, so lookups of other models are not held up by it.
 func (r *Registry) Register(model Model) error {
+	t := reflect.TypeOf(model)
+
+	// Fast path: already registered (or already failed) under the read lock
+	r.mu.RLock()
+	_, exists := r.models[t]
+	err := r.failed[t]
+	r.mu.RUnlock()
+	if exists || err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	// Check again: another goroutine may have registered t while we waited for the lock
+	if _, exists := r.models[t]; exists {
+		r.mu.Unlock()
+		return nil
+	}
+	if err := r.failed[t]; err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	// Another goroutine is reflecting over t: wait for its result
+	if done, ok := r.inflight[t]; ok {
+		r.mu.Unlock()
+		<-done
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.failed[t]
+	}
+	done := make(chan struct{})
+	r.inflight[t] = done
+	inferNames, requireAll := r.inferNames, r.requireAll
+	r.mu.Unlock()
+
+	// Release the waiters even if the model's methods panic
+	defer func() {
+		r.mu.Lock()
+		delete(r.inflight, t)
+		r.mu.Unlock()
+		close(done)
+	}()
+
+	metadata, err := buildModelMetadata(model, inferNames)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if err != nil {
+		r.failed[t] = err
+		return err
+	}
+	metadata.RequireValues = requireAll
+	metadata.queries = newQueryCache()
+	r.applyNormalizersLocked(metadata)
+	r.applyEnumsLocked(metadata)
+	r.models[t] = metadata
+	return nil
+}
 
+// modelByTable returns the metadata of the registered model stored in tableName.
+func (r *Registry) modelByTable(tableName string) (ModelMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []ModelMetadata
+	for _, metadata := range r.models {
+		if metadata.TableName == tableName {
+			found = append(found, metadata)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return ModelMetadata{}, fmt.Errorf("no registered model has table %s", tableName)
+	case 1:
+		return found[0], nil
+	default:
+		return ModelMetadata{}, fmt.Errorf("table %s is used by %d registered models", tableName, len(found))
+	}
+}
+
+// registeredModels returns the metadata of all registered models, sorted by table name.
+func (r *Registry) registeredModels() []ModelMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := make([]ModelMetadata, 0, len(r.models))
+	for _, metadata := range r.models {
+		models = append(models, metadata)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].TableName < models[j].TableName })
+	return models
+}
+
+// RegisterAll registers each model, stopping at the first error.
+func (r *Registry) RegisterAll(models ...Model) error {
+	for _, model := range models {
+		if err := r.Register(model); err != nil {
+			return fmt.Errorf("failed to register %T: %w", model, err)
+		}
+	}
+	return nil
+}
+
+// getOrRegister returns the metadata of model, registering it first if needed.
+func (r *Registry) getOrRegister(model Model) (ModelMetadata, error) {
+	metadata, err := r.GetModelMetadata(model)
+	if err == nil {
+		return metadata, nil
+	}
+
+	var notRegistered *ErrModelNotRegistered
+	if !errors.As(err, &notRegistered) {
+		return ModelMetadata{}, err
+	}
+
+	if regErr := r.Register(model); regErr != nil {
+		return ModelMetadata{}, fmt.Errorf("failed lazy-registering model: %w", regErr)
+	}
+
+	metadata, err = r.GetModelMetadata(model)
+	if err != nil {
+		return ModelMetadata{}, fmt.Errorf("failed to get model metadata after lazy registration: %w", err)
+	}
+	return metadata, nil
+}
+
+// buildModelMetadata reflects over the model's struct fields. If inferNames is
+// set, missing db and json tags are derived from the field names.
+func buildModelMetadata(model Model, inferNames bool) (ModelMetadata, error) {
 	t := reflect.TypeOf(model)
-	// If model is already registered, silently succeed
-	if _, exists := r.models[t]; exists {
-		return nil
+	if t == nil || t.Kind() != reflect.Struct {
+		return ModelMetadata{}, fmt.Errorf("model must be a struct, got %v", t)
+	}
+
+	cacheTTL, err := modelCacheTTL(model)
+	if err != nil {
+		return ModelMetadata{}, err
 	}
 
 	metadata := ModelMetadata{
-		TableName: model.TableName(),
-		Fields:    make(map[string]Field),
+		TableName:        model.TableName(),
+		Fields:           make(map[string]Field),
+		CacheTTL:         cacheTTL,
+		MaterializedView: isMaterializedView(model),
 	}
 
+	// JSON names of the fields tagged sqld:"pk", in struct order
+	var taggedKey []string
+
 	// Reflect over the struct fields
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
-		// Get database column name from db tag
-		dbName := field.Tag.Get("db")
+		// Get database column name from db tag, and JSON name from json tag
+		dbName := tagName(field.Tag.Get("db"))
+		jsonName := tagName(field.Tag.Get("json"))
+		if dbName == "-" || jsonName == "-" {
+			// Not a column, such as a computed helper, or a column that must
+			// not be exposed, such as a password hash
+			continue
+		}
+		if inferNames {
+			if !field.IsExported() {
+				continue
+			}
+			dbName, jsonName = inferFieldNames(field.Name, dbName, jsonName)
+		}
 		if dbName == "" {
-			return fmt.Errorf("field %q missing required db tag", field.Name)
+			return ModelMetadata{}, fmt.Errorf("field %q missing required db tag", field.Name)
 		}
-
-		// Get JSON name from json tag
-		jsonName := field.Tag.Get("json")
 		if jsonName == "" {
-			return fmt.Errorf("field %q missing required json tag", field.Name)
+			return ModelMetadata{}, fmt.Errorf("field %q missing required json tag", field.Name)
+		}
+
+		// Parse sqld tag options
+		var autoTimestamp AutoTimestamp
+		var softDelete, optional, hasDefault bool
+		if tag := field.Tag.Get("sqld"); tag != "" {
+			for _, option := range strings.Split(tag, ",") {
+				if option == "optional" {
+					optional = true
+					continue
+				}
+				if option == "default" {
+					hasDefault = true
+					continue
+				}
+				if option == "pk" {
+					taggedKey = append(taggedKey, jsonName)
+					continue
+				}
+				switch option {
+				case string(AutoCreate), string(AutoUpdate):
+					autoTimestamp = AutoTimestamp(option)
+				case "softdelete":
+					if metadata.SoftDeleteField != "" {
+						return ModelMetadata{}, fmt.Errorf("field %q has sqld:\"softdelete\", but %q is already the soft-delete field",
+							field.Name, metadata.SoftDeleteField)
+					}
+					softDelete = true
+				default:
+					return ModelMetadata{}, fmt.Errorf("field %q has unknown sqld tag option %q", field.Name, option)
+				}
+				if !IsTimeType(normalizeReflectType(field.Type)) {
+					return ModelMetadata{}, fmt.Errorf("field %q has sqld:%q but is not a timestamp", field.Name, option)
+				}
+			}
+		}
+		if softDelete {
+			metadata.SoftDeleteField = jsonName
 		}
 
 		var arrayInfo *ArrayInfo
-		if field.Type.Kind() == reflect.Slice {
+		if elem, ok := arrayElemType(field.Type); ok {
 			arrayInfo = &ArrayInfo{
-				ElementType: normalizeReflectType(field.Type.Elem()),
+				ElementType: normalizeReflectType(elem),
 			}
 		}
 
@@ -121,11 +349,27 @@ func (r *Registry) Register(model Model) error {
 			Type:           field.Type,
 			NormalizedType: normalizeReflectType(field.Type),
 			Array:          arrayInfo,
+			AutoTimestamp:  autoTimestamp,
+			Optional:       optional,
+			Nullable:       isNullableType(field.Type),
+			HasDefault:     hasDefault,
+			JSON:           isJSONType(field.Type),
+			Spatial:        isSpatialType(field.Type),
 		}
 	}
 
-	r.models[t] = metadata
-	return nil
+	metadata.PrimaryKey, err = modelPrimaryKey(model, metadata, taggedKey)
+	if err != nil {
+		return ModelMetadata{}, err
+	}
+	return metadata, nil
+}
+
+// tagName returns the name in a db or json struct tag, without options such as
+// omitempty or string.
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
 }
 
 // normalizeReflectType normalizes a reflect.Type to a simpler form for validation
@@ -135,11 +379,19 @@ func normalizeReflectType(rt reflect.Type) reflect.Type {
 		rt = rt.Elem()
 	}
 
+	// JSON documents, whether raw or decoded into a map
+	if isJSONType(rt) {
+		return rawJSONType
+	}
+	if isBytesType(rt) {
+		return bytesType
+	}
+
 	// Handle pgtype types
 	switch rt {
 	case reflect.TypeOf(pgtype.Text{}):
 		return reflect.TypeOf("")
-	case reflect.TypeOf(pgtype.Numeric{}):
+	case reflect.TypeOf(pgtype.Numeric{}), decimalType, nullDecimalType:
 		return reflect.TypeOf(float64(0))
 	case reflect.TypeOf(pgtype.Int8{}):
 		return reflect.TypeOf(int64(0))
@@ -151,6 +403,13 @@ func normalizeReflectType(rt reflect.Type) reflect.Type {
 		return reflect.TypeOf(time.Time{})
 	case reflect.TypeOf(pgtype.Date{}):
 		return reflect.TypeOf(time.Time{})
+	case intervalType:
+		return durationType
+	}
+
+	// pgtype.Array[T] holds the same values as a []T
+	if elem, ok := pgArrayElem(rt); ok {
+		return reflect.SliceOf(elem)
 	}
 
 	// If underlying kind is string (including custom string-based enums),
@@ -182,6 +441,60 @@ func (r *Registry) GetModelMetadata(model Model) (ModelMetadata, error) {
 	return metadata, nil
 }
 
+// scannedValue is implemented by registered scanners that expose the value
+// they scanned, like the EmployeeIDScanner in the examples.
+type scannedValue interface {
+	Value() interface{}
+}
+
+// applyScanner runs value through the scanner registered for t and returns the
+// scanned result. Values whose type has no registered scanner are returned unchanged.
+//
+// The scanner receives the driver representation of value: raw driver values are
+// passed as is, and values implementing driver.Valuer are converted first, so the
+// same scanner works for map-scanned and struct-scanned rows. The result is taken
+// from the scanner's Value() interface{} or driver.Valuer method, or from the
+// scanner itself if it has neither.
+func (r *Registry) applyScanner(t reflect.Type, value interface{}) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	factory, ok := r.GetScanner(t)
+	if !ok {
+		return value, nil
+	}
+	return scanWith(factory, value)
+}
+
+// scanWith runs value through a scanner created by factory, see applyScanner.
+func scanWith(factory func() sql.Scanner, value interface{}) (interface{}, error) {
+	src := value
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		src = v
+	}
+
+	scanner := factory()
+	if err := scanner.Scan(src); err != nil {
+		return nil, err
+	}
+
+	switch s := scanner.(type) {
+	case scannedValue:
+		return s.Value(), nil
+	case driver.Valuer:
+		return s.Value()
+	}
+	v := reflect.ValueOf(scanner)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Interface(), nil
+}
+
 // GetScanner returns a scanner factory for the given type, if registered
 func (r *Registry) GetScanner(t reflect.Type) (func() sql.Scanner, bool) {
 	r.mu.RLock()
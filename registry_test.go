@@ -2,12 +2,18 @@ package sqld
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type RegistryTestModel struct {
@@ -233,3 +239,221 @@ func (m *mockRows) RawValues() [][]byte {
 func (m *mockRows) Conn() *pgx.Conn {
 	return nil
 }
+
+type InvalidRegistryTestModel struct {
+	ID int `json:"id"`
+}
+
+func (InvalidRegistryTestModel) TableName() string {
+	return "invalid_registry_test_models"
+}
+
+func TestConcurrentLazyRegistration(t *testing.T) {
+	// Clear the registry before test
+	defaultRegistry = NewRegistry()
+
+	var wg sync.WaitGroup
+	results := make([]ModelMetadata, 50)
+	errs := make([]error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = getModelMetadata(RegistryTestModel{})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, results[0], results[i])
+	}
+}
+
+func TestRegisterRemembersFailures(t *testing.T) {
+	// Clear the registry before test
+	defaultRegistry = NewRegistry()
+
+	err := Register[InvalidRegistryTestModel]()
+	assert.EqualError(t, err, `field "ID" missing required db tag`)
+
+	// The failure is cached, so lazy registration reports the same error
+	_, err = getModelMetadata(InvalidRegistryTestModel{})
+	assert.ErrorContains(t, err, `field "ID" missing required db tag`)
+}
+
+func TestMustRegisterAll(t *testing.T) {
+	// Clear the registry before test
+	defaultRegistry = NewRegistry()
+
+	assert.NotPanics(t, func() {
+		MustRegisterAll(RegistryTestModel{}, ArrayTestModel{})
+	})
+	_, err := defaultRegistry.GetModelMetadata(ArrayTestModel{})
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() {
+		MustRegisterAll(RegistryTestModel{}, InvalidRegistryTestModel{})
+	})
+}
+
+type scannerTestID int64
+
+func (id scannerTestID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+type scannerTestIDScanner struct {
+	value scannerTestID
+}
+
+func (s *scannerTestIDScanner) Scan(src interface{}) error {
+	v, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into scannerTestID", src)
+	}
+	s.value = scannerTestID(v * 10)
+	return nil
+}
+
+func (s *scannerTestIDScanner) Value() interface{} {
+	return s.value
+}
+
+func TestApplyScanner(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterScanner(reflect.TypeOf(scannerTestID(0)), func() sql.Scanner { return &scannerTestIDScanner{} })
+
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"raw driver value", reflect.TypeOf(scannerTestID(0)), int64(4), scannerTestID(40), false},
+		{"struct-scanned value", reflect.TypeOf(scannerTestID(0)), scannerTestID(4), scannerTestID(40), false},
+		{"pointer field type", reflect.TypeOf((*scannerTestID)(nil)), int64(4), scannerTestID(40), false},
+		{"no registered scanner", reflect.TypeOf(""), "unchanged", "unchanged", false},
+		{"scan error", reflect.TypeOf(scannerTestID(0)), "bad", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.applyScanner(tt.typ, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type ExcludedFieldsTestModel struct {
+	ID           int64  `json:"id" db:"id"`
+	Email        string `json:"email" db:"email"`
+	PasswordHash string `json:"-" db:"password_hash"`
+	DisplayName  string `json:"display_name" db:"-"`
+	Orders       []int64
+}
+
+func (ExcludedFieldsTestModel) TableName() string {
+	return "excluded_fields"
+}
+
+func TestRegisterSkipsExcludedFields(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Register(ExcludedFieldsTestModel{})
+	assert.EqualError(t, err, `field "Orders" missing required db tag`)
+
+	registry = NewRegistry()
+	registry.InferColumnNames()
+	require.NoError(t, registry.Register(ExcludedFieldsTestModel{}))
+	metadata, err := registry.GetModelMetadata(ExcludedFieldsTestModel{})
+	require.NoError(t, err)
+
+	var columns []string
+	for _, field := range metadata.Fields {
+		columns = append(columns, field.Name)
+	}
+	assert.ElementsMatch(t, []string{"id", "email", "orders"}, columns)
+
+	err = BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{"password_hash"}}, metadata)
+	assert.Error(t, err)
+}
+
+// SlowRegistryTestModel blocks in TableName until release is closed, counting calls.
+type SlowRegistryTestModel struct {
+	ID int `json:"id" db:"id"`
+}
+
+var (
+	slowModelCalls   atomic.Int32
+	slowModelRelease = make(chan struct{})
+)
+
+func (SlowRegistryTestModel) TableName() string {
+	slowModelCalls.Add(1)
+	<-slowModelRelease
+	return "slow_models"
+}
+
+func TestRegisterReflectsOnceOutsideLock(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(RegistryTestModel{}))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = registry.Register(SlowRegistryTestModel{})
+		}(i)
+	}
+
+	// Other models can be looked up while SlowRegistryTestModel is being registered
+	require.Eventually(t, func() bool { return slowModelCalls.Load() == 1 }, time.Second, time.Millisecond)
+	_, err := registry.GetModelMetadata(RegistryTestModel{})
+	assert.NoError(t, err)
+
+	close(slowModelRelease)
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), slowModelCalls.Load())
+	metadata, err := registry.GetModelMetadata(SlowRegistryTestModel{})
+	require.NoError(t, err)
+	assert.Equal(t, "slow_models", metadata.TableName)
+}
+
+type TagOptionsTestModel struct {
+	ID       int64  `json:"id,string" db:"id"`
+	Nickname string `json:"nickname,omitempty" db:"nickname,omitempty"`
+	Secret   string `json:"-" db:"secret"`
+}
+
+func (TagOptionsTestModel) TableName() string {
+	return "tag_options"
+}
+
+func TestTagOptionsAreIgnored(t *testing.T) {
+	metadata, err := buildModelMetadata(TagOptionsTestModel{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "nickname", metadata.Fields["nickname"].Name)
+	assert.Equal(t, "id", metadata.Fields["id"].Name)
+	assert.Len(t, metadata.Fields, 2)
+
+	metaMap, err := BuildMetadataMap[TagOptionsTestModel]()
+	require.NoError(t, err)
+	assert.Equal(t, "nickname", metaMap["nickname"].jsonKey)
+	assert.Len(t, metaMap, 2)
+
+	args, err := ValidateMapParamsAgainstStructNamed[TagOptionsTestModel](
+		map[string]interface{}{"id": int64(7), "nickname": "jo"}, []string{"id", "nickname"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(7), "jo"}, args)
+}
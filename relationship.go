@@ -0,0 +1,137 @@
+package sqld
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Cardinality is how many rows of the related model match a row of the model
+// declaring a Relationship.
+type Cardinality string
+
+const (
+	BelongsTo Cardinality = "belongs_to" // The model holds the foreign key of one related row
+	HasOne    Cardinality = "has_one"    // One related row holds the foreign key of the model
+	HasMany   Cardinality = "has_many"   // Any number of related rows hold the foreign key of the model
+)
+
+// Relationship is a foreign-key relationship from one model to another.
+// Rows are related when each of LocalColumns equals the RemoteColumns entry at
+// the same position. Features that follow relationships, such as joins, EXISTS
+// conditions or preloading, look them up by name with GetRelationship instead
+// of taking their own join configuration.
+type Relationship struct {
+	Name          string      `json:"name"`           // Unique among the relationships of the model
+	Table         string      `json:"table"`          // Table of the model declaring the relationship
+	RelatedTable  string      `json:"related_table"`  // Table of the related model
+	LocalColumns  []string    `json:"local_columns"`  // Columns of the model
+	RemoteColumns []string    `json:"remote_columns"` // Columns of the related model
+	Cardinality   Cardinality `json:"cardinality"`
+
+	related reflect.Type
+}
+
+// RelatedModel returns the Go type of the related model.
+func (rel Relationship) RelatedModel() reflect.Type {
+	return rel.related
+}
+
+// RegisterRelationship declares rel from model From to model To in the default
+// registry. Both models are registered if needed; Table and RelatedTable are
+// filled in from them.
+//
+//	err := sqld.RegisterRelationship[Employee, Department](sqld.Relationship{
+//	    Name:          "department",
+//	    LocalColumns:  []string{"department_id"},
+//	    RemoteColumns: []string{"id"},
+//	    Cardinality:   sqld.BelongsTo,
+//	})
+func RegisterRelationship[From Model, To Model](rel Relationship) error {
+	var from From
+	var to To
+	return defaultRegistry.RegisterRelationship(from, to, rel)
+}
+
+// GetRelationship returns the relationship of model T named name from the default registry.
+func GetRelationship[T Model](name string) (Relationship, error) {
+	var model T
+	return defaultRegistry.GetRelationship(model, name)
+}
+
+// RegisterRelationship declares rel from the model from to the model to.
+// The columns must be columns of the models, and a model cannot have two
+// relationships with the same name.
+func (r *Registry) RegisterRelationship(from, to Model, rel Relationship) error {
+	fromMetadata, err := r.getOrRegister(from)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	toMetadata, err := r.getOrRegister(to)
+	if err != nil {
+		return fmt.Errorf("failed to get related model metadata: %w", err)
+	}
+
+	if rel.Name == "" {
+		return fmt.Errorf("relationship of model %s has no name", fromMetadata.TableName)
+	}
+	switch rel.Cardinality {
+	case BelongsTo, HasOne, HasMany:
+	default:
+		return fmt.Errorf("relationship %s has unknown cardinality %q", rel.Name, rel.Cardinality)
+	}
+	if len(rel.LocalColumns) == 0 || len(rel.LocalColumns) != len(rel.RemoteColumns) {
+		return fmt.Errorf("relationship %s must have as many local as remote columns, and at least one", rel.Name)
+	}
+	for _, column := range rel.LocalColumns {
+		if _, ok := fromMetadata.fieldByColumn(column); !ok {
+			return fmt.Errorf("relationship %s: %s is not a column of model %s", rel.Name, column, fromMetadata.TableName)
+		}
+	}
+	for _, column := range rel.RemoteColumns {
+		if _, ok := toMetadata.fieldByColumn(column); !ok {
+			return fmt.Errorf("relationship %s: %s is not a column of model %s", rel.Name, column, toMetadata.TableName)
+		}
+	}
+
+	rel.Table = fromMetadata.TableName
+	rel.RelatedTable = toMetadata.TableName
+	rel.LocalColumns = append([]string(nil), rel.LocalColumns...)
+	rel.RemoteColumns = append([]string(nil), rel.RemoteColumns...)
+	rel.related = reflect.TypeOf(to)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := reflect.TypeOf(from)
+	if _, exists := r.relations[t][rel.Name]; exists {
+		return fmt.Errorf("model %s already has a relationship named %s", rel.Table, rel.Name)
+	}
+	if r.relations[t] == nil {
+		r.relations[t] = make(map[string]Relationship)
+	}
+	r.relations[t][rel.Name] = rel
+	return nil
+}
+
+// GetRelationship returns the relationship of model named name.
+func (r *Registry) GetRelationship(model Model, name string) (Relationship, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rel, ok := r.relations[reflect.TypeOf(model)][name]
+	if !ok {
+		return Relationship{}, fmt.Errorf("model %s has no relationship named %s", model.TableName(), name)
+	}
+	return rel, nil
+}
+
+// Relationships returns the relationships of model, sorted by name.
+func (r *Registry) Relationships(model Model) []Relationship {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rels := make([]Relationship, 0, len(r.relations[reflect.TypeOf(model)]))
+	for _, rel := range r.relations[reflect.TypeOf(model)] {
+		rels = append(rels, rel)
+	}
+	sort.Slice(rels, func(i, j int) bool { return rels[i].Name < rels[j].Name })
+	return rels
+}
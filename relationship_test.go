@@ -0,0 +1,109 @@
+package sqld
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RelEmployee struct {
+	ID           int `db:"id" json:"id"`
+	DepartmentID int `db:"department_id" json:"department_id"`
+}
+
+func (RelEmployee) TableName() string { return "employees" }
+
+type RelDepartment struct {
+	ID int `db:"id" json:"id"`
+}
+
+func (RelDepartment) TableName() string { return "departments" }
+
+func TestRegisterRelationship(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, RegisterRelationship[RelEmployee, RelDepartment](Relationship{
+		Name:          "department",
+		LocalColumns:  []string{"department_id"},
+		RemoteColumns: []string{"id"},
+		Cardinality:   BelongsTo,
+	}))
+	require.NoError(t, RegisterRelationship[RelDepartment, RelEmployee](Relationship{
+		Name:          "employees",
+		LocalColumns:  []string{"id"},
+		RemoteColumns: []string{"department_id"},
+		Cardinality:   HasMany,
+	}))
+
+	rel, err := GetRelationship[RelEmployee]("department")
+	require.NoError(t, err)
+	assert.Equal(t, "employees", rel.Table)
+	assert.Equal(t, "departments", rel.RelatedTable)
+	assert.Equal(t, reflect.TypeOf(RelDepartment{}), rel.RelatedModel())
+
+	rels := defaultRegistry.Relationships(RelDepartment{})
+	require.Len(t, rels, 1)
+	assert.Equal(t, HasMany, rels[0].Cardinality)
+
+	_, err = GetRelationship[RelEmployee]("manager")
+	assert.EqualError(t, err, "model employees has no relationship named manager")
+}
+
+func TestRegisterRelationshipErrors(t *testing.T) {
+	valid := Relationship{
+		Name:          "department",
+		LocalColumns:  []string{"department_id"},
+		RemoteColumns: []string{"id"},
+		Cardinality:   BelongsTo,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(rel *Relationship)
+		wantErr string
+	}{
+		{
+			name:    "no name",
+			modify:  func(rel *Relationship) { rel.Name = "" },
+			wantErr: "relationship of model employees has no name",
+		},
+		{
+			name:    "unknown cardinality",
+			modify:  func(rel *Relationship) { rel.Cardinality = "many_to_many" },
+			wantErr: `relationship department has unknown cardinality "many_to_many"`,
+		},
+		{
+			name:    "column count",
+			modify:  func(rel *Relationship) { rel.RemoteColumns = nil },
+			wantErr: "relationship department must have as many local as remote columns, and at least one",
+		},
+		{
+			name:    "unknown local column",
+			modify:  func(rel *Relationship) { rel.LocalColumns = []string{"dept_id"} },
+			wantErr: "relationship department: dept_id is not a column of model employees",
+		},
+		{
+			name:    "unknown remote column",
+			modify:  func(rel *Relationship) { rel.RemoteColumns = []string{"department_id"} },
+			wantErr: "relationship department: department_id is not a column of model departments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel := valid
+			tt.modify(&rel)
+			err := NewRegistry().RegisterRelationship(RelEmployee{}, RelDepartment{}, rel)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	registry := NewRegistry()
+	require.NoError(t, registry.RegisterRelationship(RelEmployee{}, RelDepartment{}, valid))
+	err := registry.RegisterRelationship(RelEmployee{}, RelDepartment{}, valid)
+	assert.EqualError(t, err, "model employees already has a relationship named department")
+}
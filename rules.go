@@ -0,0 +1,359 @@
+package sqld
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
+)
+
+// ErrDeniedConstruct is returned for raw queries that use a construct denied by QueryRules.
+var ErrDeniedConstruct = errors.New("query uses a denied construct")
+
+// DangerousFunctions lists functions that can stall the server, reach outside
+// the database or read server files. It is a starting point for QueryRules.DeniedFunctions.
+var DangerousFunctions = []string{
+	"pg_sleep", "pg_sleep_for", "pg_sleep_until",
+	"dblink", "dblink_exec", "dblink_connect", "dblink_send_query",
+	"pg_read_file", "pg_read_binary_file", "pg_ls_dir", "pg_stat_file",
+	"lo_import", "lo_export",
+	"pg_terminate_backend", "pg_cancel_backend", "pg_reload_conf", "set_config",
+}
+
+// QueryRules restricts the constructs raw queries may use, beyond the statement
+// kind checks of ExecuteRaw and ExecuteRawExec. Rules are checked on the parsed
+// query, so they cannot be evaded by formatting or comments. Statements such as
+// COPY ... TO PROGRAM are never accepted by the raw executors to begin with.
+//
+//	sqld.SetQueryRules(sqld.QueryRules{
+//	    DeniedFunctions: sqld.DangerousFunctions,
+//	    AllowedSchemas:  []string{"public", "reporting"},
+//	    AllowedTables:   []string{"orders", "reporting.daily_sales"},
+//	})
+type QueryRules struct {
+	// DeniedFunctions lists functions queries may not call. Names match
+	// case-insensitively, whether or not the call qualifies them with a schema.
+	DeniedFunctions []string
+
+	// DeniedFunctionPrefixes rejects every function whose name starts with one
+	// of the prefixes, such as "pg_" for the system administration functions.
+	DeniedFunctionPrefixes []string
+
+	// AllowedSchemas, if set, lists the only schemas tables may be qualified with.
+	// Unqualified tables resolve through the search_path and are not checked.
+	AllowedSchemas []string
+
+	// AllowedTables, if set, lists the only tables and views queries may
+	// reference, as "table" or "schema.table". A reference matches an entry
+	// with the same table name, unless both name a schema and the schemas differ.
+	// References to CTEs defined by the query are not checked.
+	AllowedTables []string
+}
+
+// SetQueryRules sets the rules checked for raw queries of the default registry.
+func SetQueryRules(rules QueryRules) {
+	defaultRegistry.SetQueryRules(rules)
+}
+
+// SetQueryRules sets the rules checked for raw queries.
+func (r *Registry) SetQueryRules(rules QueryRules) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queryRules = rules
+}
+
+// checkQueryRules checks a parsed raw statement against the registry's rules.
+func (r *Registry) checkQueryRules(stmt tree.Statement) error {
+	r.mu.RLock()
+	rules := r.queryRules
+	r.mu.RUnlock()
+
+	if len(rules.DeniedFunctions) == 0 && len(rules.DeniedFunctionPrefixes) == 0 &&
+		len(rules.AllowedSchemas) == 0 && len(rules.AllowedTables) == 0 {
+		return nil
+	}
+	return rules.check(stmt)
+}
+
+// check reports the first construct of stmt denied by the rules.
+func (rules QueryRules) check(stmt tree.Statement) error {
+	inspector := &queryInspector{}
+	inspector.inspectStatement(stmt)
+	if inspector.err != nil {
+		return inspector.err
+	}
+
+	for _, fn := range inspector.functions {
+		name := strings.ToLower(fn.Parts[0])
+		for _, denied := range rules.DeniedFunctions {
+			if strings.EqualFold(name, denied) {
+				return fmt.Errorf("%w: function %s", ErrDeniedConstruct, name)
+			}
+		}
+		for _, prefix := range rules.DeniedFunctionPrefixes {
+			if strings.HasPrefix(name, strings.ToLower(prefix)) {
+				return fmt.Errorf("%w: function %s", ErrDeniedConstruct, name)
+			}
+		}
+	}
+
+	if len(rules.AllowedSchemas) > 0 {
+		for _, table := range inspector.tables {
+			if !table.ExplicitSchema {
+				continue
+			}
+			schema := string(table.SchemaName)
+			if !containsFold(rules.AllowedSchemas, schema) {
+				return fmt.Errorf("%w: schema %s of table %s", ErrDeniedConstruct, schema, table.Table())
+			}
+		}
+	}
+
+	if len(rules.AllowedTables) > 0 {
+		for _, table := range inspector.tables {
+			if !tableAllowed(rules.AllowedTables, table) {
+				return fmt.Errorf("%w: table %s", ErrDeniedConstruct, tree.AsStringWithFlags(table, tree.FmtBareIdentifiers))
+			}
+		}
+	}
+	return nil
+}
+
+// tableAllowed reports whether table matches one of the allowed entries.
+func tableAllowed(allowed []string, table *tree.TableName) bool {
+	for _, entry := range allowed {
+		schema, name, qualified := strings.Cut(entry, ".")
+		if !qualified {
+			name = schema
+		}
+		if !strings.EqualFold(name, table.Table()) {
+			continue
+		}
+		if qualified && table.ExplicitSchema && !strings.EqualFold(schema, string(table.SchemaName)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// containsFold checks if s is present in slice, ignoring case.
+func containsFold(slice []string, s string) bool {
+	for _, item := range slice {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryInspector collects the functions called and the tables read or
+// written by a statement, including those of its subqueries and CTEs.
+// References to CTEs in scope are not collected as tables. It fails closed:
+// constructs it does not know how to inspect are reported as denied.
+type queryInspector struct {
+	functions []*tree.UnresolvedName
+	tables    []*tree.TableName
+	ctes      []map[string]bool // CTE names of the enclosing WITH clauses, innermost last
+	err       error
+}
+
+// inspectStatement collects the functions and tables of stmt.
+func (q *queryInspector) inspectStatement(stmt tree.Statement) {
+	switch s := stmt.(type) {
+	case *tree.Select:
+		defer q.inspectWith(s.With)()
+		q.inspectStatement(s.Select)
+		q.inspectOrderBy(s.OrderBy)
+		q.inspectLimit(s.Limit)
+	case *tree.ParenSelect:
+		q.inspectStatement(s.Select)
+	case *tree.UnionClause:
+		q.inspectStatement(s.Left)
+		q.inspectStatement(s.Right)
+	case *tree.SelectClause:
+		for _, table := range s.From.Tables {
+			q.inspectTableExpr(table)
+		}
+		for _, expr := range s.Exprs {
+			q.inspectExpr(expr.Expr)
+		}
+		q.inspectWhere(s.Where)
+		q.inspectExprs(tree.Exprs(s.GroupBy))
+		q.inspectWhere(s.Having)
+		for _, window := range s.Window {
+			q.inspectExprs(window.Partitions)
+			q.inspectOrderBy(window.OrderBy)
+		}
+		q.inspectExprs(tree.Exprs(s.DistinctOn))
+	case *tree.ValuesClause:
+		for _, row := range s.Rows {
+			q.inspectExprs(row)
+		}
+	case *tree.Insert:
+		defer q.inspectWith(s.With)()
+		q.inspectTableExpr(s.Table)
+		if s.Rows != nil {
+			q.inspectStatement(s.Rows)
+		}
+		if s.OnConflict != nil {
+			q.inspectExpr(s.OnConflict.ArbiterPredicate)
+			q.inspectUpdateExprs(s.OnConflict.Exprs)
+			q.inspectWhere(s.OnConflict.Where)
+		}
+		q.inspectReturning(s.Returning)
+	case *tree.Update:
+		defer q.inspectWith(s.With)()
+		q.inspectTableExpr(s.Table)
+		for _, table := range s.From {
+			q.inspectTableExpr(table)
+		}
+		q.inspectUpdateExprs(s.Exprs)
+		q.inspectWhere(s.Where)
+		q.inspectOrderBy(s.OrderBy)
+		q.inspectLimit(s.Limit)
+		q.inspectReturning(s.Returning)
+	case *tree.Delete:
+		defer q.inspectWith(s.With)()
+		q.inspectTableExpr(s.Table)
+		for _, table := range s.Using {
+			q.inspectTableExpr(table)
+		}
+		q.inspectWhere(s.Where)
+		q.inspectOrderBy(s.OrderBy)
+		q.inspectLimit(s.Limit)
+		q.inspectReturning(s.Returning)
+	default:
+		q.deny(fmt.Errorf("%w: unsupported statement %T", ErrDeniedConstruct, stmt))
+	}
+}
+
+// inspectWith brings the CTEs of with into scope and collects their functions
+// and tables. It returns a function taking them out of scope again. A CTE of
+// a non-recursive WITH only sees the CTEs defined before it.
+func (q *queryInspector) inspectWith(with *tree.With) func() {
+	scope := make(map[string]bool)
+	q.ctes = append(q.ctes, scope)
+	if with != nil {
+		if with.Recursive {
+			for _, cte := range with.CTEList {
+				scope[string(cte.Name.Alias)] = true
+			}
+		}
+		for _, cte := range with.CTEList {
+			q.inspectStatement(cte.Stmt)
+			scope[string(cte.Name.Alias)] = true
+		}
+	}
+	return func() { q.ctes = q.ctes[:len(q.ctes)-1] }
+}
+
+// isCTE reports whether table refers to a CTE in scope.
+func (q *queryInspector) isCTE(table *tree.TableName) bool {
+	if table.ExplicitSchema {
+		return false
+	}
+	for _, scope := range q.ctes {
+		if scope[table.Table()] {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectTableExpr collects the functions and tables of a FROM item.
+func (q *queryInspector) inspectTableExpr(expr tree.TableExpr) {
+	switch e := expr.(type) {
+	case *tree.TableName:
+		if !q.isCTE(e) {
+			q.tables = append(q.tables, e)
+		}
+	case *tree.AliasedTableExpr:
+		q.inspectTableExpr(e.Expr)
+	case *tree.ParenTableExpr:
+		q.inspectTableExpr(e.Expr)
+	case *tree.JoinTableExpr:
+		q.inspectTableExpr(e.Left)
+		q.inspectTableExpr(e.Right)
+		if cond, ok := e.Cond.(*tree.OnJoinCond); ok {
+			q.inspectExpr(cond.Expr)
+		}
+	case *tree.Subquery:
+		q.inspectStatement(e.Select)
+	case *tree.RowsFromExpr:
+		q.inspectExprs(e.Items)
+	default:
+		q.deny(fmt.Errorf("%w: unsupported table expression %T", ErrDeniedConstruct, expr))
+	}
+}
+
+// inspectExpr collects the functions of expr, and the functions and tables of
+// its subqueries.
+func (q *queryInspector) inspectExpr(expr tree.Expr) {
+	if expr == nil {
+		return
+	}
+	_, err := tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch e := expr.(type) {
+		case *tree.FuncExpr:
+			if name, ok := e.Func.FunctionReference.(*tree.UnresolvedName); ok {
+				q.functions = append(q.functions, name)
+			}
+		case *tree.Subquery:
+			// Inspected here rather than by the walk, so that it sees the CTEs in scope
+			q.inspectStatement(e.Select)
+			return false, expr, nil
+		}
+		return true, expr, nil
+	})
+	if err != nil {
+		q.deny(fmt.Errorf("failed to inspect query: %w", err))
+	}
+}
+
+func (q *queryInspector) inspectExprs(exprs tree.Exprs) {
+	for _, expr := range exprs {
+		q.inspectExpr(expr)
+	}
+}
+
+func (q *queryInspector) inspectWhere(where *tree.Where) {
+	if where != nil {
+		q.inspectExpr(where.Expr)
+	}
+}
+
+func (q *queryInspector) inspectOrderBy(orderBy tree.OrderBy) {
+	for _, order := range orderBy {
+		q.inspectExpr(order.Expr)
+	}
+}
+
+func (q *queryInspector) inspectLimit(limit *tree.Limit) {
+	if limit != nil {
+		q.inspectExpr(limit.Count)
+		q.inspectExpr(limit.Offset)
+	}
+}
+
+func (q *queryInspector) inspectUpdateExprs(exprs tree.UpdateExprs) {
+	for _, expr := range exprs {
+		q.inspectExpr(expr.Expr)
+	}
+}
+
+func (q *queryInspector) inspectReturning(returning tree.ReturningClause) {
+	if exprs, ok := returning.(*tree.ReturningExprs); ok {
+		for _, expr := range *exprs {
+			q.inspectExpr(expr.Expr)
+		}
+	}
+}
+
+// deny records err unless an earlier error was recorded.
+func (q *queryInspector) deny(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
@@ -0,0 +1,127 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRules(t *testing.T) {
+	rules := QueryRules{
+		DeniedFunctions:        DangerousFunctions,
+		DeniedFunctionPrefixes: []string{"pg_stat_"},
+		AllowedSchemas:         []string{"public"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{name: "plain select", query: "SELECT id, lower(name) FROM users WHERE id = $1"},
+		{name: "allowed schema", query: "SELECT id FROM public.users"},
+		{name: "denied function", query: "SELECT pg_sleep(10)", wantErr: "query uses a denied construct: function pg_sleep"},
+		{name: "qualified and upper case", query: "SELECT PG_CATALOG.PG_SLEEP(1)", wantErr: "query uses a denied construct: function pg_sleep"},
+		{name: "function in where", query: "SELECT id FROM users WHERE pg_sleep(1) IS NULL", wantErr: "query uses a denied construct: function pg_sleep"},
+		{name: "function in from", query: "SELECT * FROM dblink('host=x', 'SELECT 1') AS t(a int)", wantErr: "query uses a denied construct: function dblink"},
+		{name: "function in join condition", query: "SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id AND pg_sleep(1) IS NULL", wantErr: "query uses a denied construct: function pg_sleep"},
+		{name: "function in cte", query: "WITH t AS (SELECT pg_read_file('/etc/passwd') AS f) SELECT f FROM t", wantErr: "query uses a denied construct: function pg_read_file"},
+		{name: "denied prefix", query: "SELECT * FROM pg_stat_get_activity(NULL)", wantErr: "query uses a denied construct: function pg_stat_get_activity"},
+		{name: "other schema", query: "SELECT usename FROM pg_catalog.pg_user", wantErr: "query uses a denied construct: schema pg_catalog of table pg_user"},
+		{name: "other schema in join", query: "SELECT u.id FROM users u JOIN audit.log l ON l.user_id = u.id", wantErr: "query uses a denied construct: schema audit of table log"},
+		{name: "other schema in subquery", query: "SELECT id FROM users WHERE id IN (SELECT user_id FROM audit.log)", wantErr: "query uses a denied construct: schema audit of table log"},
+		{name: "other schema in union", query: "SELECT id FROM users UNION SELECT id FROM audit.log", wantErr: "query uses a denied construct: schema audit of table log"},
+		{name: "other schema in update from", query: "UPDATE users SET name = l.name FROM audit.log l WHERE l.id = users.id", wantErr: "query uses a denied construct: schema audit of table log"},
+		{name: "other schema as insert target", query: "INSERT INTO audit.log (id) VALUES (1)", wantErr: "query uses a denied construct: schema audit of table log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tt.query)
+			require.NoError(t, err)
+
+			err = rules.check(stmt.AST)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrDeniedConstruct)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestQueryRulesInRawExecution(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	SetQueryRules(QueryRules{DeniedFunctions: DangerousFunctions})
+
+	db, fake := newFakeDB(t, nil)
+	_, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}} AND pg_sleep(5) IS NULL",
+		Params: map[string]interface{}{"id": 1},
+	})
+	assert.ErrorIs(t, err, ErrDeniedConstruct)
+
+	_, err = ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+		Query:  "DELETE FROM test_results WHERE id = {{id}} AND pg_sleep(5) IS NULL",
+		Params: map[string]interface{}{"id": 1},
+	})
+	assert.ErrorIs(t, err, ErrDeniedConstruct)
+	assert.Empty(t, fake.statements)
+}
+
+func TestQueryRulesAllowedTables(t *testing.T) {
+	rules := QueryRules{AllowedTables: []string{"users", "reporting.daily_sales"}}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{name: "allowed table", query: "SELECT id FROM users"},
+		{name: "allowed table in any schema", query: "SELECT id FROM public.users"},
+		{name: "allowed qualified table", query: "SELECT total FROM reporting.daily_sales"},
+		{name: "qualified entry matches unqualified reference", query: "SELECT total FROM daily_sales"},
+		{name: "cte", query: "WITH recent AS (SELECT id FROM users) SELECT id FROM recent"},
+		{name: "recursive cte", query: "WITH RECURSIVE n AS (SELECT 1 AS i UNION ALL SELECT i + 1 FROM n WHERE i < 3) SELECT i FROM n"},
+		{name: "cte in subquery", query: "WITH recent AS (SELECT id FROM users) SELECT id FROM users WHERE id IN (SELECT id FROM recent)"},
+		{name: "other table", query: "SELECT id FROM orders", wantErr: "query uses a denied construct: table orders"},
+		{name: "wrong schema", query: "SELECT total FROM archive.daily_sales", wantErr: "query uses a denied construct: table archive.daily_sales"},
+		{name: "other table in join", query: "SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id", wantErr: "query uses a denied construct: table orders"},
+		{name: "other table in scalar subquery", query: "SELECT (SELECT count(*) FROM orders) AS n FROM users", wantErr: "query uses a denied construct: table orders"},
+		{name: "other table in cte", query: "WITH o AS (SELECT id FROM orders) SELECT id FROM o", wantErr: "query uses a denied construct: table orders"},
+		{
+			name:    "cte name out of scope",
+			query:   "SELECT a.id FROM (WITH orders AS (SELECT id FROM users) SELECT id FROM orders) a, orders",
+			wantErr: "query uses a denied construct: table orders",
+		},
+		{
+			name:    "later cte name in non-recursive with",
+			query:   "WITH a AS (SELECT id FROM orders), orders AS (SELECT id FROM users) SELECT id FROM a",
+			wantErr: "query uses a denied construct: table orders",
+		},
+		{name: "other table in delete using", query: "DELETE FROM users USING orders WHERE orders.user_id = users.id", wantErr: "query uses a denied construct: table orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tt.query)
+			require.NoError(t, err)
+
+			err = rules.check(stmt.AST)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
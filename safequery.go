@@ -2,18 +2,15 @@ package sqld
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/parser"
 	"github.com/cockroachdb/cockroachdb-parser/pkg/sql/sem/tree"
-	"github.com/georgysavva/scany/v2/sqlscan"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/georgysavva/scany/v2/pgxscan"
 )
 
 type fieldInfo struct {
@@ -27,26 +24,75 @@ type fieldInfo struct {
 // where the key is the 'db' tag and the value is a fieldInfo struct containing the
 // 'json' tag and the Go type of the field. This map is used later in the ExecuteRaw
 // function to map database column names to JSON keys in the result.
+//
+// The map is built once per type and cached in the default registry, so it is
+// shared between calls and must not be modified.
 func BuildMetadataMap[T any]() (map[string]fieldInfo, error) {
-	t := reflect.TypeOf((*T)(nil)).Elem()
+	tags, err := defaultRegistry.rawStructTags(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return tags.metaMap, nil
+}
+
+// rawStructTags is what the raw query helpers read from the tags of a struct
+// type, see Registry.rawStructTags.
+type rawStructTags struct {
+	metaMap  map[string]fieldInfo    // Fields with db and json tags by db tag, see BuildMetadataMap
+	params   map[string]reflect.Type // Types of the fields with a db tag by db tag
+	optional map[string]bool         // Fields tagged sqld:"optional" by db tag
+	err      error                   // Set if a field has a db tag but no json tag
+}
+
+// rawStructTags returns the tags of the struct type t, reflecting over t on the
+// first call only so raw endpoints serving many requests do not pay for it on
+// every call.
+func (r *Registry) rawStructTags(t reflect.Type) (*rawStructTags, error) {
 	if t.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("model must be a struct")
 	}
+	r.mu.RLock()
+	tags, ok := r.rawTags[t]
+	r.mu.RUnlock()
+	if ok {
+		return tags, nil
+	}
 
-	metaMap := make(map[string]fieldInfo)
+	tags = &rawStructTags{
+		metaMap:  make(map[string]fieldInfo),
+		params:   make(map[string]reflect.Type),
+		optional: make(map[string]bool),
+	}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		dbTag := field.Tag.Get("db")
-		jsonTag := field.Tag.Get("json")
+		dbTag := tagName(field.Tag.Get("db"))
+		jsonTag := tagName(field.Tag.Get("json"))
+		if dbTag == "-" || jsonTag == "-" {
+			continue
+		}
 		if dbTag != "" && jsonTag != "" {
-			metaMap[dbTag] = fieldInfo{
+			tags.metaMap[dbTag] = fieldInfo{
 				jsonKey:   jsonTag,
 				goType:    field.Type,
 				fieldName: field.Name,
 			}
 		}
+
+		// Validate that all fields with db tag must have json tag
+		if dbTag != "" && jsonTag == "" && tags.err == nil {
+			tags.err = fmt.Errorf("field %s has db tag but missing json tag", field.Name)
+		}
+
+		if dbTag != "" {
+			tags.params[dbTag] = field.Type
+			tags.optional[dbTag] = contains(strings.Split(field.Tag.Get("sqld"), ","), "optional")
+		}
 	}
-	return metaMap, nil
+
+	r.mu.Lock()
+	r.rawTags[t] = tags
+	r.mu.Unlock()
+	return tags, nil
 }
 
 func typeNameOrNil(t reflect.Type) string {
@@ -75,10 +121,18 @@ func ExtractNamedPlaceholders(query string) ([]string, error) {
 }
 
 // ReplaceNamedWithDollarPlaceholders replaces {{param_name}} with $1, $2, ...
+// numbered in the order of queryParams. Every occurrence of a parameter gets the
+// same $N, and names repeated in queryParams are numbered once, matching the
+// arguments returned by ValidateMapParamsAgainstStructNamed.
 func ReplaceNamedWithDollarPlaceholders(query string, queryParams []string) (string, error) {
-	for i, p := range queryParams {
+	numbered := make(map[string]bool, len(queryParams))
+	for _, p := range queryParams {
+		if numbered[p] {
+			continue
+		}
+		numbered[p] = true
 		placeholder := fmt.Sprintf("{{%s}}", p)
-		newPlaceholder := fmt.Sprintf("$%d", i+1)
+		newPlaceholder := fmt.Sprintf("$%d", len(numbered))
 		query = strings.ReplaceAll(query, placeholder, newPlaceholder)
 	}
 	return query, nil
@@ -87,33 +141,38 @@ func ReplaceNamedWithDollarPlaceholders(query string, queryParams []string) (str
 // ValidateMapParamsAgainstStructNamed ensures the params map matches the expected types from P.
 // It uses the isTypeCompatible function to check if the type of each parameter in the map
 // matches the expected type from P. This is primarily to prevent runtime errors due to type mismatches.
+// Plain Go values are converted for pgtype fields with the registered converters,
+// and pgtype values are unwrapped for plain fields. Missing parameters are an
+// error unless their field is tagged sqld:"optional", in which case they are bound as NULL.
 func ValidateMapParamsAgainstStructNamed[P any](
 	paramMap map[string]interface{},
 	queryParams []string,
 ) ([]interface{}, error) {
-	t := reflect.TypeOf((*P)(nil)).Elem()
-	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("model must be a struct")
-	}
-
-	typeByName := make(map[string]reflect.Type)
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		dbTag := field.Tag.Get("db")
-		jsonTag := field.Tag.Get("json")
-
-		// Validate that all fields with db tag must have json tag
-		if dbTag != "" && jsonTag == "" {
-			return nil, fmt.Errorf("field %s has db tag but missing json tag", field.Name)
-		}
+	return validateMapParamsWith[P](defaultRegistry, paramMap, queryParams)
+}
 
-		if dbTag != "" {
-			typeByName[dbTag] = field.Type
-		}
+// validateMapParamsWith implements ValidateMapParamsAgainstStructNamed with the
+// converters of r.
+func validateMapParamsWith[P any](r *Registry, paramMap map[string]interface{}, queryParams []string) ([]interface{}, error) {
+	tags, err := r.rawStructTags(reflect.TypeOf((*P)(nil)).Elem())
+	if err != nil {
+		return nil, err
 	}
+	if tags.err != nil {
+		return nil, tags.err
+	}
+	typeByName := tags.params
+	optionalByName := tags.optional
 
+	// A parameter repeated in queryParams is passed once, see ReplaceNamedWithDollarPlaceholders
 	args := make([]interface{}, 0, len(queryParams))
+	seen := make(map[string]bool, len(queryParams))
 	for _, p := range queryParams {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
 		expectedType, found := typeByName[p]
 		if !found {
 			return nil, fmt.Errorf("no type info for param %s", p)
@@ -121,18 +180,21 @@ func ValidateMapParamsAgainstStructNamed[P any](
 
 		val, present := paramMap[p]
 		if !present {
-			// If the parameter is optional and not present, append nil or handle as needed
+			// Only parameters tagged sqld:"optional" may be missing; they are bound as NULL
+			if !optionalByName[p] {
+				return nil, fmt.Errorf("missing required parameter: %s", p)
+			}
 			args = append(args, nil)
 			continue
 		}
 
-		valType := reflect.TypeOf(val)
-		if !AreTypesCompatible(valType, expectedType) {
+		converted, ok := r.convertParamValue(val, expectedType)
+		if !ok {
 			return nil, fmt.Errorf("parameter %s type mismatch: got %s, want %s",
-				p, typeNameOrNil(valType), typeNameOrNil(expectedType))
+				p, typeNameOrNil(reflect.TypeOf(val)), typeNameOrNil(expectedType))
 		}
 
-		args = append(args, val)
+		args = append(args, converted)
 	}
 
 	return args, nil
@@ -141,8 +203,7 @@ func ValidateMapParamsAgainstStructNamed[P any](
 // validateQueryParams checks if all parameters in the query have corresponding values in paramMap
 func validateQueryParams(query string, paramMap map[string]interface{}) error {
 	// Find all parameters in the query using regex
-	re := regexp.MustCompile(`{{(\w+)}}`)
-	matches := re.FindAllStringSubmatch(query, -1)
+	matches := namedParamRegex.FindAllStringSubmatch(query, -1)
 
 	// Create a set of required parameters from the query
 	requiredParams := make(map[string]bool)
@@ -185,27 +246,66 @@ func validateQueryParams(query string, paramMap map[string]interface{}) error {
 	return nil
 }
 
-// validateSQLSyntax uses CockroachDB's parser to validate SQL syntax and structure
-func validateSQLSyntax(query string) error {
+// validateSQLSyntax uses CockroachDB's parser to validate SQL syntax and structure.
+// Read-only WITH queries, including WITH RECURSIVE, are accepted. The query must
+// also pass the rules of r, set with SetQueryRules.
+func (r *Registry) validateSQLSyntax(query string) error {
 	stmt, err := parser.ParseOne(query)
 	if err != nil {
 		return fmt.Errorf("SQL syntax error: %w", err)
 	}
 
 	// Check if it's a SELECT statement
-	switch stmt.AST.(type) {
+	switch ast := stmt.AST.(type) {
 	case *tree.Select:
-		return nil
+		if err := validateReadOnlySelect(ast); err != nil {
+			return err
+		}
+		return r.checkQueryRules(ast)
 	default:
 		return fmt.Errorf("only SELECT statements are allowed")
 	}
 }
 
+// validateReadOnlySelect rejects SELECT statements whose WITH clauses contain
+// data-modifying statements, such as WITH d AS (DELETE ... RETURNING *) SELECT ...
+func validateReadOnlySelect(sel *tree.Select) error {
+	if sel.With != nil {
+		for _, cte := range sel.With.CTEList {
+			cteSelect, ok := cte.Stmt.(*tree.Select)
+			if !ok {
+				return fmt.Errorf("only SELECT statements are allowed in WITH clause %s", cte.Name.Alias)
+			}
+			if err := validateReadOnlySelect(cteSelect); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch clause := sel.Select.(type) {
+	case *tree.ParenSelect:
+		return validateReadOnlySelect(clause.Select)
+	case *tree.UnionClause:
+		if err := validateReadOnlySelect(clause.Left); err != nil {
+			return err
+		}
+		return validateReadOnlySelect(clause.Right)
+	}
+	return nil
+}
+
 // ExecuteRawRequest contains all parameters needed for ExecuteRaw
 type ExecuteRawRequest struct {
-	Query        string                 // SQL query with {{param_name}} placeholders
-	Params       map[string]interface{} // Parameter values mapped to placeholder names
-	SelectFields []string               // List of fields to be returned in the result
+	Query        string                  // SQL query with {{param_name}} placeholders
+	QueryName    string                  // Name or hash of a registered query, used instead of Query
+	Params       map[string]interface{}  // Parameter values mapped to placeholder names
+	SelectFields []string                // List of fields to be returned in the result
+	Lists        map[string]TemplateList // Lists iterated by {% for %} template directives
+	Explain      bool                    // Return the query plan instead of running the query, see ExplainRaw
+	Placeholders PlaceholderStyle        // Placeholder syntax sent to the database, $N by default
+	Timeout      time.Duration           // Cancels the query after this long, see ErrQueryTimeout and Config.QueryTimeout
+	OrderBy      []OrderByClause         // Result fields appended as an ORDER BY clause, validated against R
+	WithSchema   bool                    // Describe the result fields in responses that have room for it, see RawResultSchema
 }
 
 // ExecuteRaw executes a dynamic SQL query with named parameters and returns the results as a slice of maps.
@@ -222,8 +322,13 @@ type ExecuteRawRequest struct {
 //
 //  1. Initial Parameter Validation:
 //     - Validates that P is a struct type
+//     - Looks up QueryName among the registered queries, or checks that Query is
+//     registered when raw queries are locked (see RegisterQuery)
+//     - Expands {% if %} and {% for %} template directives (see TemplateList)
+//     - Keeps [[ ... ]] optional blocks whose parameters are all present, drops the others
 //     - Finds {{param}} placeholders in query using regex
-//     - Validates all placeholders have values in Params map
+//     - Validates all placeholders have values in Params map, binding missing
+//     parameters tagged sqld:"optional" in P as NULL
 //     - Validates no extra unused parameters in Params map
 //
 //  2. Parameter Processing:
@@ -235,12 +340,14 @@ type ExecuteRawRequest struct {
 //
 //  3. Query Processing:
 //     - Replaces {{param}} placeholders with $N positional parameters
+//     - Expands slice parameters used as IN ({{param}}) to one $N per element
 //     - Validates modified SQL using PostgreSQL parser
-//     - Verifies query is a SELECT statement
+//     - Verifies query is a SELECT statement; WITH clauses must be read-only
 //
 //  4. Result Setup:
 //     - Validates that R is a struct type
 //     - Builds metadata map from R struct's db tags
+//     - Verifies every named output column of the query has a field in R
 //
 //  5. Query Execution:
 //     - Executes query with positional parameters
@@ -287,7 +394,10 @@ type ExecuteRawRequest struct {
 //   - Parameter type mismatch errors
 //   - SQL syntax errors after parameter substitution
 //   - Non-SELECT statement errors
+//   - Output columns without a destination field in R
+//   - SelectFields or OrderBy entries that are not fields of R
 //   - Database query execution errors
+//   - *ErrQueryTimeout if the query runs longer than req.Timeout, or Config.QueryTimeout
 //   - Row scanning errors
 //
 // The function supports both *sql.DB and *pgx.Conn database connections through scany's
@@ -297,122 +407,313 @@ func ExecuteRaw[P Model, R Model](
 	db interface{},
 	req ExecuteRawRequest,
 ) ([]map[string]interface{}, error) {
-	// Validate that all query parameters have corresponding values
-	if err := validateQueryParams(req.Query, req.Params); err != nil {
+	return executeRawWith[P, R](ctx, defaultRegistry, db, req)
+}
+
+// executeRawWith implements ExecuteRaw with the models, queries and rules of r.
+func executeRawWith[P Model, R Model](ctx context.Context, r *Registry, db interface{}, req ExecuteRawRequest) (results []map[string]interface{}, err error) {
+	var result R
+	ctx, finish := r.startRaw(ctx, "sqld.execute_raw", result.TableName())
+	defer func() { finish(len(results), err) }()
+
+	finalQuery, args, err := bindRawParams[P](r, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate SQL syntax
+	if err := r.validateSQLSyntax(finalQuery); err != nil {
+		return nil, err
+	}
+
+	// Get metadata from registry for result type
+	metadata, err := r.getOrRegister(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := validateResultColumns(finalQuery, metadata); err != nil {
+		return nil, err
+	}
+	if err := validateSelectFields(req.SelectFields, metadata); err != nil {
+		return nil, err
+	}
+	finalQuery, err = r.orderRawQuery(finalQuery, req.OrderBy, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, timeout := r.queryContext(ctx, req.Timeout)
+	defer cancel()
+
+	if req.Explain {
+		var plan json.RawMessage
+		err := r.runRaw(ctx, "sqld.explain", metadata.TableName, timeout, finalQuery, args, func(ctx context.Context) (err error) {
+			plan, err = explainQuery(ctx, db, finalQuery, args)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{{"plan": plan}}, nil
+	}
+
+	finalQuery, args, err = formatPlaceholders(finalQuery, args, req.Placeholders)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute query and scan into slice of structs first to handle custom types
+	var structResults []R
+	err = r.runRaw(ctx, "sqld.query", metadata.TableName, timeout, finalQuery, args, func(ctx context.Context) error {
+		if err := selectAll(ctx, db, &structResults, finalQuery, args...); err != nil {
+			return wrapDBError("failed to execute query", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	return rawResultsToMaps(r, structResults, metadata, req.SelectFields)
+}
+
+// contains checks if a string is present in a slice
+func contains(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// bindRawParams validates req.Params against the parameter struct P and replaces
+// the {{param}} placeholders of req.Query with positional parameters.
+// It returns the rewritten query and its arguments in placeholder order.
+// Queries, models and converters are looked up in r.
+// See bindPlaceholders for how slice parameters are bound.
+func bindRawParams[P Model](r *Registry, req ExecuteRawRequest) (string, []interface{}, error) {
+	// Expand template directives, then keep or drop [[ ... ]] blocks
+	// depending on the parameters present
+	query, lists, err := r.resolveQuery(req)
+	if err != nil {
+		return "", nil, err
+	}
+	query, err = expandTemplate(query, req.Params, lists)
+	if err != nil {
+		return "", nil, err
+	}
+	query = expandOptionalBlocks(query, req.Params)
+
 	// Extract named placeholders
-	queryParams, err := ExtractNamedPlaceholders(req.Query)
+	queryParams, err := ExtractNamedPlaceholders(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract named placeholders: %w", err)
+		return "", nil, fmt.Errorf("failed to extract named placeholders: %w", err)
 	}
 
 	// Get metadata from registry for parameter type
 	var param P
-	paramMetadata, err := getModelMetadata(param)
+	paramMetadata, err := r.getOrRegister(param)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get parameter metadata: %w", err)
+		return "", nil, fmt.Errorf("failed to get parameter metadata: %w", err)
 	}
 
-	// Validate and convert map params to arguments in correct order using metadata
-	var args []interface{}
+	// Missing optional parameters are bound as NULL
+	params := req.Params
+	copied := false
+	for _, paramName := range queryParams {
+		if _, ok := params[paramName]; ok || !paramMetadata.Fields[paramName].Optional {
+			continue
+		}
+		// Copy the parameters so the caller's map is never modified
+		if !copied {
+			params = make(map[string]interface{}, len(req.Params)+1)
+			for name, value := range req.Params {
+				params[name] = value
+			}
+			copied = true
+		}
+		params[paramName] = nil
+	}
+
+	// Validate that all query parameters have corresponding values
+	if err := validateQueryParams(query, params); err != nil {
+		return "", nil, err
+	}
+
+	// Validate map params against the parameter types in metadata
+	values := make(map[string]interface{}, len(queryParams))
 	for _, paramName := range queryParams {
-		value, ok := req.Params[paramName]
+		value, ok := params[paramName]
 		if !ok {
-			return nil, fmt.Errorf("missing parameter: %s", paramName)
+			return "", nil, fmt.Errorf("missing parameter: %s", paramName)
 		}
 
 		// Get field info from metadata
 		field, ok := paramMetadata.Fields[paramName]
 		if !ok {
-			return nil, fmt.Errorf("parameter %s not found in struct type %T", paramName, param)
+			return "", nil, fmt.Errorf("parameter %s not found in struct type %T", paramName, param)
+		}
+		if value == nil && field.Optional {
+			values[paramName] = nil
+			continue
 		}
 
-		// Validate type compatibility
-		valueType := reflect.TypeOf(value)
-		if !AreTypesCompatible(valueType, field.Type) {
-			return nil, fmt.Errorf("parameter %s has wrong type: got %v, want %v",
-				paramName, typeNameOrNil(valueType), typeNameOrNil(field.Type))
+		// Validate type compatibility, converting to and from pgtype fields
+		converted, ok := r.convertParamValue(value, field.Type)
+		if !ok {
+			return "", nil, fmt.Errorf("parameter %s has wrong type: got %v, want %v",
+				paramName, typeNameOrNil(reflect.TypeOf(value)), typeNameOrNil(field.Type))
 		}
 
-		args = append(args, value)
+		values[paramName] = converted
 	}
 
-	// Replace named placeholders with $N placeholders
-	finalQuery, err := ReplaceNamedWithDollarPlaceholders(req.Query, queryParams)
-	if err != nil {
-		return nil, fmt.Errorf("failed to replace named placeholders: %w", err)
+	// Replace named placeholders with $N placeholders, expanding IN lists
+	return bindPlaceholders(query, values)
+}
+
+// optionalBlockRegex matches an optional block [[ ... ]], capturing its content.
+var optionalBlockRegex = regexp.MustCompile(`(?s)\[\[(.*?)\]\]`)
+
+// expandOptionalBlocks resolves the optional blocks of query. A block such as
+// [[ AND department = {{department}} ]] is replaced by its content when every
+// parameter it uses is present in params, and removed otherwise, so one query
+// can serve optional filters:
+//
+//	SELECT * FROM employees WHERE TRUE
+//	[[ AND department = {{department}} ]]
+//	[[ AND salary >= {{min_salary}} ]]
+//
+// Brackets without a {{param}} inside, such as ARRAY[[1, 2], [3, 4]], are left
+// untouched. Blocks cannot be nested.
+func expandOptionalBlocks(query string, params map[string]interface{}) string {
+	return optionalBlockRegex.ReplaceAllStringFunc(query, func(block string) string {
+		content := block[2 : len(block)-2]
+		names := namedParamRegex.FindAllStringSubmatch(content, -1)
+		if len(names) == 0 {
+			return block
+		}
+		for _, name := range names {
+			if _, ok := params[name[1]]; !ok {
+				return ""
+			}
+		}
+		return content
+	})
+}
+
+// inListRegex matches IN ({{param_name}}), capturing the placeholder in group 1.
+var inListRegex = regexp.MustCompile(`(?i)\bIN\s*\((\s*\{\{[a-zA-Z0-9_]+\}\}\s*)\)`)
+
+// bindPlaceholders replaces the {{param}} placeholders of query with $N positional
+// parameters, numbered in order of first appearance, and returns the arguments.
+// Every occurrence of a parameter shares the same $N.
+//
+// A slice parameter used as IN ({{param}}) is expanded to one parameter per
+// element, as in IN ($1, $2, $3), so variable-length lists can be bound. Elsewhere
+// a slice is bound as a single array parameter, as in = ANY({{param}}).
+func bindPlaceholders(query string, values map[string]interface{}) (string, []interface{}, error) {
+	inLists := make(map[int]bool)
+	for _, m := range inListRegex.FindAllStringSubmatchIndex(query, -1) {
+		inLists[m[2]+strings.Index(query[m[2]:m[3]], "{{")] = true
 	}
 
-	// Validate SQL syntax
-	if err := validateSQLSyntax(finalQuery); err != nil {
-		return nil, err
+	var b strings.Builder
+	var args []interface{}
+	scalars := make(map[string]string)
+	lists := make(map[string]string)
+	last := 0
+	for _, m := range namedParamRegex.FindAllStringSubmatchIndex(query, -1) {
+		name := query[m[2]:m[3]]
+		value := values[name]
+		b.WriteString(query[last:m[0]])
+		last = m[1]
+
+		if inLists[m[0]] && isListValue(value) {
+			if _, ok := lists[name]; !ok {
+				list := reflect.ValueOf(value)
+				if list.Len() == 0 {
+					return "", nil, fmt.Errorf("parameter %s used in IN list must not be empty", name)
+				}
+				placeholders := make([]string, list.Len())
+				for i := range placeholders {
+					args = append(args, list.Index(i).Interface())
+					placeholders[i] = fmt.Sprintf("$%d", len(args))
+				}
+				lists[name] = strings.Join(placeholders, ", ")
+			}
+			b.WriteString(lists[name])
+			continue
+		}
+
+		if _, ok := scalars[name]; !ok {
+			args = append(args, value)
+			scalars[name] = fmt.Sprintf("$%d", len(args))
+		}
+		b.WriteString(scalars[name])
 	}
+	b.WriteString(query[last:])
+	return b.String(), args, nil
+}
 
-	// Get metadata from registry for result type
-	var result R
-	metadata, err := getModelMetadata(result)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+// isListValue reports whether value is a slice or array that can be expanded
+// into an IN list. Byte slices are bound as single bytea values.
+func isListValue(value interface{}) bool {
+	t := reflect.TypeOf(value)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return false
 	}
+	return t.Elem().Kind() != reflect.Uint8
+}
 
-	// Execute query and scan into slice of structs first to handle custom types
-	var structResults []R
-	switch db := db.(type) {
-	case *sql.DB:
-		if err := sqlscan.Select(ctx, db, &structResults, finalQuery, args...); err != nil {
-			return nil, fmt.Errorf("failed to execute query: %w", err)
-		}
-	case *pgx.Conn:
-		if err := pgxscan.Select(ctx, db, &structResults, finalQuery, args...); err != nil {
-			return nil, fmt.Errorf("failed to execute query: %w", err)
+// validateSelectFields checks that every entry of selectFields is the db or json
+// name of a field of the result model, listing all entries that are not.
+func validateSelectFields(selectFields []string, metadata ModelMetadata) error {
+	var unknown []string
+	for _, name := range selectFields {
+		found := false
+		for _, field := range metadata.Fields {
+			if field.Name == name || field.JSONName == name {
+				found = true
+				break
+			}
 		}
-	case *pgxpool.Pool:
-		if err := pgxscan.Select(ctx, db, &structResults, finalQuery, args...); err != nil {
-			return nil, fmt.Errorf("failed to execute query: %w", err)
+		if !found {
+			unknown = append(unknown, name)
 		}
-	default:
-		return nil, fmt.Errorf("unsupported database type: %T", db)
 	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown select fields for result model %s: %s", metadata.TableName, strings.Join(unknown, ", "))
+	}
+	return nil
+}
 
-	// Convert struct results to maps with only requested fields
+// rawResultsToMaps converts scanned rows to maps keyed by JSON name, keeping only
+// the fields listed in selectFields (by db or JSON name) if it is not empty.
+// Scanners registered with r are applied to custom field types.
+func rawResultsToMaps[R any](r *Registry, structResults []R, metadata ModelMetadata, selectFields []string) ([]map[string]interface{}, error) {
+	plan := rawResultPlan[R](r, metadata, selectFields)
 	results := make([]map[string]interface{}, len(structResults))
 	for i, row := range structResults {
-		val := reflect.ValueOf(row)
-		resultMap := make(map[string]interface{})
-
-		// Only include fields that were specified in SelectFields
-		for _, field := range metadata.Fields {
-			// If SelectFields is empty, include all fields
-			// Otherwise, only include fields that were requested
-			if len(req.SelectFields) == 0 {
-				fieldVal := val.FieldByName(field.GoFieldName)
-				if fieldVal.IsValid() {
-					resultMap[field.JSONName] = fieldVal.Interface()
-				}
-			} else {
-				// Check if the db name or json name is in SelectFields
-				if contains(req.SelectFields, field.Name) || contains(req.SelectFields, field.JSONName) {
-					fieldVal := val.FieldByName(field.GoFieldName)
-					if fieldVal.IsValid() {
-						resultMap[field.JSONName] = fieldVal.Interface()
-					}
-				}
-			}
+		resultMap, err := plan.structRow(reflect.ValueOf(row))
+		if err != nil {
+			return nil, err
 		}
 		results[i] = resultMap
 	}
-
 	return results, nil
 }
 
-// contains checks if a string is present in a slice
-func contains(slice []string, str string) bool {
-	for _, s := range slice {
-		if s == str {
-			return true
+// rawResultPlan plans the conversion of rows of type R, see rawResultsToMaps.
+func rawResultPlan[R any](r *Registry, metadata ModelMetadata, selectFields []string) *resultPlan {
+	keys := make([]string, 0, len(metadata.Fields))
+	for jsonName, field := range metadata.Fields {
+		// If SelectFields is empty, include all fields
+		// Otherwise, check if the db name or json name is in SelectFields
+		if len(selectFields) == 0 || contains(selectFields, field.Name) || contains(selectFields, field.JSONName) {
+			keys = append(keys, jsonName)
 		}
 	}
-	return false
+	return r.newResultPlan(metadata, keys, reflect.TypeOf((*R)(nil)).Elem(), r.getResultLocation())
 }
@@ -2,7 +2,9 @@ package sqld
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -115,3 +117,334 @@ func TestExecuteRawRegistryForParamsAndResult(t *testing.T) {
 }
 
 type MockDB struct{}
+
+func TestValidateSQLSyntaxWithCTEs(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{
+			name:  "read-only CTE",
+			query: "WITH eng AS (SELECT id FROM employees WHERE department = $1) SELECT * FROM eng",
+		},
+		{
+			name: "recursive CTE",
+			query: `WITH RECURSIVE chain AS (
+				SELECT id, manager_id FROM employees WHERE id = $1
+				UNION ALL
+				SELECT e.id, e.manager_id FROM employees e JOIN chain c ON e.id = c.manager_id
+			) SELECT id FROM chain`,
+		},
+		{
+			name:    "data-modifying CTE",
+			query:   "WITH gone AS (DELETE FROM employees WHERE id = $1 RETURNING *) SELECT * FROM gone",
+			wantErr: "only SELECT statements are allowed in WITH clause gone",
+		},
+		{
+			name:    "data-modifying CTE nested in a CTE",
+			query:   "WITH outer_cte AS (WITH inner_cte AS (UPDATE employees SET salary = 0 RETURNING id) SELECT id FROM inner_cte) SELECT id FROM outer_cte",
+			wantErr: "only SELECT statements are allowed in WITH clause inner_cte",
+		},
+		{
+			name:    "data-modifying CTE in a union branch",
+			query:   "SELECT id FROM employees UNION (WITH x AS (INSERT INTO employees (id) VALUES (1) RETURNING id) SELECT id FROM x)",
+			wantErr: "only SELECT statements are allowed in WITH clause x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := defaultRegistry.validateSQLSyntax(tt.query)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBindPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		values   map[string]interface{}
+		want     string
+		wantArgs []interface{}
+		wantErr  string
+	}{
+		{
+			name:     "repeated scalar",
+			query:    "SELECT * FROM t WHERE a = {{x}} OR b = {{x}} AND c = {{y}}",
+			values:   map[string]interface{}{"x": 1, "y": "z"},
+			want:     "SELECT * FROM t WHERE a = $1 OR b = $1 AND c = $2",
+			wantArgs: []interface{}{1, "z"},
+		},
+		{
+			name:     "IN list expanded and later placeholders renumbered",
+			query:    "SELECT * FROM t WHERE id IN ({{ids}}) AND name = {{name}}",
+			values:   map[string]interface{}{"ids": []int{4, 5, 6}, "name": "n"},
+			want:     "SELECT * FROM t WHERE id IN ($1, $2, $3) AND name = $4",
+			wantArgs: []interface{}{4, 5, 6, "n"},
+		},
+		{
+			name:     "NOT IN list with spacing and lowercase",
+			query:    "SELECT * FROM t WHERE id not in ( {{ids}} )",
+			values:   map[string]interface{}{"ids": []string{"a"}},
+			want:     "SELECT * FROM t WHERE id not in ( $1 )",
+			wantArgs: []interface{}{"a"},
+		},
+		{
+			name:     "slice outside IN is bound as an array",
+			query:    "SELECT * FROM t WHERE id = ANY({{ids}}) OR id IN ({{ids}})",
+			values:   map[string]interface{}{"ids": []int{1, 2}},
+			want:     "SELECT * FROM t WHERE id = ANY($1) OR id IN ($2, $3)",
+			wantArgs: []interface{}{[]int{1, 2}, 1, 2},
+		},
+		{
+			name:     "scalar in IN list",
+			query:    "SELECT * FROM t WHERE id IN ({{id}})",
+			values:   map[string]interface{}{"id": 7},
+			want:     "SELECT * FROM t WHERE id IN ($1)",
+			wantArgs: []interface{}{7},
+		},
+		{
+			name:     "byte slice is not expanded",
+			query:    "SELECT * FROM t WHERE digest IN ({{digest}})",
+			values:   map[string]interface{}{"digest": []byte{1, 2}},
+			want:     "SELECT * FROM t WHERE digest IN ($1)",
+			wantArgs: []interface{}{[]byte{1, 2}},
+		},
+		{
+			name:    "empty IN list",
+			query:   "SELECT * FROM t WHERE id IN ({{ids}})",
+			values:  map[string]interface{}{"ids": []int{}},
+			wantErr: "parameter ids used in IN list must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := bindPlaceholders(tt.query, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("query = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+type ListParams struct {
+	IDs  []int  `db:"ids" json:"ids"`
+	Name string `db:"name" json:"name"`
+}
+
+func (ListParams) TableName() string {
+	return "list_params"
+}
+
+func TestExecuteRawWithINList(t *testing.T) {
+	if err := Register[ListParams](); err != nil {
+		t.Fatalf("Failed to register ListParams: %v", err)
+	}
+	if err := Register[TestResult](); err != nil {
+		t.Fatalf("Failed to register TestResult: %v", err)
+	}
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(2), "b"})
+
+	_, err := ExecuteRaw[ListParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id IN ({{ids}}) AND name <> {{name}}",
+		Params: map[string]interface{}{"ids": []int{1, 2}, "name": "a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "SELECT id, name FROM test_results WHERE id IN ($1, $2) AND name <> $3"
+	if len(fake.statements) != 1 || fake.statements[0] != want {
+		t.Errorf("statements = %q, want %q", fake.statements, want)
+	}
+	if wantArgs := []driver.Value{int64(1), int64(2), "a"}; !reflect.DeepEqual(fake.args[0], wantArgs) {
+		t.Errorf("args = %v, want %v", fake.args[0], wantArgs)
+	}
+}
+
+func TestExpandOptionalBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		params map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "present parameter keeps block content",
+			query:  "SELECT * FROM t WHERE TRUE [[ AND a = {{a}} ]]",
+			params: map[string]interface{}{"a": 1},
+			want:   "SELECT * FROM t WHERE TRUE  AND a = {{a}} ",
+		},
+		{
+			name:   "absent parameter drops block",
+			query:  "SELECT * FROM t WHERE TRUE [[ AND a = {{a}} ]] [[ AND b = {{b}} ]]",
+			params: map[string]interface{}{"b": 2},
+			want:   "SELECT * FROM t WHERE TRUE   AND b = {{b}} ",
+		},
+		{
+			name:   "block needs all of its parameters",
+			query:  "SELECT * FROM t WHERE TRUE [[ AND a BETWEEN {{lo}} AND {{hi}} ]]",
+			params: map[string]interface{}{"lo": 1},
+			want:   "SELECT * FROM t WHERE TRUE ",
+		},
+		{
+			name:   "multi-line block",
+			query:  "SELECT * FROM t WHERE TRUE [[\nAND a = {{a}}\n]]",
+			params: map[string]interface{}{"a": 1},
+			want:   "SELECT * FROM t WHERE TRUE \nAND a = {{a}}\n",
+		},
+		{
+			name:  "brackets without parameters are untouched",
+			query: "SELECT ARRAY[[1, 2], [3, 4]]",
+			want:  "SELECT ARRAY[[1, 2], [3, 4]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandOptionalBlocks(tt.query, tt.params); got != tt.want {
+				t.Errorf("expandOptionalBlocks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRawWithOptionalBlocks(t *testing.T) {
+	if err := Register[TestParams](); err != nil {
+		t.Fatalf("Failed to register TestParams: %v", err)
+	}
+	if err := Register[TestResult](); err != nil {
+		t.Fatalf("Failed to register TestResult: %v", err)
+	}
+	db, fake := newFakeDB(t, []string{"id", "name"})
+
+	_, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE TRUE [[AND id = {{id}}]] [[AND name = {{name}}]]",
+		Params: map[string]interface{}{"name": "a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "SELECT id, name FROM test_results WHERE TRUE  AND name = $1"
+	if len(fake.statements) != 1 || fake.statements[0] != want {
+		t.Errorf("statements = %q, want %q", fake.statements, want)
+	}
+}
+
+func TestRepeatedNamedPlaceholders(t *testing.T) {
+	query := "SELECT id FROM t WHERE name = {{name}} OR alias = {{name}} OR id = {{id}}"
+
+	// Repeated names, as a caller might pass from its own scan of the query
+	queryParams := []string{"name", "name", "id"}
+
+	got, err := ReplaceNamedWithDollarPlaceholders(query, queryParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "SELECT id FROM t WHERE name = $1 OR alias = $1 OR id = $2"
+	if got != want {
+		t.Errorf("ReplaceNamedWithDollarPlaceholders() = %q, want %q", got, want)
+	}
+
+	args, err := ValidateMapParamsAgainstStructNamed[TestParams](map[string]interface{}{"name": "a", "id": 1}, queryParams)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"a", 1}) {
+		t.Errorf("args = %v, want [a 1]", args)
+	}
+}
+
+type OptionalTestParams struct {
+	ID   int    `db:"id" json:"id"`
+	Name string `db:"name" json:"name" sqld:"optional"`
+}
+
+func (OptionalTestParams) TableName() string {
+	return "optional_test_params"
+}
+
+func TestOptionalParams(t *testing.T) {
+	if err := Register[OptionalTestParams](); err != nil {
+		t.Fatalf("Failed to register OptionalTestParams: %v", err)
+	}
+	query := "SELECT id FROM t WHERE id = {{id}} AND ({{name}}::text IS NULL OR name = {{name}})"
+
+	// A missing optional parameter is bound as NULL
+	params := map[string]interface{}{"id": 1}
+	finalQuery, args, err := bindRawParams[OptionalTestParams](defaultRegistry, ExecuteRawRequest{Query: query, Params: params})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT id FROM t WHERE id = $1 AND ($2::text IS NULL OR name = $2)"; finalQuery != want {
+		t.Errorf("query = %q, want %q", finalQuery, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, nil}) {
+		t.Errorf("args = %v, want [1 <nil>]", args)
+	}
+	if len(params) != 1 {
+		t.Errorf("caller's params were modified: %v", params)
+	}
+
+	// A missing required parameter is an error
+	_, _, err = bindRawParams[OptionalTestParams](defaultRegistry, ExecuteRawRequest{Query: query, Params: map[string]interface{}{"name": "a"}})
+	if err == nil || err.Error() != "missing required parameters in paramMap: [id]" {
+		t.Errorf("err = %v, want missing id", err)
+	}
+
+	_, err = ValidateMapParamsAgainstStructNamed[OptionalTestParams](map[string]interface{}{"name": "a"}, []string{"id", "name"})
+	if err == nil || err.Error() != "missing required parameter: id" {
+		t.Errorf("err = %v, want missing required parameter: id", err)
+	}
+	args, err = ValidateMapParamsAgainstStructNamed[OptionalTestParams](map[string]interface{}{"id": 1}, []string{"id", "name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, nil}) {
+		t.Errorf("args = %v, want [1 <nil>]", args)
+	}
+}
+
+func TestRawStructTagsAreCached(t *testing.T) {
+	registry := NewRegistry()
+	first, err := registry.rawStructTags(reflect.TypeOf(TestParams{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := registry.rawStructTags(reflect.TypeOf(TestParams{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Error("expected the tags of TestParams to be reflected once and cached")
+	}
+	if got := first.params["name"]; got != reflect.TypeOf("") {
+		t.Errorf("expected parameter name to be a string, got %v", got)
+	}
+
+	if _, err := registry.rawStructTags(reflect.TypeOf(0)); err == nil || err.Error() != "model must be a struct" {
+		t.Errorf("expected error for non-struct type, got %v", err)
+	}
+}
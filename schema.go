@@ -0,0 +1,142 @@
+package sqld
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ResultField describes a field of the results of a raw query, so generic
+// frontends can format its values without knowing the result model.
+type ResultField struct {
+	Name    string `json:"name"`     // Key of the field in the result maps
+	Column  string `json:"column"`   // Column the field is scanned from
+	GoType  string `json:"go_type"`  // Go type of the result model field
+	SQLType string `json:"sql_type"` // PostgreSQL type matching the Go type, empty if unknown
+}
+
+// RawResultSchema describes the fields of R returned by a raw query with the
+// given SelectFields, in the order they were selected, or in struct order
+// when selectFields is empty. ExecuteRawPaginated includes it in its response
+// when ExecuteRawRequest.WithSchema is set.
+func RawResultSchema[R Model](selectFields []string) ([]ResultField, error) {
+	var result R
+	metadata, err := getModelMetadata(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := validateSelectFields(selectFields, metadata); err != nil {
+		return nil, err
+	}
+	return resultSchema(reflect.TypeOf(result), metadata, selectFields), nil
+}
+
+// resultSchema describes the fields of metadata included by selectFields.
+// modelType orders the fields when selectFields is empty.
+func resultSchema(modelType reflect.Type, metadata ModelMetadata, selectFields []string) []ResultField {
+	var fields []Field
+	if len(selectFields) > 0 {
+		for _, name := range selectFields {
+			for _, field := range metadata.Fields {
+				if field.Name == name || field.JSONName == name {
+					fields = append(fields, field)
+					break
+				}
+			}
+		}
+	} else {
+		for _, field := range metadata.Fields {
+			fields = append(fields, field)
+		}
+		sort.Slice(fields, func(i, j int) bool {
+			return fieldIndex(modelType, fields[i]) < fieldIndex(modelType, fields[j])
+		})
+	}
+
+	schema := make([]ResultField, len(fields))
+	for i, field := range fields {
+		schema[i] = ResultField{
+			Name:    field.JSONName,
+			Column:  field.Name,
+			GoType:  field.Type.String(),
+			SQLType: sqlTypeName(field.Type),
+		}
+	}
+	return schema
+}
+
+// fieldIndex returns the position of field in the struct modelType.
+func fieldIndex(modelType reflect.Type, field Field) int {
+	if f, ok := modelType.FieldByName(field.GoFieldName); ok {
+		return f.Index[0]
+	}
+	return modelType.NumField()
+}
+
+// sqlTypes maps Go types to the PostgreSQL types they are scanned from.
+var sqlTypes = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}):          "timestamptz",
+	reflect.TypeOf(json.RawMessage{}):    "jsonb",
+	reflect.TypeOf(map[string]any{}):     "jsonb",
+	reflect.TypeOf([]byte{}):             "bytea",
+	reflect.TypeOf(pgtype.Text{}):        "text",
+	reflect.TypeOf(pgtype.Numeric{}):     "numeric",
+	reflect.TypeOf(pgtype.Int2{}):        "smallint",
+	reflect.TypeOf(pgtype.Int4{}):        "integer",
+	reflect.TypeOf(pgtype.Int8{}):        "bigint",
+	reflect.TypeOf(pgtype.Float4{}):      "real",
+	reflect.TypeOf(pgtype.Float8{}):      "double precision",
+	reflect.TypeOf(pgtype.Bool{}):        "boolean",
+	reflect.TypeOf(pgtype.Date{}):        "date",
+	reflect.TypeOf(pgtype.Timestamp{}):   "timestamp",
+	reflect.TypeOf(pgtype.Timestamptz{}): "timestamptz",
+	reflect.TypeOf(pgtype.Interval{}):    "interval",
+	reflect.TypeOf(pgtype.UUID{}):        "uuid",
+	decimalType:                          "numeric",
+	durationType:                         "interval",
+	geoJSONType:                          "geometry",
+	nullDecimalType:                      "numeric",
+	addrType:                             "inet",
+	prefixType:                           "cidr",
+	wktType:                              "geometry",
+}
+
+// sqlTypeName returns the PostgreSQL type matching the Go type t, or "" if
+// there is no obvious one.
+func sqlTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if name, ok := sqlTypes[t]; ok {
+		return name
+	}
+	if elem, ok := pgArrayElem(t); ok {
+		t = reflect.SliceOf(elem)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int16, reflect.Int8, reflect.Uint8:
+		return "smallint"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "bigint"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "double precision"
+	case reflect.String:
+		return "text"
+	case reflect.Slice, reflect.Array:
+		if elem := sqlTypeName(t.Elem()); elem != "" {
+			return elem + "[]"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SchemaTestResult struct {
+	ID        int64          `db:"id" json:"id"`
+	Name      *string        `db:"name" json:"name"`
+	Salary    pgtype.Numeric `db:"salary" json:"salary"`
+	Tags      []string       `db:"tags" json:"tags"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+func (SchemaTestResult) TableName() string {
+	return "schema_test_results"
+}
+
+func TestRawResultSchema(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[SchemaTestResult]())
+
+	schema, err := RawResultSchema[SchemaTestResult](nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ResultField{
+		{Name: "id", Column: "id", GoType: "int64", SQLType: "bigint"},
+		{Name: "name", Column: "name", GoType: "*string", SQLType: "text"},
+		{Name: "salary", Column: "salary", GoType: "pgtype.Numeric", SQLType: "numeric"},
+		{Name: "tags", Column: "tags", GoType: "[]string", SQLType: "text[]"},
+		{Name: "created_at", Column: "created_at", GoType: "time.Time", SQLType: "timestamptz"},
+	}, schema)
+
+	schema, err = RawResultSchema[SchemaTestResult]([]string{"created_at", "id"})
+	require.NoError(t, err)
+	assert.Equal(t, []ResultField{
+		{Name: "created_at", Column: "created_at", GoType: "time.Time", SQLType: "timestamptz"},
+		{Name: "id", Column: "id", GoType: "int64", SQLType: "bigint"},
+	}, schema)
+
+	_, err = RawResultSchema[SchemaTestResult]([]string{"missing"})
+	assert.EqualError(t, err, "unknown select fields for result model schema_test_results: missing")
+}
+
+func TestExecuteRawPaginatedWithSchema(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+	fake.queue([]string{"count"}, []driver.Value{int64(1)})
+
+	resp, err := ExecuteRawPaginated[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:        "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params:       map[string]interface{}{"id": 1},
+		SelectFields: []string{"name"},
+		WithSchema:   true,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ResultField{{Name: "name", Column: "name", GoType: "string", SQLType: "text"}}, resp.Schema)
+}
@@ -0,0 +1,142 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrScopeExpired is returned when a scoped token is past its expiry time.
+	ErrScopeExpired = errors.New("scope token expired")
+
+	// ErrScopeViolation is returned when a request asks for more than its scope allows.
+	ErrScopeViolation = errors.New("request exceeds token scope")
+)
+
+// QueryScope describes the constraints embedded in a scoped token.
+// A request executed with the token may only touch the given model and fields,
+// and always has the forced filters ANDed into its WHERE clause.
+type QueryScope struct {
+	// Model is the table name of the only model the token may query.
+	Model string `json:"model"`
+
+	// AllowedFields lists the JSON field names that may be selected, filtered
+	// or sorted on, aggregated or faceted. Empty means all fields of the model
+	// are allowed.
+	AllowedFields []string `json:"allowed_fields,omitempty"`
+
+	// ForcedFilters are appended to every request's conditions.
+	ForcedFilters []Condition `json:"forced_filters,omitempty"`
+
+	// ExpiresAt is the time after which the token is rejected.
+	// The zero value means the token does not expire.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// MintScopeToken signs scope with the keyring's active key and returns
+// a URL-safe token that can be handed to clients.
+func (k *Keyring) MintScopeToken(scope QueryScope) (string, error) {
+	if scope.Model == "" {
+		return "", fmt.Errorf("scope model cannot be empty")
+	}
+	payload, err := json.Marshal(scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize query scope: %w", err)
+	}
+	return k.signPayload(purposeScope, payload), nil
+}
+
+// ParseScopeToken verifies token and returns the scope it encodes.
+// Expired tokens are rejected with ErrScopeExpired.
+func (k *Keyring) ParseScopeToken(token string) (QueryScope, error) {
+	payload, err := k.verifyPayload(purposeScope, token)
+	if err != nil {
+		return QueryScope{}, err
+	}
+
+	var scope QueryScope
+	if err := json.Unmarshal(payload, &scope); err != nil {
+		return QueryScope{}, fmt.Errorf("failed to decode query scope: %w", err)
+	}
+	if !scope.ExpiresAt.IsZero() && time.Now().After(scope.ExpiresAt) {
+		return QueryScope{}, ErrScopeExpired
+	}
+	return scope, nil
+}
+
+// Apply checks req against the scope and returns a copy of req with the
+// forced filters added. A request selecting SelectAll is narrowed to the
+// allowed fields.
+func (s QueryScope) Apply(req QueryRequest, metadata ModelMetadata) (QueryRequest, error) {
+	if s.Model != metadata.TableName {
+		return QueryRequest{}, fmt.Errorf("%w: token is not valid for model %s", ErrScopeViolation, metadata.TableName)
+	}
+
+	if len(s.AllowedFields) > 0 {
+		if len(req.Select) == 1 && req.Select[0] == SelectAll {
+			req.Select = append([]string(nil), s.AllowedFields...)
+		}
+		for _, field := range req.Select {
+			if !contains(s.AllowedFields, field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be selected", ErrScopeViolation, field)
+			}
+		}
+		for _, cond := range req.Where {
+			if !contains(s.AllowedFields, cond.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be filtered on", ErrScopeViolation, cond.Field)
+			}
+		}
+		for _, orderBy := range req.OrderBy {
+			if !contains(s.AllowedFields, orderBy.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be sorted on", ErrScopeViolation, orderBy.Field)
+			}
+		}
+		for _, agg := range req.Aggregates {
+			if !contains(s.AllowedFields, agg.Field) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be aggregated", ErrScopeViolation, agg.Field)
+			}
+		}
+		for _, facet := range req.Facets {
+			if !contains(s.AllowedFields, facet) {
+				return QueryRequest{}, fmt.Errorf("%w: field %s cannot be faceted", ErrScopeViolation, facet)
+			}
+		}
+	}
+
+	// Copy the conditions so the caller's slice is never modified
+	where := make([]Condition, 0, len(req.Where)+len(s.ForcedFilters))
+	where = append(where, req.Where...)
+	req.Where = append(where, s.ForcedFilters...)
+
+	return req, nil
+}
+
+// ExecuteScoped verifies a scoped token, applies its constraints to req
+// and then runs the request with Execute.
+func ExecuteScoped[T Model](ctx context.Context, db interface{}, keyring *Keyring, token string, req QueryRequest) (QueryResponse[T], error) {
+	return executeScopedWith[T](ctx, defaultRegistry, db, keyring, token, req)
+}
+
+// executeScopedWith implements ExecuteScoped with the models of r.
+func executeScopedWith[T Model](ctx context.Context, r *Registry, db interface{}, keyring *Keyring, token string, req QueryRequest) (QueryResponse[T], error) {
+	scope, err := keyring.ParseScopeToken(token)
+	if err != nil {
+		return QueryResponse[T]{}, err
+	}
+
+	var model T
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return QueryResponse[T]{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	scopedReq, err := scope.Apply(req, metadata)
+	if err != nil {
+		return QueryResponse[T]{}, err
+	}
+
+	return executeWith[T](ctx, r, db, scopedReq)
+}
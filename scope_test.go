@@ -0,0 +1,180 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeTokenRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	scope := QueryScope{
+		Model:         "test_models",
+		AllowedFields: []string{"id", "name"},
+		ForcedFilters: []Condition{{Field: "active", Operator: OpEqual, Value: true}},
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	token, err := keyring.MintScopeToken(scope)
+	require.NoError(t, err)
+
+	got, err := keyring.ParseScopeToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, scope.Model, got.Model)
+	assert.Equal(t, scope.AllowedFields, got.AllowedFields)
+	assert.Equal(t, true, got.ForcedFilters[0].Value)
+}
+
+func TestScopeTokenExpired(t *testing.T) {
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	token, err := keyring.MintScopeToken(QueryScope{
+		Model:     "test_models",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = keyring.ParseScopeToken(token)
+	assert.ErrorIs(t, err, ErrScopeExpired)
+}
+
+func TestQueryScopeApply(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	metadata, err := getModelMetadata(BuilderTestModel{})
+	require.NoError(t, err)
+
+	scope := QueryScope{
+		Model:         "test_models",
+		AllowedFields: []string{"id", "name", "age"},
+		ForcedFilters: []Condition{{Field: "active", Operator: OpEqual, Value: true}},
+	}
+
+	tests := []struct {
+		name       string
+		request    QueryRequest
+		wantSelect []string
+		wantWhere  int
+		wantErr    bool
+	}{
+		{
+			name:       "allowed fields",
+			request:    QueryRequest{Select: []string{"id", "name"}},
+			wantSelect: []string{"id", "name"},
+			wantWhere:  1,
+		},
+		{
+			name:       "select all is narrowed",
+			request:    QueryRequest{Select: []string{SelectAll}},
+			wantSelect: []string{"id", "name", "age"},
+			wantWhere:  1,
+		},
+		{
+			name: "existing conditions are kept",
+			request: QueryRequest{
+				Select: []string{"id"},
+				Where:  []Condition{{Field: "age", Operator: OpGreaterThan, Value: 18}},
+			},
+			wantSelect: []string{"id"},
+			wantWhere:  2,
+		},
+		{
+			name:    "select outside scope",
+			request: QueryRequest{Select: []string{"email"}},
+			wantErr: true,
+		},
+		{
+			name: "filter outside scope",
+			request: QueryRequest{
+				Select: []string{"id"},
+				Where:  []Condition{{Field: "salary", Operator: OpGreaterThan, Value: 1000}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "order by outside scope",
+			request: QueryRequest{
+				Select:  []string{"id"},
+				OrderBy: []OrderByClause{{Field: "email"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "aggregate outside scope",
+			request: QueryRequest{
+				Select:     []string{"id"},
+				Aggregates: []Aggregate{{Func: AggMax, Field: "salary"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "facet outside scope",
+			request: QueryRequest{
+				Select: []string{"id"},
+				Facets: []string{"email"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "aggregate and facet in scope",
+			request: QueryRequest{
+				Select:     []string{"id"},
+				Aggregates: []Aggregate{{Func: AggSum, Field: "age"}},
+				Facets:     []string{"name"},
+			},
+			wantSelect: []string{"id"},
+			wantWhere:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scope.Apply(tt.request, metadata)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrScopeViolation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSelect, got.Select)
+			assert.Len(t, got.Where, tt.wantWhere)
+			assert.Equal(t, "active", got.Where[len(got.Where)-1].Field)
+		})
+	}
+
+	_, err = QueryScope{Model: "other_table"}.Apply(QueryRequest{Select: []string{"id"}}, metadata)
+	assert.ErrorIs(t, err, ErrScopeViolation)
+}
+
+func TestExecuteScopedRejectsOutOfScopeRequest(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	token, err := keyring.MintScopeToken(QueryScope{Model: "test_models", AllowedFields: []string{"id"}})
+	require.NoError(t, err)
+
+	var mockDB *MockDB
+	_, err = ExecuteScoped[BuilderTestModel](context.Background(), mockDB, keyring, token, QueryRequest{Select: []string{"name"}})
+	assert.ErrorIs(t, err, ErrScopeViolation)
+
+	// Disallowed columns cannot leak through aggregates or facet counts
+	_, err = ExecuteScoped[BuilderTestModel](context.Background(), mockDB, keyring, token, QueryRequest{
+		Select:     []string{"id"},
+		Aggregates: []Aggregate{{Func: AggMax, Field: "salary"}},
+	})
+	assert.ErrorIs(t, err, ErrScopeViolation)
+	_, err = ExecuteScoped[BuilderTestModel](context.Background(), mockDB, keyring, token, QueryRequest{
+		Select: []string{"id"},
+		Facets: []string{"email"},
+	})
+	assert.ErrorIs(t, err, ErrScopeViolation)
+
+	// An in-scope request gets as far as the database
+	_, err = ExecuteScoped[BuilderTestModel](context.Background(), mockDB, keyring, token, QueryRequest{Select: []string{"id"}})
+	assert.EqualError(t, err, "unsupported database type: *sqld.MockDB")
+}
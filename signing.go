@@ -0,0 +1,140 @@
+package sqld
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a signed query fails verification,
+// either because it is malformed or because its contents were modified.
+var ErrInvalidSignature = errors.New("invalid query signature")
+
+// ErrUnknownSigningKey is returned when a signed query references a key ID
+// that is not present in the keyring.
+type ErrUnknownSigningKey struct {
+	KeyID string
+}
+
+func (e *ErrUnknownSigningKey) Error() string {
+	return fmt.Sprintf("unknown signing key %q", e.KeyID)
+}
+
+// SigningKey is an HMAC secret identified by an ID.
+// The ID is embedded in every signed query so that the matching secret
+// can be found during verification.
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring holds the keys used to sign and verify query requests.
+// New queries are always signed with the active key. Previous keys are
+// only used for verification, which allows rotating secrets without
+// invalidating links that were signed with an older key.
+type Keyring struct {
+	active SigningKey
+	keys   map[string][]byte
+}
+
+// NewKeyring creates a keyring that signs with active and accepts signatures
+// made with active or any of the previous keys.
+func NewKeyring(active SigningKey, previous ...SigningKey) (*Keyring, error) {
+	k := &Keyring{
+		active: active,
+		keys:   make(map[string][]byte),
+	}
+	for _, key := range append([]SigningKey{active}, previous...) {
+		if key.ID == "" || strings.Contains(key.ID, ".") {
+			return nil, fmt.Errorf("signing key ID must be non-empty and must not contain '.'")
+		}
+		if len(key.Secret) == 0 {
+			return nil, fmt.Errorf("signing key %q has an empty secret", key.ID)
+		}
+		if _, exists := k.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key ID %q", key.ID)
+		}
+		k.keys[key.ID] = key.Secret
+	}
+	return k, nil
+}
+
+// Sign serializes req and returns a URL-safe token of the form
+// "<key id>.<payload>.<signature>".
+func (k *Keyring) Sign(req QueryRequest) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize query request: %w", err)
+	}
+	return k.signPayload(purposeRequest, payload), nil
+}
+
+// Verify checks the token's signature and returns the embedded QueryRequest.
+// The returned request is exactly what was signed, so it can be passed
+// straight to Execute.
+func (k *Keyring) Verify(token string) (QueryRequest, error) {
+	payload, err := k.verifyPayload(purposeRequest, token)
+	if err != nil {
+		return QueryRequest{}, err
+	}
+
+	var req QueryRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return QueryRequest{}, fmt.Errorf("failed to decode signed query request: %w", err)
+	}
+	return req, nil
+}
+
+// Purposes of signed tokens. The purpose is part of the MAC input, so a token
+// signed for one purpose, such as a scope token, fails verification as another,
+// such as a signed query request.
+const (
+	purposeRequest = "sqld-request"
+	purposeScope   = "sqld-scope"
+)
+
+// signPayload signs an arbitrary payload for purpose with the active key.
+func (k *Keyring) signPayload(purpose string, payload []byte) string {
+	signed := k.active.ID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(computeMAC(k.active.Secret, purpose, signed))
+}
+
+// verifyPayload checks a token produced by signPayload for purpose and returns
+// the raw payload.
+func (k *Keyring) verifyPayload(purpose, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidSignature
+	}
+
+	secret, ok := k.keys[parts[0]]
+	if !ok {
+		return nil, &ErrUnknownSigningKey{KeyID: parts[0]}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if !hmac.Equal(signature, computeMAC(secret, purpose, parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
+
+// computeMAC returns the MAC of data signed for purpose. The NUL byte keeps
+// the purpose apart from the data, which never contains one.
+func computeMAC(secret []byte, purpose, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose + "\x00" + data))
+	return mac.Sum(nil)
+}
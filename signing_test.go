@@ -0,0 +1,112 @@
+package sqld
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerifyQueryRequest(t *testing.T) {
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	req := QueryRequest{
+		Select: []string{"id", "name"},
+		Where: []Condition{
+			{Field: "name", Operator: OpEqual, Value: "alice"},
+		},
+		Limit: intPtr(10),
+	}
+
+	token, err := keyring.Sign(req)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "k1."))
+
+	got, err := keyring.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, req.Select, got.Select)
+	assert.Equal(t, "alice", got.Where[0].Value)
+	assert.Equal(t, 10, *got.Limit)
+}
+
+func TestVerifyRejectsTamperedQuery(t *testing.T) {
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	token, err := keyring.Sign(QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	other, err := keyring.Sign(QueryRequest{Select: []string{SelectAll}})
+	require.NoError(t, err)
+
+	// Swap the payload of one token into the other
+	parts := strings.Split(token, ".")
+	otherParts := strings.Split(other, ".")
+	tampered := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	_, err = keyring.Verify(tampered)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = keyring.Verify("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestTokensAreBoundToTheirPurpose(t *testing.T) {
+	keyring, err := NewKeyring(SigningKey{ID: "k1", Secret: []byte("secret-1")})
+	require.NoError(t, err)
+
+	scopeToken, err := keyring.MintScopeToken(QueryScope{
+		Model:         "test_models",
+		AllowedFields: []string{"id"},
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = keyring.Verify(scopeToken)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	requestToken, err := keyring.Sign(QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+	_, err = keyring.ParseScopeToken(requestToken)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyWithRotatedKeys(t *testing.T) {
+	oldKey := SigningKey{ID: "k1", Secret: []byte("secret-1")}
+	newKey := SigningKey{ID: "k2", Secret: []byte("secret-2")}
+
+	oldKeyring, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	token, err := oldKeyring.Sign(QueryRequest{Select: []string{"id"}})
+	require.NoError(t, err)
+
+	// Tokens signed with a previous key still verify after rotation
+	rotated, err := NewKeyring(newKey, oldKey)
+	require.NoError(t, err)
+	_, err = rotated.Verify(token)
+	assert.NoError(t, err)
+
+	// Once the old key is retired, its tokens are rejected
+	retired, err := NewKeyring(newKey)
+	require.NoError(t, err)
+	_, err = retired.Verify(token)
+	var unknownKey *ErrUnknownSigningKey
+	assert.ErrorAs(t, err, &unknownKey)
+	assert.Equal(t, "k1", unknownKey.KeyID)
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	_, err := NewKeyring(SigningKey{ID: "", Secret: []byte("s")})
+	assert.Error(t, err)
+
+	_, err = NewKeyring(SigningKey{ID: "a.b", Secret: []byte("s")})
+	assert.Error(t, err)
+
+	_, err = NewKeyring(SigningKey{ID: "k1"})
+	assert.Error(t, err)
+
+	_, err = NewKeyring(SigningKey{ID: "k1", Secret: []byte("a")}, SigningKey{ID: "k1", Secret: []byte("b")})
+	assert.Error(t, err)
+}
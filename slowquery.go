@@ -0,0 +1,53 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// logSlowQuery logs query, run on the table of model with args, at LogWarn
+// level if it took elapsed, at least the slow query threshold set with
+// Configure. Only the types of the arguments are logged, since their values
+// may hold personal data.
+func (r *Registry) logSlowQuery(ctx context.Context, model, query string, args []interface{}, elapsed time.Duration) {
+	r.mu.RLock()
+	threshold := r.slowQueryThreshold
+	r.mu.RUnlock()
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	r.log(ctx, LogWarn, "sqld slow query",
+		"model", model,
+		"sql", query,
+		"arg_types", argTypes(args),
+		"duration", elapsed,
+	)
+}
+
+// logSlowBatch logs a pgx batch of statements on the tables of models at
+// LogWarn level if it took elapsed, at least the slow query threshold. The
+// statements of a batch are not timed one by one, so the batch is logged as a
+// whole, without SQL.
+func (r *Registry) logSlowBatch(ctx context.Context, models []string, statements int, elapsed time.Duration) {
+	r.mu.RLock()
+	threshold := r.slowQueryThreshold
+	r.mu.RUnlock()
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	r.log(ctx, LogWarn, "sqld slow batch",
+		"models", models,
+		"statements", statements,
+		"duration", elapsed,
+	)
+}
+
+// argTypes returns the Go types of args.
+func argTypes(args []interface{}) []string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = fmt.Sprintf("%T", arg)
+	}
+	return types
+}
@@ -0,0 +1,57 @@
+package sqld
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryLogging(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewRegistry()
+	registry.Configure(Config{
+		Logger:             NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		SlowQueryThreshold: 20 * time.Millisecond,
+	})
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)})
+	req := QueryRequest{
+		Select: []string{"id"},
+		Where:  []Condition{{Field: "id", Operator: OpEqual, Value: 1}},
+	}
+
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, req)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	fake.delay = 30 * time.Millisecond
+	_, err = executeWith[CompileTestAccount](context.Background(), registry, db, req)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `level=WARN msg="sqld slow query" model=accounts sql="SELECT id FROM accounts WHERE id = $1" arg_types=[int] duration=`)
+	assert.NotContains(t, buf.String(), "args=")
+}
+
+func TestSlowBatchLogging(t *testing.T) {
+	var buf bytes.Buffer
+	registry := NewRegistry()
+	registry.Configure(Config{
+		Logger:             NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		SlowQueryThreshold: 20 * time.Millisecond,
+	})
+
+	registry.logSlowBatch(context.Background(), []string{"accounts", "branches"}, 3, 10*time.Millisecond)
+	assert.Empty(t, buf.String())
+	registry.logSlowBatch(context.Background(), []string{"accounts", "branches"}, 3, 30*time.Millisecond)
+	assert.Contains(t, buf.String(), `level=WARN msg="sqld slow batch" models="[accounts branches]" statements=3 duration=30ms`)
+	assert.NotContains(t, buf.String(), "sql=")
+}
+
+func TestArgTypes(t *testing.T) {
+	assert.Equal(t, []string{"int", "string", "<nil>", "[]int"}, argTypes([]interface{}{1, "a", nil, []int{2}}))
+	assert.Empty(t, argTypes(nil))
+}
@@ -0,0 +1,130 @@
+package sqld
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// SoftDeleteRequest selects the rows to soft-delete or restore.
+// Where is mandatory, so a request cannot affect the whole table by accident.
+type SoftDeleteRequest struct {
+	Where []Condition `json:"where"`
+}
+
+// SoftDeleteResponse reports how many rows changed state.
+type SoftDeleteResponse struct {
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+// ExecuteSoftDelete marks the rows matching req.Where as deleted by setting the
+// model's soft-delete field (tagged sqld:"softdelete") to now(). Rows that are
+// already deleted are left untouched. Fields tagged sqld:"autoupdate" are set to now().
+//
+// Example:
+//
+//	type Employee struct {
+//	    ...
+//	    DeletedAt *time.Time `json:"deleted_at" db:"deleted_at" sqld:"softdelete"`
+//	}
+//
+//	resp, err := sqld.ExecuteSoftDelete[Employee](ctx, db, sqld.SoftDeleteRequest{
+//	    Where: []sqld.Condition{{Field: "id", Operator: sqld.OpEqual, Value: 42}},
+//	})
+func ExecuteSoftDelete[T Model](ctx context.Context, db interface{}, req SoftDeleteRequest) (SoftDeleteResponse, error) {
+	return executeSoftDelete[T](ctx, defaultRegistry, db, req, true)
+}
+
+// ExecuteRestore clears the soft-delete field of the deleted rows matching
+// req.Where, making them visible to queries again.
+func ExecuteRestore[T Model](ctx context.Context, db interface{}, req SoftDeleteRequest) (SoftDeleteResponse, error) {
+	return executeSoftDelete[T](ctx, defaultRegistry, db, req, false)
+}
+
+// executeSoftDelete implements ExecuteSoftDelete, if deleted is true, and
+// ExecuteRestore with the models of r.
+func executeSoftDelete[T Model](ctx context.Context, r *Registry, db interface{}, req SoftDeleteRequest, deleted bool) (resp SoftDeleteResponse, err error) {
+	var model T
+	name := "sqld.execute_soft_delete"
+	if !deleted {
+		name = "sqld.execute_restore"
+	}
+	ctx, finish := r.startWrite(ctx, name, model.TableName())
+	defer func() { finish(int(resp.RowsAffected), err) }()
+
+	metadata, err := r.getOrRegister(model)
+	if err != nil {
+		return SoftDeleteResponse{}, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+	if err := checkWritable(metadata); err != nil {
+		return SoftDeleteResponse{}, err
+	}
+
+	query, err := r.buildSoftDeleteQuery(model.TableName(), metadata, req, deleted)
+	if err != nil {
+		return SoftDeleteResponse{}, err
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return SoftDeleteResponse{}, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	ctx, cancel, timeout := r.queryContext(ctx, 0)
+	defer cancel()
+	var rowsAffected int64
+	err = r.runRaw(ctx, "sqld.exec", model.TableName(), timeout, sql, args, func(ctx context.Context) (err error) {
+		rowsAffected, err = execStatement(ctx, db, sql, args...)
+		if err != nil {
+			return wrapDBError("failed to execute soft delete", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return SoftDeleteResponse{}, err
+	}
+	r.invalidate(model.TableName())
+	return SoftDeleteResponse{RowsAffected: rowsAffected}, nil
+}
+
+// buildSoftDeleteQuery creates the UPDATE statement that sets (deleted is true)
+// or clears the soft-delete field of the rows matching req.Where.
+func (r *Registry) buildSoftDeleteQuery(tableName string, metadata ModelMetadata, req SoftDeleteRequest, deleted bool) (squirrel.UpdateBuilder, error) {
+	if metadata.SoftDeleteField == "" {
+		return squirrel.UpdateBuilder{}, fmt.Errorf("model %s has no field tagged sqld:\"softdelete\"", tableName)
+	}
+	if len(req.Where) == 0 {
+		return squirrel.UpdateBuilder{}, fmt.Errorf("where conditions cannot be empty")
+	}
+	if err := validateWhere(req.Where, metadata); err != nil {
+		return squirrel.UpdateBuilder{}, err
+	}
+	clauses, err := r.whereClauses(metadata, req.Where)
+	if err != nil {
+		return squirrel.UpdateBuilder{}, err
+	}
+
+	column := metadata.Fields[metadata.SoftDeleteField].Name
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(tableName)
+	if deleted {
+		query = query.Set(column, squirrel.Expr("now()")).Where(squirrel.Eq{column: nil})
+	} else {
+		query = query.Set(column, nil).Where(squirrel.NotEq{column: nil})
+	}
+	var autoUpdate []string
+	for _, field := range metadata.Fields {
+		if field.AutoTimestamp == AutoUpdate {
+			autoUpdate = append(autoUpdate, field.Name)
+		}
+	}
+	sort.Strings(autoUpdate)
+	for _, column := range autoUpdate {
+		query = query.Set(column, squirrel.Expr("now()"))
+	}
+	for _, clause := range clauses {
+		query = query.Where(clause)
+	}
+	return query, nil
+}
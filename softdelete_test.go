@@ -0,0 +1,97 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SoftDeleteTestModel struct {
+	ID        int64      `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at" sqld:"autoupdate"`
+	DeletedAt *time.Time `json:"deleted_at" db:"deleted_at" sqld:"softdelete"`
+}
+
+func (SoftDeleteTestModel) TableName() string {
+	return "soft_delete_test_models"
+}
+
+func TestBuildSoftDeleteQuery(t *testing.T) {
+	metadata, err := getModelMetadata(SoftDeleteTestModel{})
+	require.NoError(t, err)
+	assert.Equal(t, "deleted_at", metadata.SoftDeleteField)
+
+	req := SoftDeleteRequest{Where: []Condition{{Field: "id", Operator: OpEqual, Value: 42}}}
+
+	got, err := defaultRegistry.buildSoftDeleteQuery(metadata.TableName, metadata, req, true)
+	require.NoError(t, err)
+	sql, args, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE soft_delete_test_models SET deleted_at = now(), updated_at = now() WHERE deleted_at IS NULL AND id = $1", sql)
+	assert.Equal(t, []interface{}{42}, args)
+
+	got, err = defaultRegistry.buildSoftDeleteQuery(metadata.TableName, metadata, req, false)
+	require.NoError(t, err)
+	sql, args, err = got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE soft_delete_test_models SET deleted_at = $1, updated_at = now() WHERE deleted_at IS NOT NULL AND id = $2", sql)
+	assert.Equal(t, []interface{}{nil, 42}, args)
+}
+
+func TestSoftDeleteQueriesSkipDeletedRows(t *testing.T) {
+	require.NoError(t, Register[SoftDeleteTestModel]())
+
+	got, err := buildQuery[SoftDeleteTestModel](QueryRequest{
+		Select: []string{"name"},
+		Where:  []Condition{{Field: "name", Operator: OpLike, Value: "J%"}},
+	})
+	require.NoError(t, err)
+	sql, _, err := got.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT name FROM soft_delete_test_models WHERE name LIKE $1 AND deleted_at IS NULL", sql)
+}
+
+func TestExecuteSoftDelete(t *testing.T) {
+	require.NoError(t, Register[SoftDeleteTestModel]())
+	require.NoError(t, Register[BuilderTestModel]())
+	db, fake := newFakeDB(t, nil)
+	fake.rowsAffected = 3
+
+	resp, err := ExecuteSoftDelete[SoftDeleteTestModel](context.Background(), db, SoftDeleteRequest{
+		Where: []Condition{{Field: "name", Operator: OpEqual, Value: "Jane"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.RowsAffected)
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr string
+	}{
+		{"missing where", func() error {
+			_, err := ExecuteRestore[SoftDeleteTestModel](context.Background(), db, SoftDeleteRequest{})
+			return err
+		}, "where conditions cannot be empty"},
+		{"invalid where", func() error {
+			_, err := ExecuteRestore[SoftDeleteTestModel](context.Background(), db, SoftDeleteRequest{
+				Where: []Condition{{Field: "title", Operator: OpEqual, Value: "x"}},
+			})
+			return err
+		}, "invalid field in where clause: title"},
+		{"model without soft-delete field", func() error {
+			_, err := ExecuteSoftDelete[BuilderTestModel](context.Background(), db, SoftDeleteRequest{
+				Where: []Condition{{Field: "id", Operator: OpEqual, Value: 1}},
+			})
+			return err
+		}, `model test_models has no field tagged sqld:"softdelete"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.run(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package sqld
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateList is a list iterated by a {% for %} directive of a raw query.
+// Allowed maps every permitted item to the SQL fragment written for it, and
+// Items lists the chosen items, typically from the incoming request. Since the
+// fragments come from Allowed, only SQL written by the application reaches the query.
+// Lists of a query registered with RegisterQuery may leave Allowed nil to use
+// the fragments registered with it, and must once raw queries are locked.
+//
+// Template directives are expanded before placeholder substitution, and the
+// resulting query is validated like any other:
+//
+//	{% if name %} ... {% else %} ... {% end %}
+//	    The first branch is kept if name is a parameter in Params or a non-empty
+//	    list in Lists, the {% else %} branch otherwise. {% else %} is optional.
+//
+//	{% for name %} ... {% end %}
+//	{% for name join ", " %} ... {% end %}
+//	    The body is repeated for every item of Lists[name], joined by the quoted
+//	    separator. In the body, {% item %} writes the allowed SQL fragment of the
+//	    item and {% key %} writes the item itself, which must be an identifier.
+//
+// For example:
+//
+//	SELECT {% for fields join ", " %}{% item %} AS {% key %}{% end %}
+//	FROM employees e
+//	{% if department %}WHERE e.department = {{department}}{% end %}
+type TemplateList struct {
+	Allowed map[string]string
+	Items   []string
+}
+
+// directiveRegex matches a template directive, capturing its content.
+var directiveRegex = regexp.MustCompile(`\{%\s*(.*?)\s*%\}`)
+
+// forRegex matches the content of a {% for %} directive, capturing the list
+// name and the optional quoted separator.
+var forRegex = regexp.MustCompile(`^for\s+([a-zA-Z0-9_]+)(?:\s+join\s+("(?:[^"\\]|\\.)*"))?$`)
+
+// identifierRegex matches the list items that {% key %} may write.
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// templateNode is a parsed part of a query template.
+type templateNode struct {
+	text     string         // Literal text, if the node has no directive
+	kind     string         // "if", "for", "item" or "key"
+	name     string         // Parameter or list name of "if" and "for"
+	sep      string         // Separator of "for"
+	body     []templateNode // Content of "if" and "for"
+	elseBody []templateNode // {% else %} content of "if"
+}
+
+// expandTemplate expands the template directives of query.
+// Queries without directives are returned unchanged.
+func expandTemplate(query string, params map[string]interface{}, lists map[string]TemplateList) (string, error) {
+	if !strings.Contains(query, "{%") {
+		return query, nil
+	}
+
+	p := &templateParser{query: query, directives: directiveRegex.FindAllStringSubmatchIndex(query, -1)}
+	nodes, end, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+	if end != "" {
+		return "", fmt.Errorf("template: unexpected {%% %s %%}", end)
+	}
+
+	var b strings.Builder
+	if err := renderTemplate(&b, nodes, params, lists, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// templateParser parses directives in order of appearance.
+type templateParser struct {
+	query      string
+	directives [][]int // Index pairs from directiveRegex
+	next       int     // Index of the next directive
+	pos        int     // Offset in query after the last directive
+}
+
+// parse parses nodes until the end of the query or an {% else %} or {% end %},
+// which it returns.
+func (p *templateParser) parse() ([]templateNode, string, error) {
+	var nodes []templateNode
+	for p.next < len(p.directives) {
+		d := p.directives[p.next]
+		p.next++
+		if d[0] > p.pos {
+			nodes = append(nodes, templateNode{text: p.query[p.pos:d[0]]})
+		}
+		p.pos = d[1]
+
+		directive := p.query[d[2]:d[3]]
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			return nil, "", fmt.Errorf("template: empty directive")
+		}
+
+		switch fields[0] {
+		case "else", "end":
+			if len(fields) != 1 {
+				return nil, "", fmt.Errorf("template: invalid directive {%% %s %%}", directive)
+			}
+			return nodes, fields[0], nil
+		case "item", "key":
+			if len(fields) != 1 {
+				return nil, "", fmt.Errorf("template: invalid directive {%% %s %%}", directive)
+			}
+			nodes = append(nodes, templateNode{kind: fields[0]})
+		case "if":
+			if len(fields) != 2 {
+				return nil, "", fmt.Errorf("template: invalid directive {%% %s %%}, expected {%% if name %%}", directive)
+			}
+			node := templateNode{kind: "if", name: fields[1]}
+			body, end, err := p.parse()
+			if err != nil {
+				return nil, "", err
+			}
+			node.body = body
+			if end == "else" {
+				if node.elseBody, end, err = p.parse(); err != nil {
+					return nil, "", err
+				}
+			}
+			if end != "end" {
+				return nil, "", fmt.Errorf("template: {%% if %s %%} is not closed by {%% end %%}", node.name)
+			}
+			nodes = append(nodes, node)
+		case "for":
+			node, err := p.parseFor(directive)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		default:
+			return nil, "", fmt.Errorf("template: unknown directive {%% %s %%}", directive)
+		}
+	}
+
+	if p.pos < len(p.query) {
+		nodes = append(nodes, templateNode{text: p.query[p.pos:]})
+		p.pos = len(p.query)
+	}
+	return nodes, "", nil
+}
+
+// parseFor parses the rest of a {% for name [join "sep"] %} block.
+func (p *templateParser) parseFor(directive string) (templateNode, error) {
+	node := templateNode{kind: "for"}
+	m := forRegex.FindStringSubmatch(directive)
+	if m == nil {
+		return node, fmt.Errorf("template: invalid directive {%% %s %%}, expected {%% for name %%} or {%% for name join \"separator\" %%}", directive)
+	}
+	node.name = m[1]
+	if m[2] != "" {
+		sep, err := strconv.Unquote(m[2])
+		if err != nil {
+			return node, fmt.Errorf("template: invalid separator %s: %w", m[2], err)
+		}
+		node.sep = sep
+	}
+
+	body, end, err := p.parse()
+	if err != nil {
+		return node, err
+	}
+	if end != "end" {
+		return node, fmt.Errorf("template: {%% for %s %%} is not closed by {%% end %%}", node.name)
+	}
+	node.body = body
+	return node, nil
+}
+
+// templateItem is the current item of a {% for %} directive.
+type templateItem struct {
+	key      string
+	fragment string
+}
+
+// renderTemplate writes nodes to b. item is the current list item inside a
+// {% for %} body, or nil outside one.
+func renderTemplate(b *strings.Builder, nodes []templateNode, params map[string]interface{}, lists map[string]TemplateList, item *templateItem) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case "":
+			b.WriteString(node.text)
+		case "if":
+			_, present := params[node.name]
+			present = present || len(lists[node.name].Items) > 0
+			body := node.body
+			if !present {
+				body = node.elseBody
+			}
+			if err := renderTemplate(b, body, params, lists, item); err != nil {
+				return err
+			}
+		case "for":
+			list, ok := lists[node.name]
+			if !ok {
+				return fmt.Errorf("template: no list %s for {%% for %s %%}", node.name, node.name)
+			}
+			for i, key := range list.Items {
+				fragment, ok := list.Allowed[key]
+				if !ok {
+					return fmt.Errorf("template: item %q is not allowed in list %s", key, node.name)
+				}
+				if i > 0 {
+					b.WriteString(node.sep)
+				}
+				if err := renderTemplate(b, node.body, params, lists, &templateItem{key: key, fragment: fragment}); err != nil {
+					return err
+				}
+			}
+		case "item", "key":
+			if item == nil {
+				return fmt.Errorf("template: {%% %s %%} used outside {%% for %%}", node.kind)
+			}
+			if node.kind == "item" {
+				b.WriteString(item.fragment)
+			} else if !identifierRegex.MatchString(item.key) {
+				return fmt.Errorf("template: item %q cannot be written by {%% key %%}, it is not an identifier", item.key)
+			} else {
+				b.WriteString(item.key)
+			}
+		}
+	}
+	return nil
+}
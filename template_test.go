@@ -0,0 +1,140 @@
+package sqld
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	fields := TemplateList{
+		Allowed: map[string]string{
+			"name":    "e.first_name",
+			"total":   "COALESCE(SUM(a.balance), 0)",
+			"bad key": "1",
+		},
+	}
+	withItems := func(items ...string) map[string]TemplateList {
+		list := fields
+		list.Items = items
+		return map[string]TemplateList{"fields": list}
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		params  map[string]interface{}
+		lists   map[string]TemplateList
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no directives",
+			query: "SELECT {{a}}",
+			want:  "SELECT {{a}}",
+		},
+		{
+			name:   "if present",
+			query:  "SELECT 1 {% if dept %}WHERE d = {{dept}}{% end %}",
+			params: map[string]interface{}{"dept": "x"},
+			want:   "SELECT 1 WHERE d = {{dept}}",
+		},
+		{
+			name:  "if absent with else",
+			query: "SELECT 1 {% if dept %}WHERE d = {{dept}}{% else %}WHERE TRUE{% end %}",
+			want:  "SELECT 1 WHERE TRUE",
+		},
+		{
+			name:  "for with join",
+			query: `SELECT {% for fields join ", " %}{% item %} AS {% key %}{% end %} FROM e`,
+			lists: withItems("name", "total"),
+			want:  "SELECT e.first_name AS name, COALESCE(SUM(a.balance), 0) AS total FROM e",
+		},
+		{
+			name:  "if on list nested in for",
+			query: `{% if fields %}{% for fields %}[{% if x %}x{% else %}{% key %}{% end %}]{% end %}{% end %}`,
+			lists: withItems("name", "total"),
+			want:  "[name][total]",
+		},
+		{
+			name:    "item not allowed",
+			query:   `SELECT {% for fields join ", " %}{% item %}{% end %}`,
+			lists:   withItems("name", "password"),
+			wantErr: `template: item "password" is not allowed in list fields`,
+		},
+		{
+			name:    "key not an identifier",
+			query:   `SELECT {% for fields %}{% key %}{% end %}`,
+			lists:   withItems("bad key"),
+			wantErr: `template: item "bad key" cannot be written by {% key %}, it is not an identifier`,
+		},
+		{
+			name:    "missing list",
+			query:   `SELECT {% for cols %}{% item %}{% end %}`,
+			wantErr: "template: no list cols for {% for cols %}",
+		},
+		{
+			name:    "unclosed if",
+			query:   "SELECT 1 {% if dept %}WHERE d = {{dept}}",
+			wantErr: "template: {% if dept %} is not closed by {% end %}",
+		},
+		{
+			name:    "unexpected end",
+			query:   "SELECT 1 {% end %}",
+			wantErr: "template: unexpected {% end %}",
+		},
+		{
+			name:    "item outside for",
+			query:   "SELECT {% item %}",
+			wantErr: "template: {% item %} used outside {% for %}",
+		},
+		{
+			name:    "unknown directive",
+			query:   "SELECT {% include x %}",
+			wantErr: "template: unknown directive {% include x %}",
+		},
+		{
+			name:    "bad separator",
+			query:   "SELECT {% for fields join , %}{% end %}",
+			wantErr: "template: invalid directive {% for fields join , %}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandTemplate(tt.query, tt.params, tt.lists)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecuteRawWithTemplate(t *testing.T) {
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+	db, fake := newFakeDB(t, []string{"id", "name"})
+
+	_, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query:  `SELECT {% for cols join ", " %}{% item %}{% end %} FROM test_results {% if name %}WHERE name = {{name}}{% end %}`,
+		Params: map[string]interface{}{"name": "a"},
+		Lists: map[string]TemplateList{
+			"cols": {Allowed: map[string]string{"id": "id", "name": "name"}, Items: []string{"id", "name"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT id, name FROM test_results WHERE name = $1"}, fake.statements)
+
+	_, err = ExecuteRaw[TestParams, TestResult](context.Background(), db, ExecuteRawRequest{
+		Query: `SELECT {% for cols %}{% item %}{% end %} FROM test_results`,
+		Lists: map[string]TemplateList{
+			"cols": {Allowed: map[string]string{"x": "id; DROP TABLE test_results"}, Items: []string{"x"}},
+		},
+	})
+	assert.ErrorContains(t, err, "SQL syntax error")
+}
@@ -0,0 +1,43 @@
+package sqld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQueryTimeout is returned when a raw query is cancelled because it ran
+// longer than the Timeout of its ExecuteRawRequest, or a query, raw or
+// structured, ran longer than the QueryTimeout of the registry's Config. HTTP
+// handlers can check for it with errors.As to answer 504 Gateway Timeout
+// instead of 500.
+type ErrQueryTimeout struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *ErrQueryTimeout) Error() string {
+	return fmt.Sprintf("query timed out after %s: %v", e.Timeout, e.Err)
+}
+
+func (e *ErrQueryTimeout) Unwrap() error {
+	return e.Err
+}
+
+// withTimeout returns ctx bounded by timeout, or ctx itself if timeout is not positive.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError reports err as an *ErrQueryTimeout if the deadline of ctx, set
+// by withTimeout from timeout, has passed.
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil || timeout <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &ErrQueryTimeout{Timeout: timeout, Err: err}
+}
@@ -0,0 +1,82 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRawTimeout(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	require.NoError(t, Register[TestParams]())
+	require.NoError(t, Register[TestResult]())
+
+	req := ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 1},
+	}
+
+	tests := []struct {
+		name        string
+		delay       time.Duration
+		timeout     time.Duration
+		wantTimeout bool
+	}{
+		{name: "no timeout", delay: 10 * time.Millisecond},
+		{name: "within timeout", delay: 10 * time.Millisecond, timeout: time.Second},
+		{name: "timed out", delay: time.Second, timeout: 10 * time.Millisecond, wantTimeout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(1), "Jane"})
+			fake.delay = tt.delay
+			req.Timeout = tt.timeout
+
+			results, err := ExecuteRaw[TestParams, TestResult](context.Background(), db, req)
+			if !tt.wantTimeout {
+				require.NoError(t, err)
+				assert.Len(t, results, 1)
+				return
+			}
+
+			var timeoutErr *ErrQueryTimeout
+			require.True(t, errors.As(err, &timeoutErr), "got %v", err)
+			assert.Equal(t, tt.timeout, timeoutErr.Timeout)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Equal(t, "query timed out after 10ms: failed to execute query: scany: query multiple result rows: context deadline exceeded", err.Error())
+		})
+	}
+
+	t.Run("exec", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+		fake.delay = time.Second
+		_, err := ExecuteRawExec[TestParams](context.Background(), db, ExecuteRawRequest{
+			Query:   "DELETE FROM test_results WHERE id = {{id}}",
+			Params:  map[string]interface{}{"id": 1},
+			Timeout: 10 * time.Millisecond,
+		})
+		var timeoutErr *ErrQueryTimeout
+		assert.True(t, errors.As(err, &timeoutErr), "got %v", err)
+	})
+
+	t.Run("cancelled by caller", func(t *testing.T) {
+		db, fake := newFakeDB(t, nil)
+		fake.delay = time.Second
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req.Timeout = time.Second
+		_, err := ExecuteRaw[TestParams, TestResult](ctx, db, req)
+		var timeoutErr *ErrQueryTimeout
+		assert.False(t, errors.As(err, &timeoutErr))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,96 @@
+package sqld
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SetResultLocation makes the default registry convert timestamps in results
+// to loc. See Registry.SetResultLocation.
+func SetResultLocation(loc *time.Location) {
+	defaultRegistry.SetResultLocation(loc)
+}
+
+// SetResultLocation converts the timestamps in query results, insert RETURNING
+// rows and raw query results to loc before they are returned, so clients in
+// different regions receive the same offsets:
+//
+//	sqld.SetResultLocation(time.UTC)
+//
+// A QueryRequest can ask for another zone with TimeZone. Fields of type
+// pgtype.Date and pgtype.Timestamp hold no zone and are left as scanned, as are
+// all timestamps when loc is nil, the default.
+func (r *Registry) SetResultLocation(loc *time.Location) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resultLocation = loc
+}
+
+// locationFor returns the location results of req are converted to: the
+// request's TimeZone if set, or else the registry's result location.
+func (r *Registry) locationFor(req QueryRequest) (*time.Location, error) {
+	if req.TimeZone != "" {
+		return time.LoadLocation(req.TimeZone)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resultLocation, nil
+}
+
+// getResultLocation returns the location set with SetResultLocation.
+func (r *Registry) getResultLocation() *time.Location {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resultLocation
+}
+
+// localizeResult converts the timestamps in result to loc. Nothing is converted
+// if loc is nil.
+func localizeResult(metadata ModelMetadata, result map[string]interface{}, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for jsonName, val := range result {
+		if hasZone(metadata.Fields[jsonName].Type) {
+			result[jsonName] = localizeValue(val, loc)
+		}
+	}
+}
+
+// localizeValue returns the timestamp val converted to loc. Other values are
+// returned unchanged.
+func localizeValue(val interface{}, loc *time.Location) interface{} {
+	switch ts := val.(type) {
+	case time.Time:
+		return ts.In(loc)
+	case *time.Time:
+		if ts != nil {
+			return ts.In(loc)
+		}
+	case pgtype.Timestamptz:
+		if ts.Valid {
+			ts.Time = ts.Time.In(loc)
+			return ts
+		}
+	}
+	return val
+}
+
+// hasZone reports whether fields of type t hold an instant in time, whose zone
+// can be changed without changing the value. Dates and timestamps without time
+// zone are read as UTC and would shift if converted.
+func hasZone(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(pgtype.Date{}), reflect.TypeOf(pgtype.Timestamp{}):
+		return false
+	}
+	return IsTimeType(normalizeReflectType(t))
+}
@@ -0,0 +1,63 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TimeZoneTestEvent struct {
+	ID       int64       `json:"id" db:"id"`
+	StartsAt time.Time   `json:"starts_at" db:"starts_at"`
+	Day      pgtype.Date `json:"day" db:"day"`
+}
+
+func (TimeZoneTestEvent) TableName() string {
+	return "time_zone_events"
+}
+
+func TestResultLocation(t *testing.T) {
+	startsAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := pgtype.Date{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	db, _ := newFakeDB(t, []string{"id", "starts_at"}, []driver.Value{int64(1), startsAt})
+	client := NewClient(db)
+	req := QueryRequest{Select: []string{"id", "starts_at"}}
+
+	// Timestamps are returned as scanned by default
+	resp, err := ExecuteOn[TimeZoneTestEvent](context.Background(), client, req)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, resp.Data[0]["starts_at"].(time.Time).Location())
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	client.SetResultLocation(ist)
+	resp, err = ExecuteOn[TimeZoneTestEvent](context.Background(), client, req)
+	require.NoError(t, err)
+	got := resp.Data[0]["starts_at"].(time.Time)
+	assert.Equal(t, ist, got.Location())
+	assert.True(t, got.Equal(startsAt))
+
+	// The request's zone takes precedence
+	req.TimeZone = "UTC"
+	resp, err = ExecuteOn[TimeZoneTestEvent](context.Background(), client, req)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, resp.Data[0]["starts_at"].(time.Time).Location())
+
+	req.TimeZone = "Mars/Olympus_Mons"
+	_, err = ExecuteOn[TimeZoneTestEvent](context.Background(), client, req)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, Violation{Code: CodeUnknownTimeZone, Path: "time_zone", Message: "unknown time zone: Mars/Olympus_Mons"}, verr.Rejected[0])
+
+	// Dates have no zone and are not converted
+	metadata, err := buildModelMetadata(TimeZoneTestEvent{}, false)
+	require.NoError(t, err)
+	result := map[string]interface{}{"starts_at": startsAt, "day": day}
+	localizeResult(metadata, result, ist)
+	assert.Equal(t, day, result["day"])
+	assert.Equal(t, ist, result["starts_at"].(time.Time).Location())
+}
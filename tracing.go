@@ -0,0 +1,109 @@
+package sqld
+
+import (
+	"context"
+	"reflect"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the spans of sqld.
+const tracerName = "github.com/remiges-tech/sqld"
+
+// maxSpanSQL bounds the length of the SQL recorded in the db.statement
+// attribute of spans.
+const maxSpanSQL = 1024
+
+// Attributes of the spans of sqld, besides the db.* attributes of the
+// OpenTelemetry semantic conventions.
+const (
+	spanOperationKey  = attribute.Key("sqld.operation")  // OperationExecute, OperationBatch, OperationRaw or OperationWrite
+	spanRowsKey       = attribute.Key("sqld.rows")       // Rows returned
+	spanCacheHitKey   = attribute.Key("sqld.cache_hit")  // The SQL came from the query cache
+	spanStatementsKey = attribute.Key("sqld.statements") // Statements sent in a batch
+)
+
+// UseTracerProvider makes the default registry record spans with tp. See
+// Registry.UseTracerProvider.
+func UseTracerProvider(tp trace.TracerProvider) {
+	defaultRegistry.UseTracerProvider(tp)
+}
+
+// UseTracerProvider makes Execute, ExecuteMany and the raw functions record
+// OpenTelemetry spans with tp, as children of the span of the context they are
+// given, so their queries appear in distributed traces. Execute records the
+// span sqld.execute, under which a query records sqld.validate, sqld.build when
+// its SQL is not cached, and one span per statement: sqld.count,
+// sqld.aggregates, sqld.facet and sqld.query. ExecuteMany records the same
+// under sqld.execute_many, with a single sqld.batch span for the statements
+// sent in a pgx batch. ExecuteRaw records sqld.execute_raw, and the other raw
+// functions sqld.execute_raw_paginated, sqld.execute_raw_batch and the like,
+// with one span per statement, such as sqld.count and sqld.query. Writes record
+// sqld.execute_insert, sqld.execute_import and the like, with a sqld.exec or
+// sqld.copy span per statement. Spans carry the table, the operation, the row
+// count and the SQL, truncated. Nil stops recording spans.
+//
+//	sqld.UseTracerProvider(otel.GetTracerProvider())
+func (r *Registry) UseTracerProvider(tp trace.TracerProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tp == nil {
+		r.tracer = nil
+		return
+	}
+	r.tracer = tp.Tracer(tracerName)
+}
+
+// startSpan starts the span name as a child of the span of ctx, with attrs
+// and the attributes of table, if any, and returns it with a context holding
+// it. Without a tracer provider the span records nothing.
+func (r *Registry) startSpan(ctx context.Context, name, table string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	r.mu.RLock()
+	tracer := r.tracer
+	r.mu.RUnlock()
+	if tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	attrs = append(attrs, attribute.String("db.system", "postgresql"))
+	if table != "" {
+		attrs = append(attrs, attribute.String("db.sql.table", table))
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err if the work it covers failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanSQL returns the db.statement attribute of query, truncated to maxSpanSQL
+// bytes.
+func spanSQL(query string) attribute.KeyValue {
+	if len(query) > maxSpanSQL {
+		n := maxSpanSQL
+		for !utf8.RuneStart(query[n]) {
+			n--
+		}
+		query = query[:n] + "..."
+	}
+	return attribute.String("db.statement", query)
+}
+
+// destRows returns the number of rows scanned into dest: the length of the
+// slice it points to, or 1.
+func destRows(dest interface{}) int {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Slice {
+		return v.Elem().Len()
+	}
+	return 1
+}
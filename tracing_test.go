@@ -0,0 +1,102 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanAttributes returns the attributes of span by key.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingExecute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	registry := NewRegistry()
+	registry.UseTracerProvider(provider)
+
+	db, fake := newFakeDB(t, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+	fake.queue([]string{"count"}, []driver.Value{int64(2)})
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "handler")
+	_, err := executeWith[CompileTestAccount](ctx, registry, db, QueryRequest{
+		Select:     []string{"id"},
+		Pagination: &PaginationRequest{Page: 1, PageSize: 10},
+	})
+	parent.End()
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	require.Equal(t, []string{"sqld.validate", "sqld.build", "sqld.count", "sqld.query", "sqld.execute", "handler"}, names)
+
+	execute := spans[4]
+	assert.Equal(t, parent.SpanContext().SpanID(), execute.Parent().SpanID())
+	for _, span := range spans[:4] {
+		assert.Equal(t, execute.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+
+	attrs := spanAttributes(execute)
+	assert.Equal(t, "accounts", attrs["db.sql.table"].AsString())
+	assert.Equal(t, OperationExecute, attrs[spanOperationKey].AsString())
+	assert.Equal(t, int64(2), attrs[spanRowsKey].AsInt64())
+
+	assert.False(t, spanAttributes(spans[0])[spanCacheHitKey].AsBool())
+	assert.Equal(t, "SELECT COUNT(*) FROM accounts", spanAttributes(spans[2])["db.statement"].AsString())
+	attrs = spanAttributes(spans[3])
+	assert.Equal(t, "SELECT id FROM accounts LIMIT $1 OFFSET $2", attrs["db.statement"].AsString())
+	assert.Equal(t, int64(2), attrs[spanRowsKey].AsInt64())
+}
+
+func TestTracingExecuteError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	registry := NewRegistry()
+	registry.UseTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	db, _ := newFakeDB(t, []string{"id"})
+
+	_, err := executeWith[CompileTestAccount](context.Background(), registry, db, QueryRequest{Select: []string{"balance"}})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "sqld.validate", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "sqld.execute", spans[1].Name())
+	assert.Equal(t, err.Error(), spans[1].Status().Description)
+}
+
+func TestTracingDisabled(t *testing.T) {
+	registry := NewRegistry()
+	ctx, span := registry.startSpan(context.Background(), "sqld.execute", "accounts")
+	assert.Equal(t, context.Background(), ctx)
+	assert.False(t, span.IsRecording())
+}
+
+func TestSpanSQL(t *testing.T) {
+	assert.Equal(t, "SELECT 1", spanSQL("SELECT 1").Value.AsString())
+
+	// The limit falls inside a character, which is left out
+	query := "SELECT 'a" + strings.Repeat("é", maxSpanSQL) + "'"
+	truncated := spanSQL(query).Value.AsString()
+	assert.True(t, utf8.ValidString(truncated))
+	assert.True(t, strings.HasSuffix(truncated, "é..."))
+	assert.Len(t, truncated, maxSpanSQL-1+len("..."))
+}
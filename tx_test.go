@@ -0,0 +1,47 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteInSQLTx(t *testing.T) {
+	require.NoError(t, Register[BuilderTestModel]())
+
+	db, fake := newFakeDB(t, []string{"id", "name"}, []driver.Value{int64(7), "Jane"})
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	rows, err := ExecuteRaw[TestParams, TestResult](context.Background(), tx, ExecuteRawRequest{
+		Query:  "SELECT id, name FROM test_results WHERE id = {{id}}",
+		Params: map[string]interface{}{"id": 7},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Jane", rows[0]["name"])
+
+	fake.rowsAffected = 1
+	resp, err := ExecuteInsert[BuilderTestModel](context.Background(), tx, InsertRequest{
+		Values: map[string]interface{}{"name": "John"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+
+	resp, err = ExecuteBulkInsert[BuilderTestModel](context.Background(), tx, BulkInsertRequest{
+		Rows: []map[string]interface{}{{"name": "A"}, {"name": "B"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.RowsAffected)
+
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, []string{
+		"SELECT id, name FROM test_results WHERE id = $1",
+		"INSERT INTO test_models (name) VALUES ($1)",
+		"INSERT INTO test_models (name) VALUES ($1),($2)",
+	}, fake.statements)
+	assert.Equal(t, 1, fake.commits)
+}
@@ -31,8 +31,23 @@ func AreTypesCompatible(fieldType, valueType reflect.Type) bool {
 		return true
 	}
 
+	// Durations are given as time.Duration values or as strings
+	if fieldType == durationType {
+		return valueType == durationType || valueType.Kind() == reflect.String
+	}
+
+	// Bytes are given as byte slices or as base64 strings
+	if fieldType == bytesType {
+		return isBytesType(valueType) || valueType.Kind() == reflect.String
+	}
+
+	// Addresses and networks are given as netip values or as strings
+	if isNetworkType(fieldType) {
+		return isNetworkType(valueType) || valueType.Kind() == reflect.String
+	}
+
 	// Check numeric
-	if IsNumericType(fieldType) && IsNumericType(valueType) {
+	if IsNumericType(fieldType) && (IsNumericType(valueType) || isDecimalType(valueType)) {
 		return true
 	}
 
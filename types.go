@@ -2,6 +2,8 @@ package sqld
 
 import (
 	"reflect"
+	"strings"
+	"time"
 )
 
 // Model interface that represents a database table.
@@ -17,6 +19,34 @@ type Model interface {
 type ModelMetadata struct {
 	TableName string
 	Fields    map[string]Field
+
+	// SoftDeleteField is the JSON name of the field tagged sqld:"softdelete", if any.
+	// Queries skip rows where it is set; see ExecuteSoftDelete.
+	SoftDeleteField string
+
+	// CacheTTL is how long query results of the model may be cached, as declared
+	// by CacheableModel. Zero means the results must not be cached.
+	CacheTTL time.Duration
+
+	// PrimaryKey holds the JSON names of the fields that identify a row, in key
+	// order, as declared with sqld:"pk" or KeyedModel. It is empty if the model
+	// declares no primary key.
+	PrimaryKey []string
+
+	// RequireValues makes inserts fail when they leave out a required field,
+	// see Registry.RequireInsertValues.
+	RequireValues bool
+
+	// MaterializedView is set for models backed by a materialized view, as
+	// declared by MaterializedViewModel. They cannot be written to.
+	MaterializedView bool
+
+	// Computed holds the computed conditions registered for the model by
+	// name, see RegisterComputedCondition.
+	Computed map[string]ComputedCondition
+
+	// queries caches the SQL of the model's queries by request shape, see compileQuery.
+	queries *queryCache
 }
 
 // Field represents a queryable field with its metadata.
@@ -30,8 +60,31 @@ type Field struct {
 	Type           reflect.Type // Original Go type
 	NormalizedType reflect.Type // Normalized type for validation
 	Array          *ArrayInfo   // Non-nil for array fields
+	AutoTimestamp  AutoTimestamp
+	Optional       bool  // Raw query parameter that is bound as NULL when missing, see sqld:"optional"
+	Nullable       bool  // The Go type can hold NULL, see isNullableType
+	HasDefault     bool  // The column has a database default, see sqld:"default"
+	JSON           bool  // The column holds json or jsonb, see isJSONType
+	Enum           *Enum // Non-nil for fields holding a registered enum, or arrays of one
+	Spatial        bool  // The column holds a PostGIS geometry or geography, see WKT and GeoJSON
 }
 
+// AutoTimestamp marks a timestamp field whose value is managed by sqld.
+// It is set with the sqld struct tag:
+//
+//	CreatedAt time.Time `json:"created_at" db:"created_at" sqld:"autocreate"`
+//	UpdatedAt time.Time `json:"updated_at" db:"updated_at" sqld:"autoupdate"`
+//
+// Inserts set both kinds of fields to the current time unless the request
+// provides a value for them.
+type AutoTimestamp string
+
+const (
+	AutoNone   AutoTimestamp = ""
+	AutoCreate AutoTimestamp = "autocreate"
+	AutoUpdate AutoTimestamp = "autoupdate"
+)
+
 // ArrayInfo contains metadata for array/slice fields.
 type ArrayInfo struct {
 	ElementType reflect.Type
@@ -82,6 +135,12 @@ const (
 	OpContains          Operator = "@>"
 	// OpOverlap checks if an array field shares any elements with the given slice.
 	OpOverlap           Operator = "&&"
+	// OpSubnetOf checks if a network field is strictly contained in the given network.
+	OpSubnetOf          Operator = "<<"
+	// OpSupernetOf checks if a network field strictly contains the given address or network.
+	OpSupernetOf        Operator = ">>"
+	// OpMatches applies a computed condition, with the object of its parameters as value.
+	OpMatches           Operator = "MATCHES"
 
 	// SelectAll is a special value that can be used in QueryRequest.Select to select all fields
 	SelectAll = "ALL"
@@ -94,6 +153,36 @@ type Condition struct {
 	Value    interface{} `json:"value"`     // Value to compare against (optional for IS NULL/IS NOT NULL)
 }
 
+// AggregateFunc represents a SQL aggregate function
+type AggregateFunc string
+
+const (
+	AggSum   AggregateFunc = "SUM"
+	AggAvg   AggregateFunc = "AVG"
+	AggMin   AggregateFunc = "MIN"
+	AggMax   AggregateFunc = "MAX"
+	AggCount AggregateFunc = "COUNT"
+)
+
+// Aggregate requests a summary value computed over the full filtered result set,
+// for example the total salary of all matching employees regardless of the current page.
+type Aggregate struct {
+	Func  AggregateFunc `json:"func"`  // Aggregate function
+	Field string        `json:"field"` // Field name (must match JSON field name)
+}
+
+// Key returns the name under which the aggregate is returned in QueryResponse.Aggregates,
+// e.g. "sum_salary".
+func (a Aggregate) Key() string {
+	return strings.ToLower(string(a.Func)) + "_" + a.Field
+}
+
+// FacetCount is the number of rows in the filtered result set that have a given value.
+type FacetCount struct {
+	Value interface{} `json:"value" db:"value"`
+	Count int64       `json:"count" db:"count"`
+}
+
 // QueryRequest represents the structure for building dynamic SQL queries.
 // It provides type-safe query building with runtime validation against model metadata.
 type QueryRequest struct {
@@ -130,17 +219,55 @@ type QueryRequest struct {
 	// Optional - nil means no offset.
 	// Must be non-negative if provided.
 	Offset *int `json:"offset,omitempty"`
+
+	// Aggregates lists summary values to compute over all rows matching Where.
+	// They are not affected by pagination and are returned in QueryResponse.Aggregates.
+	// Optional - if not provided, no aggregate query is run.
+	Aggregates []Aggregate `json:"aggregates,omitempty"`
+
+	// Facets lists fields for which to return value→count breakdowns of all rows
+	// matching Where, e.g. the number of matching employees per department.
+	// Results are returned in QueryResponse.Facets, at most MaxFacetValues per field.
+	// Optional - if not provided, no facet queries are run.
+	Facets []string `json:"facets,omitempty"`
+
+	// TimeZone is the IANA name of the zone, such as "UTC" or "Asia/Kolkata",
+	// that timestamps in the results are converted to.
+	// Optional - if not provided, the registry's result location is used, see SetResultLocation.
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 // QueryResponse represents the outgoing JSON structure
 type QueryResponse[T Model] struct {
-	Data       []QueryResult       `json:"data"`
-	Pagination *PaginationResponse `json:"pagination,omitempty"`
-	Error      string              `json:"error,omitempty"`
+	Data       []QueryResult           `json:"data"`
+	Pagination *PaginationResponse     `json:"pagination,omitempty"`
+	Aggregates map[string]interface{}  `json:"aggregates,omitempty"`
+	Facets     map[string][]FacetCount `json:"facets,omitempty"`
+	AsOf       *time.Time              `json:"as_of,omitempty"` // Last refresh of a materialized view, see RefreshMaterializedView
+	Error      string                  `json:"error,omitempty"`
 	// TODO: Add these fields for enhanced responses
 	// Metadata QueryMetadata `json:"metadata,omitempty"`
 }
 
+// InsertRequest represents a single-row INSERT built from a dynamic payload.
+type InsertRequest struct {
+	// Values maps JSON field names to the values to insert. Field names must
+	// match the JSON tags in your model struct and values must be compatible
+	// with the field types. Fields not present are left to their column defaults.
+	Values map[string]interface{} `json:"values"`
+
+	// Returning lists fields of the inserted row to return, such as a generated id
+	// or defaulted timestamps. Use SelectAll to return every field.
+	// Optional - if not provided, only the number of affected rows is returned.
+	Returning []string `json:"returning,omitempty"`
+}
+
+// InsertResponse reports the outcome of ExecuteInsert
+type InsertResponse struct {
+	RowsAffected int64         `json:"rows_affected"`
+	Returning    []QueryResult `json:"returning,omitempty"` // Rows produced by the RETURNING clause
+}
+
 // QueryResult represents a single row as map of field name to value
 type QueryResult map[string]interface{}
 
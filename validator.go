@@ -3,6 +3,7 @@ package sqld
 import (
 	"fmt"
 	"reflect"
+	"time"
 )
 
 type Validator interface {
@@ -11,11 +12,73 @@ type Validator interface {
 
 type BasicValidator struct{}
 
+// ViolationCode identifies a validation rule. Codes are stable, so clients can
+// match on them instead of on messages.
+type ViolationCode string
+
+const (
+	CodeRequired        ViolationCode = "required"          // A required value is missing
+	CodeUnknownField    ViolationCode = "unknown_field"     // The field does not exist on the model
+	CodeUnknownOperator ViolationCode = "unknown_operator"  // The operator is not supported
+	CodeUnknownFunction ViolationCode = "unknown_function"  // The aggregate function is not supported
+	CodeArrayField      ViolationCode = "array_field"       // The operation cannot be used on an array field
+	CodeNotArrayField   ViolationCode = "not_array_field"   // The operator requires an array field
+	CodeTypeMismatch    ViolationCode = "type_mismatch"     // The value or field has the wrong type
+	CodeExpectedList    ViolationCode = "expected_list"     // The operator requires a list value
+	CodeUnexpectedValue ViolationCode = "unexpected_value"  // The operator takes no value
+	CodeOutOfRange      ViolationCode = "out_of_range"      // The number is out of the allowed range
+	CodeNotNullable     ViolationCode = "not_nullable"      // The field cannot be NULL
+	CodeJSONField       ViolationCode = "json_field"        // The operator cannot be used on a JSON field
+	CodeUnknownTimeZone ViolationCode = "unknown_time_zone" // The time zone is not a known IANA zone
+	CodeEnumField       ViolationCode = "enum_field"        // The operator cannot be used on an enum field
+	CodeUnknownLabel    ViolationCode = "unknown_label"     // The value is not a label of the field's enum
+	CodeSpatialField    ViolationCode = "spatial_field"     // The operator cannot be used on a spatial field
+	CodeNotNetworkField ViolationCode = "not_network_field" // The operator requires an inet or cidr field
+)
+
+// Violation is a broken validation rule. Path locates the offending part of the
+// request using its JSON names, e.g. "where[2].value" or "order_by[0].field".
+type Violation struct {
+	Code    ViolationCode `json:"code"`
+	Path    string        `json:"path"`
+	Message string        `json:"message"`
+}
+
+func newViolation(code ViolationCode, path string, format string, args ...interface{}) Violation {
+	return Violation{Code: code, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationError is returned when a request violates one or more validation
+// rules. Its message is the message of the first violation; Rejected lists all
+// of them, so handlers can include them in error responses:
+//
+//	var verr *sqld.ValidationError
+//	if errors.As(err, &verr) {
+//	    w.WriteHeader(http.StatusBadRequest)
+//	    json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "rejected": verr.Rejected})
+//	}
+type ValidationError struct {
+	Rejected []Violation `json:"rejected"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Rejected[0].Message
+}
+
+// violationsError returns a *ValidationError for violations, or nil if there are none.
+func violationsError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Rejected: violations}
+}
+
 func isValidOperator(op Operator) bool {
 	switch op {
 	case OpEqual, OpNotEqual, OpGreaterThan, OpLessThan,
 		OpGreaterThanOrEqual, OpLessThanOrEqual, OpLike,
-		OpILike, OpIn, OpNotIn, OpIsNull, OpIsNotNull, OpAny, OpContains, OpOverlap:
+		OpILike, OpIn, OpNotIn, OpIsNull, OpIsNotNull, OpAny, OpContains, OpOverlap,
+		OpSubnetOf, OpSupernetOf:
 		return true
 	}
 	return false
@@ -29,140 +92,368 @@ func isArrayOperator(op Operator) bool {
 	return false
 }
 
+// validateAggregates checks that every aggregate uses a known function on an existing,
+// non-array field, and that SUM and AVG are only applied to numeric fields.
+func validateAggregates(aggregates []Aggregate, metadata ModelMetadata) []Violation {
+	var violations []Violation
+	for i, agg := range aggregates {
+		path := fmt.Sprintf("aggregates[%d]", i)
+		field, ok := metadata.Fields[agg.Field]
+		if !ok {
+			violations = append(violations, newViolation(CodeUnknownField, path+".field",
+				"invalid field in aggregate: %s", agg.Field))
+			continue
+		}
+
+		switch agg.Func {
+		case AggSum, AggAvg:
+			if !IsNumericType(field.NormalizedType) {
+				violations = append(violations, newViolation(CodeTypeMismatch, path+".field",
+					"aggregate %s requires a numeric field, but %s is %v",
+					agg.Func, agg.Field, field.NormalizedType))
+				continue
+			}
+		case AggMin, AggMax, AggCount:
+		default:
+			violations = append(violations, newViolation(CodeUnknownFunction, path+".func",
+				"unsupported aggregate function: %s", agg.Func))
+			continue
+		}
+
+		if field.Array != nil && agg.Func != AggCount {
+			violations = append(violations, newViolation(CodeArrayField, path+".field",
+				"aggregate %s cannot be used on array field %s", agg.Func, agg.Field))
+		}
+	}
+	return violations
+}
+
+// ValidateQuery checks req against the model metadata. If req is invalid, the
+// returned error is a *ValidationError listing every violated rule.
 func (v BasicValidator) ValidateQuery(req QueryRequest, metadata ModelMetadata) error {
 	// Validate select fields
 	if len(req.Select) == 0 {
-		return fmt.Errorf("select fields cannot be empty")
+		return &ValidationError{Rejected: []Violation{
+			newViolation(CodeRequired, "select", "select fields cannot be empty"),
+		}}
 	}
 
-	// Handle special "ALL" value
-	if len(req.Select) == 1 && req.Select[0] == SelectAll {
-		return nil
+	violations := validateAggregates(req.Aggregates, metadata)
+
+	for i, facet := range req.Facets {
+		path := fmt.Sprintf("facets[%d]", i)
+		field, ok := metadata.Fields[facet]
+		if !ok {
+			violations = append(violations, newViolation(CodeUnknownField, path,
+				"invalid field in facets: %s", facet))
+		} else if field.Array != nil {
+			violations = append(violations, newViolation(CodeArrayField, path,
+				"facets cannot be computed on array field %s", facet))
+		}
 	}
 
-	for _, field := range req.Select {
-		if _, ok := metadata.Fields[field]; !ok {
-			return fmt.Errorf("invalid field in select: %s", field)
+	// The special "ALL" value selects every field of the model
+	if len(req.Select) != 1 || req.Select[0] != SelectAll {
+		for i, field := range req.Select {
+			if _, ok := metadata.Fields[field]; !ok {
+				violations = append(violations, newViolation(CodeUnknownField, fmt.Sprintf("select[%d]", i),
+					"invalid field in select: %s", field))
+			}
 		}
 	}
 
-	// Validate where conditions
-	for _, cond := range req.Where {
-		// Validate field exists
-		field, ok := metadata.Fields[cond.Field]
-		if !ok {
-			return fmt.Errorf("invalid field in where clause: %s", cond.Field)
+	violations = append(violations, whereViolations(req.Where, metadata)...)
+
+	// Validate order by fields
+	for i, orderBy := range req.OrderBy {
+		if _, ok := metadata.Fields[orderBy.Field]; !ok {
+			violations = append(violations, newViolation(CodeUnknownField, fmt.Sprintf("order_by[%d].field", i),
+				"invalid field in order by clause: %s", orderBy.Field))
 		}
+	}
+
+	violations = append(violations, optionViolations(req)...)
+
+	return violationsError(violations)
+}
+
+// optionViolations checks the limit, offset and time zone of req.
+func optionViolations(req QueryRequest) []Violation {
+	var violations []Violation
+	// Validate limit and offset
+	if req.Limit != nil && *req.Limit < 0 {
+		violations = append(violations, newViolation(CodeOutOfRange, "limit", "limit must be non-negative"))
+	}
+	if req.Offset != nil && *req.Offset < 0 {
+		violations = append(violations, newViolation(CodeOutOfRange, "offset", "offset must be non-negative"))
+	}
 
-		// Validate operator
-		if !isValidOperator(cond.Operator) {
-			return fmt.Errorf("unsupported operator: %s", cond.Operator)
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			violations = append(violations, newViolation(CodeUnknownTimeZone, "time_zone", "unknown time zone: %s", req.TimeZone))
 		}
+	}
+	return violations
+}
 
-		// Array fields require array operators (null checks work on any field)
-		if field.Array != nil && !isArrayOperator(cond.Operator) &&
-			cond.Operator != OpIsNull && cond.Operator != OpIsNotNull {
-			return fmt.Errorf("operator %s cannot be used on array field %s",
-				cond.Operator, cond.Field)
+// validateWhere checks that every condition uses an existing field, a supported
+// operator and a value compatible with the field's type.
+// If any condition is invalid, the returned error is a *ValidationError.
+func validateWhere(where []Condition, metadata ModelMetadata) error {
+	return violationsError(whereViolations(where, metadata))
+}
+
+// whereViolations returns the violations of the conditions in where,
+// at most one per condition.
+func whereViolations(where []Condition, metadata ModelMetadata) []Violation {
+	var violations []Violation
+	for i, cond := range where {
+		if violation := conditionViolation(fmt.Sprintf("where[%d]", i), cond, metadata); violation != nil {
+			violations = append(violations, *violation)
 		}
+	}
+	return violations
+}
+
+// conditionViolation returns the first rule cond violates, or nil if it is valid.
+// path is the location of cond in the request.
+func conditionViolation(path string, cond Condition, metadata ModelMetadata) *Violation {
+	violation := func(code ViolationCode, path string, format string, args ...interface{}) *Violation {
+		v := newViolation(code, path, format, args...)
+		return &v
+	}
+
+	// Computed conditions take their parameters
+	if computed, ok := metadata.Computed[cond.Field]; ok {
+		return computedConditionViolation(path, cond, computed)
+	}
+
+	// Validate field exists
+	field, ok := metadata.Fields[cond.Field]
+	if !ok {
+		return violation(CodeUnknownField, path+".field", "invalid field in where clause: %s", cond.Field)
+	}
+
+	// Validate operator
+	if !isValidOperator(cond.Operator) {
+		return violation(CodeUnknownOperator, path+".operator", "unsupported operator: %s", cond.Operator)
+	}
 
-		// Array operators require array fields
-		if field.Array == nil && isArrayOperator(cond.Operator) {
-			return fmt.Errorf("operator %s requires an array field, but %s is not an array",
+	// JSON fields are compared as whole documents or tested for containment
+	if field.JSON {
+		if !isJSONOperator(cond.Operator) {
+			return violation(CodeJSONField, path+".operator", "operator %s cannot be used on JSON field %s",
 				cond.Operator, cond.Field)
 		}
-
-		// Special validation for null operators
 		if cond.Operator == OpIsNull || cond.Operator == OpIsNotNull {
 			if cond.Value != nil {
-				return fmt.Errorf("value must be nil for IS NULL/IS NOT NULL operators")
+				return violation(CodeUnexpectedValue, path+".value", "value must be nil for IS NULL/IS NOT NULL operators")
 			}
-			continue
+			return nil
+		}
+		if _, err := encodeJSONValue(cond.Value); err != nil {
+			return violation(CodeTypeMismatch, path+".value", "invalid value for JSON field %s: %v", cond.Field, err)
+		}
+		return nil
+	}
+
+	// Spatial values are opaque, so spatial fields are only tested for NULL
+	if field.Spatial {
+		if v := spatialConditionViolation(path, cond); v != nil {
+			return v
+		}
+	}
+
+	// Enum fields only take their labels
+	if field.Enum != nil {
+		if v := enumConditionViolation(path, cond, field); v != nil {
+			return v
 		}
+	}
+
+	// Array fields require array operators (null checks work on any field)
+	if field.Array != nil && !isArrayOperator(cond.Operator) &&
+		cond.Operator != OpIsNull && cond.Operator != OpIsNotNull {
+		return violation(CodeArrayField, path+".operator", "operator %s cannot be used on array field %s",
+			cond.Operator, cond.Field)
+	}
+
+	// Array operators require array fields
+	if field.Array == nil && isArrayOperator(cond.Operator) {
+		return violation(CodeNotArrayField, path+".operator", "operator %s requires an array field, but %s is not an array",
+			cond.Operator, cond.Field)
+	}
+
+	// Containment operators require network fields
+	if isNetworkOperator(cond.Operator) && !isNetworkType(field.NormalizedType) {
+		return violation(CodeNotNetworkField, path+".operator", "operator %s requires an inet or cidr field, but %s is not one",
+			cond.Operator, cond.Field)
+	}
 
-		// Validate value type matches field type for non-null operators
+	// Special validation for null operators
+	if cond.Operator == OpIsNull || cond.Operator == OpIsNotNull {
 		if cond.Value != nil {
-			valueType := reflect.TypeOf(cond.Value)
-
-			// OpAny: value must match array's element type
-			if cond.Operator == OpAny {
-				if !AreTypesCompatible(field.Array.ElementType, valueType) {
-					return fmt.Errorf("invalid type for field %s: expected %v, got %v",
-						cond.Field, field.Array.ElementType, valueType)
-				}
-				continue
-			}
+			return violation(CodeUnexpectedValue, path+".value", "value must be nil for IS NULL/IS NOT NULL operators")
+		}
+		return nil
+	}
 
-			// OpContains: value must be a slice with elements matching array's element type
-			if cond.Operator == OpContains {
-				if valueType.Kind() != reflect.Slice {
-					return fmt.Errorf("value for OpContains must be a slice")
-				}
-				if !AreTypesCompatible(field.Array.ElementType, valueType.Elem()) {
-					return fmt.Errorf("invalid element type for field %s: expected %v, got %v",
-						cond.Field, field.Array.ElementType, valueType.Elem())
-				}
-				continue
-			}
+	// Validate value type matches field type for non-null operators.
+	// Comparing with NULL only makes sense for fields that can hold it.
+	if cond.Value == nil {
+		if !field.Nullable {
+			return violation(CodeNotNullable, path+".value", "field %s cannot be NULL, use %s or %s to test for NULL",
+				cond.Field, OpIsNull, OpIsNotNull)
+		}
+		return nil
+	}
+	valuePath := path + ".value"
+	valueType := reflect.TypeOf(cond.Value)
 
-			// OpOverlap: value must be a slice with elements matching array's element type
-			if cond.Operator == OpOverlap {
-				if valueType.Kind() != reflect.Slice {
-					return fmt.Errorf("value for OpOverlap must be a slice")
-				}
-				if !AreTypesCompatible(field.Array.ElementType, valueType.Elem()) {
-					return fmt.Errorf("invalid element type for field %s: expected %v, got %v",
-						cond.Field, field.Array.ElementType, valueType.Elem())
-				}
-				continue
-			}
+	switch cond.Operator {
+	case OpAny:
+		// Value must match array's element type
+		if !AreTypesCompatible(field.Array.ElementType, valueType) {
+			return violation(CodeTypeMismatch, valuePath, "invalid type for field %s: expected %v, got %v",
+				cond.Field, field.Array.ElementType, valueType)
+		}
+	case OpContains:
+		// Value must be a slice with elements matching array's element type
+		if valueType.Kind() != reflect.Slice {
+			return violation(CodeExpectedList, valuePath, "value for OpContains must be a slice")
+		}
+		return sliceElementsViolation(valuePath, cond.Field, field.Array.ElementType, cond.Value)
+	case OpOverlap:
+		// Value must be a slice with elements matching array's element type
+		if valueType.Kind() != reflect.Slice {
+			return violation(CodeExpectedList, valuePath, "value for OpOverlap must be a slice")
+		}
+		return sliceElementsViolation(valuePath, cond.Field, field.Array.ElementType, cond.Value)
+	case OpIn, OpNotIn:
+		// IN/NOT IN expect slices
+		if valueType.Kind() != reflect.Slice {
+			return violation(CodeExpectedList, valuePath, "value for IN/NOT IN must be a slice")
+		}
+		return sliceElementsViolation(valuePath, cond.Field, field.NormalizedType, cond.Value)
+	default:
+		if !AreTypesCompatible(field.NormalizedType, valueType) {
+			return violation(CodeTypeMismatch, valuePath, "invalid type for field %s: expected %v, got %v",
+				cond.Field, field.NormalizedType, valueType)
+		}
+	}
+	return nil
+}
 
-			// Special case for IN/NOT IN which expect slices
-			if cond.Operator == OpIn || cond.Operator == OpNotIn {
-				if valueType.Kind() != reflect.Slice {
-					return fmt.Errorf("value for IN/NOT IN must be a slice")
-				}
-
-				// For IN/NOT IN with []interface{}, check each element's actual type
-				if valueType.Elem().Kind() == reflect.Interface {
-					sliceValue := reflect.ValueOf(cond.Value)
-					for i := 0; i < sliceValue.Len(); i++ {
-						elemValue := sliceValue.Index(i).Interface()
-						elemType := reflect.TypeOf(elemValue)
-						if !AreTypesCompatible(field.NormalizedType, elemType) {
-							return fmt.Errorf(
-								"invalid type for field %s at index %d: expected %v, got %v",
-								cond.Field, i, field.NormalizedType, elemType)
-						}
-					}
-				} else {
-					// For typed slices, check the element type
-					if !AreTypesCompatible(field.NormalizedType, valueType.Elem()) {
-						return fmt.Errorf("invalid type for field %s: expected %v, got %v",
-							cond.Field, field.NormalizedType, valueType.Elem())
-					}
-				}
-			} else if !AreTypesCompatible(field.NormalizedType, valueType) {
-				return fmt.Errorf("invalid type for field %s: expected %v, got %v",
-					cond.Field, field.NormalizedType, valueType)
-			}
+// ValidateInsert checks that every value in req refers to a field of the model
+// and has a type compatible with that field. A nil value inserts NULL, which
+// required fields reject. If the model requires values, every required field
+// must be present; see Registry.RequireInsertValues.
+func (v BasicValidator) ValidateInsert(req InsertRequest, metadata ModelMetadata) error {
+	if len(req.Values) == 0 {
+		return fmt.Errorf("insert values cannot be empty")
+	}
+	if missing := missingRequiredFields(metadata, req.Values); len(missing) > 0 {
+		return fmt.Errorf("missing value for required field %s", missing[0])
+	}
+
+	for name, value := range req.Values {
+		field, ok := metadata.Fields[name]
+		if !ok {
+			return fmt.Errorf("invalid field in insert: %s", name)
+		}
+		if err := validateFieldValue(field, value); err != nil {
+			return err
 		}
 	}
+	return validateReturning(req.Returning, metadata)
+}
 
-	// Validate order by fields
-	for _, orderBy := range req.OrderBy {
-		if _, ok := metadata.Fields[orderBy.Field]; !ok {
-			return fmt.Errorf("invalid field in order by clause: %s", orderBy.Field)
+// validateReturning checks that every RETURNING field exists on the model.
+func validateReturning(returning []string, metadata ModelMetadata) error {
+	if len(returning) == 1 && returning[0] == SelectAll {
+		return nil
+	}
+	for _, field := range returning {
+		if _, ok := metadata.Fields[field]; !ok {
+			return fmt.Errorf("invalid field in returning: %s", field)
 		}
 	}
+	return nil
+}
 
-	// Validate limit and offset
-	if req.Limit != nil && *req.Limit < 0 {
-		return fmt.Errorf("limit must be non-negative")
+// validateSliceElements checks that the elements of the slice value are compatible
+// with elemType. Elements of an []interface{}, as decoded from JSON, are checked
+// one by one; typed slices are checked by their element type.
+func validateSliceElements(fieldName string, elemType reflect.Type, value interface{}) error {
+	if violation := sliceElementsViolation("", fieldName, elemType, value); violation != nil {
+		return fmt.Errorf("%s", violation.Message)
 	}
-	if req.Offset != nil && *req.Offset < 0 {
-		return fmt.Errorf("offset must be non-negative")
+	return nil
+}
+
+// sliceElementsViolation is validateSliceElements reporting a violation at path,
+// or at path[i] for a mismatched element of an []interface{}.
+func sliceElementsViolation(path, fieldName string, elemType reflect.Type, value interface{}) *Violation {
+	valueType := reflect.TypeOf(value)
+	if valueType.Elem().Kind() != reflect.Interface {
+		if !AreTypesCompatible(elemType, valueType.Elem()) {
+			v := newViolation(CodeTypeMismatch, path, "invalid type for field %s: expected %v, got %v",
+				fieldName, elemType, valueType.Elem())
+			return &v
+		}
+		return nil
 	}
 
+	sliceValue := reflect.ValueOf(value)
+	for i := 0; i < sliceValue.Len(); i++ {
+		elemValueType := reflect.TypeOf(sliceValue.Index(i).Interface())
+		if !AreTypesCompatible(elemType, elemValueType) {
+			v := newViolation(CodeTypeMismatch, fmt.Sprintf("%s[%d]", path, i),
+				"invalid type for field %s at index %d: expected %v, got %v",
+				fieldName, i, elemType, elemValueType)
+			return &v
+		}
+	}
+	return nil
+}
+
+// validateFieldValue checks that value can be stored in field.
+// Array fields accept slices whose elements are compatible with the element type.
+// nil is NULL, which only fields that can hold it, have a default or are
+// auto-managed timestamps accept.
+func validateFieldValue(field Field, value interface{}) error {
+	if value == nil {
+		if field.Required() {
+			return fmt.Errorf("field %s cannot be NULL", field.JSONName)
+		}
+		return nil
+	}
+
+	if field.JSON {
+		if _, err := encodeJSONValue(value); err != nil {
+			return fmt.Errorf("invalid value for JSON field %s: %w", field.JSONName, err)
+		}
+		return nil
+	}
+
+	if field.Enum != nil {
+		if label, ok := unknownEnumLabel(field.Enum, value); ok {
+			return fmt.Errorf("invalid value for field %s: %q is not a label of enum %s",
+				field.JSONName, label, field.Enum.Type)
+		}
+	}
+
+	valueType := reflect.TypeOf(value)
+	if field.Array != nil {
+		if valueType.Kind() != reflect.Slice {
+			return fmt.Errorf("value for array field %s must be a slice", field.JSONName)
+		}
+		return validateSliceElements(field.JSONName, field.Array.ElementType, value)
+	}
+
+	if !AreTypesCompatible(field.NormalizedType, valueType) {
+		return fmt.Errorf("invalid type for field %s: expected %v, got %v",
+			field.JSONName, field.NormalizedType, valueType)
+	}
 	return nil
 }
@@ -213,6 +213,45 @@ func TestValidateQueryRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid - numeric aggregates",
+			request: QueryRequest{
+				Select: []string{"name"},
+				Aggregates: []Aggregate{
+					{Func: AggSum, Field: "salary"},
+					{Func: AggMax, Field: "name"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid - sum on non-numeric field",
+			request: QueryRequest{
+				Select: []string{"name"},
+				Aggregates: []Aggregate{
+					{Func: AggSum, Field: "name"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid - unknown aggregate function",
+			request: QueryRequest{
+				Select: []string{"name"},
+				Aggregates: []Aggregate{
+					{Func: AggregateFunc("MEDIAN"), Field: "salary"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid - unknown facet field",
+			request: QueryRequest{
+				Select: []string{"name"},
+				Facets: []string{"invalid_field"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid - unknown operator",
 			request: QueryRequest{
@@ -245,3 +284,73 @@ func TestValidateQueryRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateQueryRejected(t *testing.T) {
+	if err := Register[ValidatorTestModel](); err != nil {
+		t.Fatalf("Failed to register test model: %v", err)
+	}
+	metadata, err := getModelMetadata(ValidatorTestModel{})
+	assert.NoError(t, err)
+
+	limit := -1
+	req := QueryRequest{
+		Select: []string{"name", "nickname"},
+		Where: []Condition{
+			{Field: "name", Operator: OpEqual, Value: "Jane"},
+			{Field: "age", Operator: Operator("~"), Value: 3},
+			{Field: "age", Operator: OpIn, Value: []interface{}{30, "forty"}},
+			{Field: "salary", Operator: OpGreaterThan, Value: "high"},
+		},
+		OrderBy:    []OrderByClause{{Field: "rank"}},
+		Limit:      &limit,
+		Aggregates: []Aggregate{{Func: AggSum, Field: "name"}},
+	}
+
+	err = BasicValidator{}.ValidateQuery(req, metadata)
+	var verr *ValidationError
+	if !assert.ErrorAs(t, err, &verr) {
+		return
+	}
+	assert.Equal(t, []Violation{
+		{Code: CodeTypeMismatch, Path: "aggregates[0].field", Message: "aggregate SUM requires a numeric field, but name is string"},
+		{Code: CodeUnknownField, Path: "select[1]", Message: "invalid field in select: nickname"},
+		{Code: CodeUnknownOperator, Path: "where[1].operator", Message: "unsupported operator: ~"},
+		{Code: CodeTypeMismatch, Path: "where[2].value[1]", Message: "invalid type for field age at index 1: expected int, got string"},
+		{Code: CodeTypeMismatch, Path: "where[3].value", Message: "invalid type for field salary: expected float64, got string"},
+		{Code: CodeUnknownField, Path: "order_by[0].field", Message: "invalid field in order by clause: rank"},
+		{Code: CodeOutOfRange, Path: "limit", Message: "limit must be non-negative"},
+	}, verr.Rejected)
+	assert.EqualError(t, err, "aggregate SUM requires a numeric field, but name is string")
+}
+
+func TestValidateQuerySelectAll(t *testing.T) {
+	if err := Register[ValidatorTestModel](); err != nil {
+		t.Fatalf("Failed to register test model: %v", err)
+	}
+	metadata, err := getModelMetadata(ValidatorTestModel{})
+	assert.NoError(t, err)
+
+	// Selecting all fields skips only the select list check
+	limit := -1
+	req := QueryRequest{
+		Select:   []string{SelectAll},
+		Where:    []Condition{{Field: "rank", Operator: OpEqual, Value: 1}},
+		OrderBy:  []OrderByClause{{Field: "age", Desc: true}, {Field: "level"}},
+		Limit:    &limit,
+		TimeZone: "Mars/Olympus",
+	}
+
+	err = BasicValidator{}.ValidateQuery(req, metadata)
+	var verr *ValidationError
+	if !assert.ErrorAs(t, err, &verr) {
+		return
+	}
+	assert.Equal(t, []Violation{
+		{Code: CodeUnknownField, Path: "where[0].field", Message: "invalid field in where clause: rank"},
+		{Code: CodeUnknownField, Path: "order_by[1].field", Message: "invalid field in order by clause: level"},
+		{Code: CodeOutOfRange, Path: "limit", Message: "limit must be non-negative"},
+		{Code: CodeUnknownTimeZone, Path: "time_zone", Message: "unknown time zone: Mars/Olympus"},
+	}, verr.Rejected)
+
+	assert.NoError(t, BasicValidator{}.ValidateQuery(QueryRequest{Select: []string{SelectAll}}, metadata))
+}
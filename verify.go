@@ -0,0 +1,214 @@
+package sqld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ModelMismatch is a difference between a registered model and its table in the database.
+type ModelMismatch struct {
+	Table   string `json:"table"`
+	Field   string `json:"field,omitempty"`  // JSON name of the field, empty if the table itself is wrong
+	Column  string `json:"column,omitempty"` // Column of the field
+	Message string `json:"message"`
+}
+
+// SchemaMismatchError is returned by VerifyModels when registered models do not
+// match the database. Mismatches lists every difference found.
+type SchemaMismatchError struct {
+	Mismatches []ModelMismatch `json:"mismatches"`
+}
+
+func (e *SchemaMismatchError) Error() string {
+	if len(e.Mismatches) == 1 {
+		return "registered models do not match the database: " + e.Mismatches[0].Message
+	}
+	return fmt.Sprintf("registered models do not match the database: %s (and %d more)",
+		e.Mismatches[0].Message, len(e.Mismatches)-1)
+}
+
+// VerifyModels checks every registered model against the columns of its table,
+// as reported by information_schema, and returns a *SchemaMismatchError listing
+// tables that do not exist, fields whose column is missing and fields whose Go
+// type cannot hold the column's type. It is meant to run at startup, after the
+// models are registered, so schema drift fails fast instead of on the first query:
+//
+//	sqld.MustRegisterAll(Employee{}, Department{})
+//	if err := sqld.VerifyModels(ctx, db); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Unqualified table names are looked up in the current schema. Fields of types
+// with no obvious PostgreSQL counterpart, such as types with registered scanners,
+// are only checked for presence.
+func VerifyModels(ctx context.Context, db interface{}) error {
+	return defaultRegistry.VerifyModels(ctx, db)
+}
+
+// VerifyModels checks the models registered with r, see VerifyModels.
+func (r *Registry) VerifyModels(ctx context.Context, db interface{}) error {
+	var mismatches []ModelMismatch
+	for _, metadata := range r.registeredModels() {
+		columns, err := tableColumns(ctx, db, metadata.TableName)
+		if err != nil {
+			return err
+		}
+		mismatches = append(mismatches, modelMismatches(metadata, columns)...)
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return &SchemaMismatchError{Mismatches: mismatches}
+}
+
+// tableColumn is a column of a table, as described by information_schema.columns.
+type tableColumn struct {
+	Name     string `db:"column_name"`
+	DataType string `db:"data_type"`
+	UDTName  string `db:"udt_name"`
+}
+
+// tableColumnsQuery lists the columns of the table $2 in the schema $1, or in
+// the current schema if $1 is empty.
+const tableColumnsQuery = `SELECT column_name, data_type, udt_name
+FROM information_schema.columns
+WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2`
+
+// tableColumns returns the columns of table by name. The result is empty if
+// the table does not exist.
+func tableColumns(ctx context.Context, db interface{}, table string) (map[string]tableColumn, error) {
+	schema, name := "", table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, name = table[:i], table[i+1:]
+	}
+
+	var rows []tableColumn
+	if err := selectAll(ctx, db, &rows, tableColumnsQuery, schema, name); err != nil {
+		return nil, wrapDBError(fmt.Sprintf("failed to get columns of table %s", table), err)
+	}
+	columns := make(map[string]tableColumn, len(rows))
+	for _, column := range rows {
+		columns[column.Name] = column
+	}
+	return columns, nil
+}
+
+// modelMismatches compares metadata with the columns of its table.
+func modelMismatches(metadata ModelMetadata, columns map[string]tableColumn) []ModelMismatch {
+	if len(columns) == 0 {
+		return []ModelMismatch{{
+			Table:   metadata.TableName,
+			Message: fmt.Sprintf("table %s does not exist", metadata.TableName),
+		}}
+	}
+
+	var mismatches []ModelMismatch
+	for _, field := range metadata.Fields {
+		mismatch := ModelMismatch{Table: metadata.TableName, Field: field.JSONName, Column: field.Name}
+		column, ok := columns[field.Name]
+		switch {
+		case !ok:
+			mismatch.Message = fmt.Sprintf("column %s.%s of field %s does not exist",
+				metadata.TableName, field.Name, field.JSONName)
+		case !columnTypeMatches(field.Type, column):
+			mismatch.Message = fmt.Sprintf("column %s.%s has type %s, which does not fit field %s of type %s",
+				metadata.TableName, field.Name, column.UDTName, field.JSONName, field.Type)
+		case field.Enum != nil && !enumColumnMatches(field.Enum, column):
+			mismatch.Message = fmt.Sprintf("column %s.%s has type %s, but field %s holds enum %s",
+				metadata.TableName, field.Name, column.UDTName, field.JSONName, field.Enum.Type)
+		default:
+			continue
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Column < mismatches[j].Column })
+	return mismatches
+}
+
+// Groups of column types, by udt_name, that scan into the same Go types.
+var (
+	integerColumns = []string{"int2", "int4", "int8", "oid"}
+	floatColumns   = []string{"float4", "float8", "numeric"}
+	textColumns    = []string{"text", "varchar", "bpchar", "name", "citext", "uuid"}
+	timeColumns    = []string{"timestamp", "timestamptz", "date"}
+)
+
+// columnTypes maps Go types to the column types they can hold.
+var columnTypes = map[reflect.Type][]string{
+	reflect.TypeOf(time.Time{}):          timeColumns,
+	reflect.TypeOf(json.RawMessage{}):    {"json", "jsonb"},
+	reflect.TypeOf(map[string]any{}):     {"json", "jsonb"},
+	reflect.TypeOf([]byte{}):             {"bytea", "json", "jsonb"},
+	reflect.TypeOf(pgtype.Text{}):        textColumns,
+	reflect.TypeOf(pgtype.Numeric{}):     {"numeric", "int2", "int4", "int8"},
+	reflect.TypeOf(pgtype.Int2{}):        integerColumns,
+	reflect.TypeOf(pgtype.Int4{}):        integerColumns,
+	reflect.TypeOf(pgtype.Int8{}):        integerColumns,
+	reflect.TypeOf(pgtype.Float4{}):      floatColumns,
+	reflect.TypeOf(pgtype.Float8{}):      floatColumns,
+	reflect.TypeOf(pgtype.Bool{}):        {"bool"},
+	reflect.TypeOf(pgtype.Date{}):        {"date"},
+	reflect.TypeOf(pgtype.Timestamp{}):   {"timestamp"},
+	reflect.TypeOf(pgtype.Timestamptz{}): {"timestamptz"},
+	reflect.TypeOf(pgtype.Interval{}):    {"interval"},
+	durationType:                         {"interval"},
+	reflect.TypeOf(pgtype.UUID{}):        {"uuid"},
+	decimalType:                          {"numeric", "int2", "int4", "int8"},
+	nullDecimalType:                      {"numeric", "int2", "int4", "int8"},
+	addrType:                             {"inet"},
+	prefixType:                           {"inet", "cidr"},
+	wktType:                              {"geometry", "geography"},
+	geoJSONType:                          {"geometry", "geography"},
+}
+
+// columnTypeMatches reports whether a field of type t can hold column.
+// Types without known column types match any column.
+func columnTypeMatches(t reflect.Type, column tableColumn) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if elem, ok := pgArrayElem(t); ok {
+		t = reflect.SliceOf(elem)
+	}
+	if elem, ok := strings.CutPrefix(column.UDTName, "_"); ok && column.DataType == "ARRAY" {
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return false
+		}
+		return columnTypeMatches(t.Elem(), tableColumn{UDTName: elem})
+	}
+
+	if types, ok := columnTypes[t]; ok {
+		return contains(types, column.UDTName)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return column.UDTName == "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return contains(integerColumns, column.UDTName)
+	case reflect.Float32, reflect.Float64:
+		return contains(floatColumns, column.UDTName) || contains(integerColumns, column.UDTName)
+	case reflect.String:
+		// Enums and other custom types scan as text
+		return contains(textColumns, column.UDTName) || !isBuiltinColumnType(column.UDTName)
+	default:
+		return true
+	}
+}
+
+// isBuiltinColumnType reports whether udt is one of the column types in columnTypes.
+func isBuiltinColumnType(udt string) bool {
+	for _, types := range columnTypes {
+		if contains(types, udt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package sqld
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type VerifyTestModel struct {
+	ID        int       `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Salary    float64   `db:"salary" json:"salary"`
+	Tags      []string  `db:"tags" json:"tags"`
+	Status    string    `db:"status" json:"status"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (VerifyTestModel) TableName() string {
+	return "hr.employees"
+}
+
+func TestVerifyModels(t *testing.T) {
+	columns := []string{"column_name", "data_type", "udt_name"}
+	tests := []struct {
+		name   string
+		rows   [][]driver.Value
+		errors []string
+	}{
+		{
+			name: "matching",
+			rows: [][]driver.Value{
+				{"id", "integer", "int4"},
+				{"name", "character varying", "varchar"},
+				{"salary", "numeric", "numeric"},
+				{"tags", "ARRAY", "_text"},
+				{"status", "USER-DEFINED", "employee_status"},
+				{"created_at", "timestamp with time zone", "timestamptz"},
+				{"extra", "text", "text"},
+			},
+		},
+		{
+			name: "drift",
+			rows: [][]driver.Value{
+				{"id", "uuid", "uuid"},
+				{"name", "text", "text"},
+				{"salary", "numeric", "numeric"},
+				{"tags", "text", "text"},
+				{"status", "integer", "int4"},
+			},
+			errors: []string{
+				"column hr.employees.created_at of field created_at does not exist",
+				"column hr.employees.id has type uuid, which does not fit field id of type int",
+				"column hr.employees.status has type int4, which does not fit field status of type string",
+			},
+		},
+		{
+			name:   "missing table",
+			errors: []string{"table hr.employees does not exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil)
+			require.NoError(t, client.Register(VerifyTestModel{}))
+			db, fake := newFakeDB(t, columns, tt.rows...)
+
+			err := client.VerifyModels(context.Background(), db)
+			assert.Equal(t, [][]driver.Value{{"hr", "employees"}}, fake.args)
+			if tt.errors == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var mismatchErr *SchemaMismatchError
+			require.True(t, errors.As(err, &mismatchErr))
+			messages := make([]string, len(mismatchErr.Mismatches))
+			for i, mismatch := range mismatchErr.Mismatches {
+				messages[i] = mismatch.Message
+			}
+			assert.Equal(t, tt.errors, messages)
+		})
+	}
+}